@@ -0,0 +1,338 @@
+// Package mockwallet is a scriptable NIP-47 wallet service for tests and
+// demos. It runs on an in-process relay, answers requests with canned or
+// scripted results, and records every request it receives.
+package mockwallet
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip04"
+	"github.com/nbd-wtf/go-nostr/nip44"
+	"noscli/internal/testrelay"
+	"noscli/pkg/nwc"
+	"noscli/pkg/zap"
+)
+
+// Methods the wallet advertises and answers by default
+var Methods = []string{"pay_invoice", "get_balance", "get_info", "make_invoice"}
+
+// Result is a scripted answer to one request
+type Result struct {
+	Result  any    // Result object, used when Code is empty
+	Code    string // NIP-47 error code, e.g. "INSUFFICIENT_BALANCE"
+	Message string // Error message
+	NoReply bool   // Don't answer at all, to test timeouts
+}
+
+// Request is a request the wallet received
+type Request struct {
+	Method string
+	Params map[string]any
+	Event  nostr.Event
+}
+
+// Wallet is a mock wallet service connected to a test relay
+type Wallet struct {
+	Relay        *testrelay.Relay
+	SecretKey    string
+	Pubkey       string
+	ClientSecret string // Connection secret handed to clients in the URI
+	ClientPubkey string
+
+	mu          sync.Mutex
+	balanceMsat int64
+	feesMsat    int64
+	delay       time.Duration
+	untagged    bool
+	scripts     map[string][]Result
+	requests    []Request
+}
+
+// New starts a wallet service on relay with a balance and publishes its
+// info event
+func New(relay *testrelay.Relay, balanceMsat int64) *Wallet {
+	w := &Wallet{
+		Relay:        relay,
+		SecretKey:    nostr.GeneratePrivateKey(),
+		ClientSecret: nostr.GeneratePrivateKey(),
+		balanceMsat:  balanceMsat,
+		scripts:      make(map[string][]Result),
+	}
+	w.Pubkey, _ = nostr.GetPublicKey(w.SecretKey)
+	w.ClientPubkey, _ = nostr.GetPublicKey(w.ClientSecret)
+
+	w.PublishInfo(Methods, []string{nwc.EncryptionNIP44, nwc.EncryptionNIP04})
+	relay.OnEvent(w.handle)
+	return w
+}
+
+// URI returns the wallet's nostr+walletconnect connection string
+func (w *Wallet) URI() string {
+	return fmt.Sprintf("nostr+walletconnect://%s?relay=%s&secret=%s", w.Pubkey, w.Relay.URL, w.ClientSecret)
+}
+
+// PublishInfo replaces the wallet's info event. An empty encryption list
+// omits the encryption tag, like wallets that only speak NIP-04.
+func (w *Wallet) PublishInfo(methods, encryption []string) {
+	content := ""
+	for i, m := range methods {
+		if i > 0 {
+			content += " "
+		}
+		content += m
+	}
+	evt := nostr.Event{
+		CreatedAt: nostr.Now(),
+		Kind:      nwc.KindInfo,
+		Content:   content,
+	}
+	if len(encryption) > 0 {
+		scheme := encryption[0]
+		for _, e := range encryption[1:] {
+			scheme += " " + e
+		}
+		evt.Tags = nostr.Tags{nostr.Tag{"encryption", scheme}}
+	}
+	evt.Sign(w.SecretKey)
+	w.Relay.Publish(evt)
+}
+
+// Script queues results for a method, used in order before the defaults
+func (w *Wallet) Script(method string, results ...Result) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.scripts[method] = append(w.scripts[method], results...)
+}
+
+// SetFees sets the routing fee reported for every payment
+func (w *Wallet) SetFees(feesMsat int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.feesMsat = feesMsat
+}
+
+// SetDelay makes the wallet wait before answering
+func (w *Wallet) SetDelay(delay time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.delay = delay
+}
+
+// SetUntagged makes responses leave out the 'e' tag, like some broken wallets
+func (w *Wallet) SetUntagged(untagged bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.untagged = untagged
+}
+
+// Balance returns the remaining balance in msats
+func (w *Wallet) Balance() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.balanceMsat
+}
+
+// Requests returns the requests received so far
+func (w *Wallet) Requests() []Request {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]Request{}, w.requests...)
+}
+
+// handle answers requests addressed to the wallet. Requests are answered
+// whoever signed them, so clients using their own key (Pleb Signer) work too.
+func (w *Wallet) handle(evt nostr.Event) {
+	if evt.Kind != nwc.KindRequest || !hasTag(evt.Tags, "p", w.Pubkey) {
+		return
+	}
+
+	nip44Request := hasTag(evt.Tags, "encryption", nwc.EncryptionNIP44)
+	plaintext, err := w.decrypt(evt, nip44Request)
+	if err != nil {
+		return
+	}
+
+	var request struct {
+		Method string         `json:"method"`
+		Params map[string]any `json:"params"`
+	}
+	if err := json.Unmarshal([]byte(plaintext), &request); err != nil {
+		return
+	}
+
+	w.mu.Lock()
+	w.requests = append(w.requests, Request{Method: request.Method, Params: request.Params, Event: evt})
+	result := w.answer(request.Method, request.Params)
+	delay, untagged := w.delay, w.untagged
+	w.mu.Unlock()
+
+	if result.NoReply {
+		return
+	}
+
+	respond := func() { w.respond(evt, request.Method, result, nip44Request, untagged) }
+	if delay > 0 {
+		time.AfterFunc(delay, respond)
+	} else {
+		go respond()
+	}
+}
+
+// answer picks the scripted or default result for a request. Callers must hold w.mu.
+func (w *Wallet) answer(method string, params map[string]any) Result {
+	if queue := w.scripts[method]; len(queue) > 0 {
+		w.scripts[method] = queue[1:]
+		return queue[0]
+	}
+
+	switch method {
+	case "get_info":
+		return Result{Result: map[string]any{"alias": "mock wallet", "network": "regtest", "methods": Methods}}
+	case "get_balance":
+		return Result{Result: map[string]any{"balance": w.balanceMsat}}
+	case "make_invoice":
+		amount, _ := params["amount"].(float64)
+		description, _ := params["description"].(string)
+		invoice, paymentHash := Invoice(int64(amount), description)
+		return Result{Result: map[string]any{
+			"type":         "incoming",
+			"invoice":      invoice,
+			"amount":       int64(amount),
+			"payment_hash": paymentHash,
+			"created_at":   time.Now().Unix(),
+		}}
+	case "pay_invoice":
+		bolt11, _ := params["invoice"].(string)
+		invoice, err := zap.DecodeInvoice(bolt11)
+		if err != nil {
+			return Result{Code: "OTHER", Message: "invalid invoice"}
+		}
+		if invoice.AmountMsats+w.feesMsat > w.balanceMsat {
+			return Result{Code: "INSUFFICIENT_BALANCE", Message: "not enough funds"}
+		}
+		w.balanceMsat -= invoice.AmountMsats + w.feesMsat
+		preimage := make([]byte, 32)
+		rand.Read(preimage)
+		return Result{Result: map[string]any{"preimage": hex.EncodeToString(preimage), "fees_paid": w.feesMsat}}
+	}
+	return Result{Code: "NOT_IMPLEMENTED", Message: method + " is not supported"}
+}
+
+// respond publishes the wallet's answer to a request
+func (w *Wallet) respond(request nostr.Event, method string, result Result, useNIP44 bool, untagged bool) {
+	response := map[string]any{"result_type": method}
+	if result.Code != "" {
+		response["error"] = map[string]string{"code": result.Code, "message": result.Message}
+	} else {
+		response["result"] = result.Result
+	}
+	content, _ := json.Marshal(response)
+
+	ciphertext, err := w.encrypt(request.PubKey, string(content), useNIP44)
+	if err != nil {
+		return
+	}
+
+	evt := nostr.Event{
+		CreatedAt: nostr.Now(),
+		Kind:      nwc.KindResponse,
+		Tags:      nostr.Tags{nostr.Tag{"p", request.PubKey}},
+		Content:   ciphertext,
+	}
+	if !untagged {
+		evt.Tags = append(evt.Tags, nostr.Tag{"e", request.ID})
+	}
+	evt.Sign(w.SecretKey)
+	w.Relay.Publish(evt)
+}
+
+// decrypt decrypts a request from its author
+func (w *Wallet) decrypt(evt nostr.Event, useNIP44 bool) (string, error) {
+	if useNIP44 {
+		key, err := nip44.GenerateConversationKey(evt.PubKey, w.SecretKey)
+		if err != nil {
+			return "", err
+		}
+		return nip44.Decrypt(evt.Content, key)
+	}
+	shared, err := nip04.ComputeSharedSecret(evt.PubKey, w.SecretKey)
+	if err != nil {
+		return "", err
+	}
+	return nip04.Decrypt(evt.Content, shared)
+}
+
+// encrypt encrypts a response for pubkey
+func (w *Wallet) encrypt(pubkey, plaintext string, useNIP44 bool) (string, error) {
+	if useNIP44 {
+		key, err := nip44.GenerateConversationKey(pubkey, w.SecretKey)
+		if err != nil {
+			return "", err
+		}
+		return nip44.Encrypt(plaintext, key)
+	}
+	shared, err := nip04.ComputeSharedSecret(pubkey, w.SecretKey)
+	if err != nil {
+		return "", err
+	}
+	return nip04.Encrypt(plaintext, shared)
+}
+
+// Invoice builds an unsigned BOLT-11 invoice for amountMsats (a multiple of
+// 100) committing to description, and returns it with its payment hash.
+// Nothing in this repository checks invoice signatures, so that's enough.
+func Invoice(amountMsats int64, description string) (string, string) {
+	descriptionHash := sha256.Sum256([]byte(description))
+	return buildInvoice(amountMsats, 23, descriptionHash[:]) // 'h'
+}
+
+// DescribedInvoice is like Invoice but carries description itself rather
+// than its hash, as invoices for people to read do
+func DescribedInvoice(amountMsats int64, description string) (string, string) {
+	return buildInvoice(amountMsats, 13, []byte(description)) // 'd'
+}
+
+// buildInvoice encodes an invoice with a fresh payment hash and one
+// description field, tagged tag
+func buildInvoice(amountMsats int64, tag byte, description []byte) (string, string) {
+	var data []byte
+	timestamp := time.Now().Unix()
+	for i := 6; i >= 0; i-- {
+		data = append(data, byte(timestamp>>(5*i))&31)
+	}
+
+	appendField := func(tag byte, value []byte) {
+		groups, _ := bech32.ConvertBits(value, 8, 5, true)
+		data = append(data, tag, byte(len(groups)>>5), byte(len(groups)&31))
+		data = append(data, groups...)
+	}
+	preimage := make([]byte, 32)
+	rand.Read(preimage)
+	paymentHash := sha256.Sum256(preimage)
+	appendField(1, paymentHash[:]) // 'p'
+	appendField(tag, description)
+
+	// Zeroed signature and recovery id
+	data = append(data, make([]byte, 104)...)
+
+	invoice, _ := bech32.Encode(fmt.Sprintf("lnbc%dn", amountMsats/100), data)
+	return invoice, hex.EncodeToString(paymentHash[:])
+}
+
+// hasTag reports whether tags contain key with value
+func hasTag(tags nostr.Tags, key, value string) bool {
+	for _, tag := range tags {
+		if len(tag) >= 2 && tag[0] == key && tag[1] == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,305 @@
+// Package testrelay is an in-memory nostr relay for tests and demos. It
+// speaks enough of NIP-01 for the clients in this repository: EVENT with OK
+// replies, REQ with stored events and EOSE, and CLOSE. Replaceable and
+// addressable events replace older versions, ephemeral events aren't stored.
+package testrelay
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/coder/websocket"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Relay is an in-memory relay listening on a local ws:// URL
+type Relay struct {
+	URL string // ws:// URL clients connect to
+
+	server  *httptest.Server
+	mu      sync.Mutex
+	events  []nostr.Event
+	conns   map[*conn]struct{}
+	onEvent []func(nostr.Event)
+	reject  func(nostr.Event) string
+	reqs    []nostr.Filters
+	stalled bool
+}
+
+// conn is one client connection and its open subscriptions
+type conn struct {
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+	subs    map[string]nostr.Filters // Guarded by Relay.mu
+}
+
+// New starts a relay; call Close when done
+func New() *Relay {
+	r := &Relay{conns: make(map[*conn]struct{})}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serve))
+	r.URL = "ws" + strings.TrimPrefix(r.server.URL, "http")
+	return r
+}
+
+// Close disconnects all clients and stops the relay
+func (r *Relay) Close() {
+	r.mu.Lock()
+	for c := range r.conns {
+		c.ws.Close(websocket.StatusGoingAway, "relay closed")
+	}
+	r.mu.Unlock()
+	r.server.Close()
+}
+
+// Publish stores an event and sends it to matching subscriptions, as if a
+// client had published it. The signature isn't checked.
+func (r *Relay) Publish(evt nostr.Event) {
+	r.store(evt)
+	r.broadcast(evt)
+}
+
+// OnEvent registers fn to be called with every event clients publish,
+// after it was accepted and sent to subscribers
+func (r *Relay) OnEvent(fn func(nostr.Event)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onEvent = append(r.onEvent, fn)
+}
+
+// Reject installs a policy for events clients publish: a non-empty reason
+// refuses the event with an OK false message carrying that reason
+func (r *Relay) Reject(fn func(nostr.Event) string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reject = fn
+}
+
+// Stall makes the relay ignore REQs, answering with neither events nor
+// EOSE, like an overloaded relay. Publishing still works.
+func (r *Relay) Stall() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stalled = true
+}
+
+// Events returns the stored events matching filter, newest first
+func (r *Relay) Events(filter nostr.Filter) []nostr.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.query(filter)
+}
+
+// Subscriptions returns the number of open subscriptions across all clients.
+// Ephemeral events only reach subscriptions that exist when they're
+// published, so tests can wait for a client's subscription first.
+func (r *Relay) Subscriptions() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for c := range r.conns {
+		n += len(c.subs)
+	}
+	return n
+}
+
+// Requests returns the filters of every REQ clients have sent, in order
+func (r *Relay) Requests() []nostr.Filters {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]nostr.Filters(nil), r.reqs...)
+}
+
+// serve handles one websocket client
+func (r *Relay) serve(w http.ResponseWriter, req *http.Request) {
+	ws, err := websocket.Accept(w, req, nil)
+	if err != nil {
+		return
+	}
+	ws.SetReadLimit(1 << 20)
+
+	c := &conn{ws: ws, subs: make(map[string]nostr.Filters)}
+	r.mu.Lock()
+	r.conns[c] = struct{}{}
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.conns, c)
+		r.mu.Unlock()
+		ws.CloseNow()
+	}()
+
+	ctx := req.Context()
+	for {
+		_, message, err := ws.Read(ctx)
+		if err != nil {
+			return
+		}
+
+		switch env := nostr.ParseMessage(string(message)).(type) {
+		case *nostr.EventEnvelope:
+			r.handleEvent(ctx, c, env.Event)
+		case *nostr.ReqEnvelope:
+			r.handleReq(ctx, c, env.SubscriptionID, env.Filters)
+		case *nostr.CloseEnvelope:
+			r.mu.Lock()
+			delete(c.subs, string(*env))
+			r.mu.Unlock()
+		default:
+			notice := nostr.NoticeEnvelope("unsupported message")
+			c.send(ctx, &notice)
+		}
+	}
+}
+
+// handleEvent validates and accepts an event published by a client
+func (r *Relay) handleEvent(ctx context.Context, c *conn, evt nostr.Event) {
+	if ok, err := evt.CheckSignature(); !ok || err != nil {
+		c.send(ctx, &nostr.OKEnvelope{EventID: evt.ID, OK: false, Reason: "invalid: bad signature"})
+		return
+	}
+	r.mu.Lock()
+	reject := r.reject
+	r.mu.Unlock()
+	if reject != nil {
+		if reason := reject(evt); reason != "" {
+			c.send(ctx, &nostr.OKEnvelope{EventID: evt.ID, OK: false, Reason: reason})
+			return
+		}
+	}
+
+	r.store(evt)
+	c.send(ctx, &nostr.OKEnvelope{EventID: evt.ID, OK: true})
+	r.broadcast(evt)
+
+	r.mu.Lock()
+	hooks := append([]func(nostr.Event){}, r.onEvent...)
+	r.mu.Unlock()
+	for _, fn := range hooks {
+		fn(evt)
+	}
+}
+
+// handleReq sends stored events matching a subscription, then EOSE, and
+// keeps the subscription open for new events
+func (r *Relay) handleReq(ctx context.Context, c *conn, id string, filters nostr.Filters) {
+	r.mu.Lock()
+	c.subs[id] = filters
+	r.reqs = append(r.reqs, filters)
+	if r.stalled {
+		r.mu.Unlock()
+		return
+	}
+	seen := make(map[string]bool)
+	var matches []nostr.Event
+	for _, filter := range filters {
+		for _, evt := range r.query(filter) {
+			if !seen[evt.ID] {
+				seen[evt.ID] = true
+				matches = append(matches, evt)
+			}
+		}
+	}
+	r.mu.Unlock()
+
+	for _, evt := range matches {
+		c.send(ctx, &nostr.EventEnvelope{SubscriptionID: &id, Event: evt})
+	}
+	eose := nostr.EOSEEnvelope(id)
+	c.send(ctx, &eose)
+}
+
+// store keeps an event unless it's ephemeral. A replaceable or addressable
+// event replaces older versions and is dropped if a newer one is stored.
+func (r *Relay) store(evt nostr.Event) {
+	if evt.Kind >= 20000 && evt.Kind < 30000 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := replaceKey(evt)
+	var kept []nostr.Event
+	for _, existing := range r.events {
+		if existing.ID == evt.ID {
+			return
+		}
+		if key != "" && replaceKey(existing) == key {
+			if existing.CreatedAt > evt.CreatedAt {
+				return
+			}
+			continue
+		}
+		kept = append(kept, existing)
+	}
+	r.events = append(kept, evt)
+}
+
+// replaceKey identifies the slot a replaceable (kind 0, 3, 10000-19999) or
+// addressable (30000-39999) event occupies, or returns "" for regular events
+func replaceKey(evt nostr.Event) string {
+	switch {
+	case evt.Kind == 0 || evt.Kind == 3 || (evt.Kind >= 10000 && evt.Kind < 20000):
+		return fmt.Sprintf("%d:%s", evt.Kind, evt.PubKey)
+	case evt.Kind >= 30000 && evt.Kind < 40000:
+		return fmt.Sprintf("%d:%s:%s", evt.Kind, evt.PubKey, evt.Tags.GetD())
+	}
+	return ""
+}
+
+// broadcast sends an event to every open subscription it matches
+func (r *Relay) broadcast(evt nostr.Event) {
+	type delivery struct {
+		c  *conn
+		id string
+	}
+	var deliveries []delivery
+
+	r.mu.Lock()
+	for c := range r.conns {
+		for id, filters := range c.subs {
+			if filters.Match(&evt) {
+				deliveries = append(deliveries, delivery{c, id})
+			}
+		}
+	}
+	r.mu.Unlock()
+
+	for _, d := range deliveries {
+		id := d.id
+		d.c.send(context.Background(), &nostr.EventEnvelope{SubscriptionID: &id, Event: evt})
+	}
+}
+
+// query returns stored events matching filter, newest first, up to its
+// limit. Callers must hold r.mu.
+func (r *Relay) query(filter nostr.Filter) []nostr.Event {
+	var matches []nostr.Event
+	for _, evt := range r.events {
+		if filter.Matches(&evt) {
+			matches = append(matches, evt)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].CreatedAt > matches[j].CreatedAt
+	})
+	if filter.Limit > 0 && len(matches) > filter.Limit {
+		matches = matches[:filter.Limit]
+	}
+	return matches
+}
+
+// send writes an envelope to the client, ignoring clients that went away
+func (c *conn) send(ctx context.Context, env nostr.Envelope) {
+	data, err := env.MarshalJSON()
+	if err != nil {
+		return
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.ws.Write(ctx, websocket.MessageText, data)
+}
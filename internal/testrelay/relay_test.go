@@ -0,0 +1,160 @@
+package testrelay
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// signed returns an event signed by sk
+func signed(t *testing.T, sk string, kind int, content string, createdAt nostr.Timestamp, tags ...nostr.Tag) nostr.Event {
+	t.Helper()
+	evt := nostr.Event{Kind: kind, Content: content, CreatedAt: createdAt, Tags: tags}
+	if err := evt.Sign(sk); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	return evt
+}
+
+// connect starts a relay and connects a client to it
+func connect(t *testing.T) (*Relay, *nostr.Relay) {
+	t.Helper()
+	r := New()
+	t.Cleanup(r.Close)
+	client, err := nostr.RelayConnect(context.Background(), r.URL)
+	if err != nil {
+		t.Fatalf("RelayConnect: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return r, client
+}
+
+func TestPublishAndQuery(t *testing.T) {
+	r, client := connect(t)
+	ctx := context.Background()
+	sk := nostr.GeneratePrivateKey()
+
+	for i, content := range []string{"first", "second", "third"} {
+		evt := signed(t, sk, 1, content, nostr.Timestamp(1000+i))
+		if err := client.Publish(ctx, evt); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+
+	events, err := client.QuerySync(ctx, nostr.Filter{Kinds: []int{1}, Limit: 2})
+	if err != nil {
+		t.Fatalf("QuerySync: %v", err)
+	}
+	if len(events) != 2 || events[0].Content != "third" || events[1].Content != "second" {
+		t.Errorf("got %d events, want the newest two", len(events))
+	}
+
+	if got := r.Events(nostr.Filter{Kinds: []int{7}}); len(got) != 0 {
+		t.Errorf("kind 7 query returned %d events", len(got))
+	}
+}
+
+func TestRejections(t *testing.T) {
+	r, client := connect(t)
+	ctx := context.Background()
+	sk := nostr.GeneratePrivateKey()
+
+	forged := signed(t, sk, 1, "hello", nostr.Now())
+	forged.Content = "tampered"
+	if err := client.Publish(ctx, forged); err == nil || !strings.Contains(err.Error(), "bad signature") {
+		t.Errorf("forged event: err = %v, want bad signature", err)
+	}
+
+	r.Reject(func(evt nostr.Event) string {
+		if evt.Kind == 4 {
+			return "blocked: no DMs here"
+		}
+		return ""
+	})
+	if err := client.Publish(ctx, signed(t, sk, 4, "psst", nostr.Now())); err == nil || !strings.Contains(err.Error(), "no DMs here") {
+		t.Errorf("policy: err = %v, want the rejection reason", err)
+	}
+	if err := client.Publish(ctx, signed(t, sk, 1, "fine", nostr.Now())); err != nil {
+		t.Errorf("allowed event: %v", err)
+	}
+
+	if got := r.Events(nostr.Filter{}); len(got) != 1 || got[0].Content != "fine" {
+		t.Errorf("stored %d events, want only the accepted one", len(got))
+	}
+}
+
+func TestReplaceableEvents(t *testing.T) {
+	r := New()
+	defer r.Close()
+	sk := nostr.GeneratePrivateKey()
+
+	r.Publish(signed(t, sk, 0, "old profile", 100))
+	r.Publish(signed(t, sk, 0, "new profile", 200))
+	r.Publish(signed(t, sk, 0, "stale profile", 150))
+	r.Publish(signed(t, sk, 30023, "article a", 100, nostr.Tag{"d", "a"}))
+	r.Publish(signed(t, sk, 30023, "article b", 100, nostr.Tag{"d", "b"}))
+	r.Publish(signed(t, sk, 30023, "article a v2", 200, nostr.Tag{"d", "a"}))
+	r.Publish(signed(t, sk, 23195, "ephemeral", 100))
+
+	profiles := r.Events(nostr.Filter{Kinds: []int{0}})
+	if len(profiles) != 1 || profiles[0].Content != "new profile" {
+		t.Errorf("profiles = %v, want only the newest", profiles)
+	}
+	articles := r.Events(nostr.Filter{Kinds: []int{30023}})
+	if len(articles) != 2 || articles[0].Content != "article a v2" || articles[1].Content != "article b" {
+		t.Errorf("articles = %v, want one per d tag", articles)
+	}
+	if got := r.Events(nostr.Filter{Kinds: []int{23195}}); len(got) != 0 {
+		t.Errorf("ephemeral event was stored")
+	}
+}
+
+func TestSubscription(t *testing.T) {
+	r, client := connect(t)
+	ctx := context.Background()
+	sk := nostr.GeneratePrivateKey()
+	pk, _ := nostr.GetPublicKey(sk)
+
+	r.Publish(signed(t, sk, 1, "stored", 100))
+
+	sub, err := client.Subscribe(ctx, nostr.Filters{{Kinds: []int{1, 23195}, Authors: []string{pk}}})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	next := func() *nostr.Event {
+		select {
+		case evt := <-sub.Events:
+			return evt
+		case <-time.After(2 * time.Second):
+			t.Fatal("no event")
+			return nil
+		}
+	}
+
+	if evt := next(); evt.Content != "stored" {
+		t.Errorf("first event = %q, want the stored one", evt.Content)
+	}
+	select {
+	case <-sub.EndOfStoredEvents:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no EOSE")
+	}
+
+	r.Publish(signed(t, sk, 23195, "live", 200))
+	if evt := next(); evt.Content != "live" {
+		t.Errorf("live event = %q", evt.Content)
+	}
+
+	sub.Unsub()
+	deadline := time.Now().Add(2 * time.Second)
+	for r.Subscriptions() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("CLOSE didn't remove the subscription")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,86 @@
+// Package testsigner stands in for Pleb Signer's D-Bus object in tests,
+// signing and encrypting with a local key
+package testsigner
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip04"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"noscli/pkg/signer"
+)
+
+// Object answers Pleb Signer's D-Bus methods with a local secret key. Only
+// Call is implemented; the other BusObject methods panic.
+type Object struct {
+	dbus.BusObject
+	SecretKey string
+	PublicKey string
+}
+
+// New returns a Pleb Signer client backed by a fresh key, and that key's pubkey
+func New() (*signer.PlebSigner, string) {
+	obj := NewObject(nostr.GeneratePrivateKey())
+	return signer.NewPlebSignerWithObject(obj), obj.PublicKey
+}
+
+// NewObject returns a signer object for the given secret key
+func NewObject(sk string) *Object {
+	pk, _ := nostr.GetPublicKey(sk)
+	return &Object{SecretKey: sk, PublicKey: pk}
+}
+
+// Call implements the signer's D-Bus methods
+func (o *Object) Call(method string, flags dbus.Flags, args ...any) *dbus.Call {
+	switch method {
+	case signer.Interface + ".IsReady":
+		return &dbus.Call{Body: []any{true}}
+	case signer.Interface + ".GetPublicKey":
+		npub, _ := nip19.EncodePublicKey(o.PublicKey)
+		result, _ := json.Marshal(map[string]string{"type": "public_key", "npub": npub, "hex": o.PublicKey})
+		return reply(string(result), nil)
+	case signer.Interface + ".SignEvent":
+		var evt nostr.Event
+		if err := json.Unmarshal([]byte(args[0].(string)), &evt); err != nil {
+			return reply(nil, err)
+		}
+		if err := evt.Sign(o.SecretKey); err != nil {
+			return reply(nil, err)
+		}
+		eventJSON, _ := json.Marshal(evt)
+		result, _ := json.Marshal(map[string]string{"type": "signed_event", "event_json": string(eventJSON), "signature": evt.Sig})
+		return reply(string(result), nil)
+	case signer.Interface + ".Nip04Encrypt":
+		shared, err := nip04.ComputeSharedSecret(args[1].(string), o.SecretKey)
+		if err != nil {
+			return reply(nil, err)
+		}
+		ciphertext, err := nip04.Encrypt(args[0].(string), shared)
+		return reply(ciphertext, err)
+	case signer.Interface + ".Nip04Decrypt":
+		shared, err := nip04.ComputeSharedSecret(args[1].(string), o.SecretKey)
+		if err != nil {
+			return reply(nil, err)
+		}
+		plaintext, err := nip04.Decrypt(args[0].(string), shared)
+		return reply(plaintext, err)
+	}
+	return &dbus.Call{Err: fmt.Errorf("unknown method %s", method)}
+}
+
+// reply wraps a result the way Pleb Signer does: a JSON response whose
+// result is itself JSON-encoded
+func reply(result any, err error) *dbus.Call {
+	resp := signer.SignerResponse{Success: err == nil}
+	if err != nil {
+		msg := err.Error()
+		resp.Error = &msg
+	} else {
+		resp.Result, _ = json.Marshal(result)
+	}
+	body, _ := json.Marshal(resp)
+	return &dbus.Call{Body: []any{string(body)}}
+}
@@ -24,10 +24,17 @@ func main() {
 		}
 	}
 
+	// Subcommands run without the TUI
+	if len(os.Args) > 1 && os.Args[1] == "wallet" {
+		os.Exit(runWalletCommand(os.Args[2:]))
+	}
+
 	m := tui.NewModel()
 	p := tea.NewProgram(m, tea.WithAltScreen())
 
-	if _, err := p.Run(); err != nil {
+	_, err = p.Run()
+	m.Close()
+	if err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,194 @@
+// Package client reads from and writes to Nostr relays on behalf of one
+// user: feeds, DMs, notifications and threads, publishing notes, reposts,
+// quotes and DMs, and zapping. It has no UI; the TUI wraps its methods in
+// commands, and bots and headless tools can use it directly.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+	"noscli/pkg/signer"
+	"noscli/pkg/zap"
+)
+
+// ErrNoRelays is returned when there are no relays to query or publish to
+var ErrNoRelays = errors.New("no relays configured")
+
+// ErrContactListUnavailable is returned by Follow when no relay answered
+// whether we have a contact list, so publishing one could replace it
+var ErrContactListUnavailable = errors.New("couldn't load the contact list from any relay")
+
+// Client is one user's connection to their relays. Events are signed with
+// SecretKey when it's set, and by Signer (Pleb Signer) otherwise.
+type Client struct {
+	Pool      *nostr.SimplePool
+	Relays    []string
+	PubKey    string             // Our public key, hex
+	SecretKey string             // Our private key, hex; empty to sign with Signer
+	Signer    *signer.PlebSigner // Signs and encrypts when there's no SecretKey
+	LNURL     *zap.Client        // LNURL-pay requests for zaps
+	HTTP      *http.Client       // NIP-05 lookups; nil for a default that doesn't follow redirects
+}
+
+// New creates a client for relays with its own pool and LNURL client. Set
+// PubKey and SecretKey or Signer before publishing.
+func New(relays []string) *Client {
+	return &Client{
+		Pool:   nostr.NewSimplePool(context.Background()),
+		Relays: relays,
+		LNURL:  zap.NewClient(nil),
+	}
+}
+
+// Sign signs evt with our key
+func (c *Client) Sign(evt *nostr.Event) error {
+	if c.SecretKey != "" {
+		return evt.Sign(c.SecretKey)
+	}
+	if c.Signer == nil {
+		return fmt.Errorf("no key or signer to sign with")
+	}
+	return c.Signer.SignEvent(evt)
+}
+
+// Publish signs evt unless it's already signed and sends it to our relays.
+// It succeeds if at least one relay accepts the event.
+func (c *Client) Publish(ctx context.Context, evt *nostr.Event) error {
+	if evt.Sig == "" {
+		if err := c.Sign(evt); err != nil {
+			return fmt.Errorf("failed to sign event: %w", err)
+		}
+	}
+	return c.publish(ctx, *evt)
+}
+
+// publish sends signed events to our relays, succeeding if any relay
+// accepts any of them
+func (c *Client) publish(ctx context.Context, events ...nostr.Event) error {
+	if len(c.Relays) == 0 {
+		return ErrNoRelays
+	}
+
+	accepted := 0
+	var lastErr error
+	for _, evt := range events {
+		for result := range c.Pool.PublishMany(ctx, c.Relays, evt) {
+			if result.Error == nil {
+				accepted++
+			} else {
+				lastErr = result.Error
+			}
+		}
+	}
+
+	if accepted == 0 {
+		if lastErr != nil {
+			return fmt.Errorf("failed to publish: %w", lastErr)
+		}
+		return fmt.Errorf("failed to publish to any relay (no results)")
+	}
+	return nil
+}
+
+// query collects the events matching filter from all our relays, each
+// event once
+func (c *Client) query(ctx context.Context, filter nostr.Filter) ([]nostr.Event, error) {
+	if len(c.Relays) == 0 {
+		return nil, ErrNoRelays
+	}
+
+	var events []nostr.Event
+	for event := range c.Pool.SubManyEose(ctx, c.Relays, []nostr.Filter{filter}) {
+		if event.Event == nil {
+			continue
+		}
+		events = append(events, *event.Event)
+	}
+	return events, nil
+}
+
+// queryConfirmed is query that also reports whether any relay said it sent
+// everything it has (EOSE), which tells "there's nothing" apart from relays
+// that timed out or couldn't be reached
+func (c *Client) queryConfirmed(ctx context.Context, filter nostr.Filter) ([]nostr.Event, bool, error) {
+	if len(c.Relays) == 0 {
+		return nil, false, ErrNoRelays
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		events    []nostr.Event
+		seen      = make(map[string]bool)
+		confirmed bool
+	)
+	add := func(evt *nostr.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !seen[evt.ID] {
+			seen[evt.ID] = true
+			events = append(events, *evt)
+		}
+	}
+
+	urls := make(map[string]bool)
+	for _, url := range c.Relays {
+		url = nostr.NormalizeURL(url)
+		if urls[url] {
+			continue
+		}
+		urls[url] = true
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			relay, err := c.Pool.EnsureRelay(url)
+			if err != nil {
+				return
+			}
+			sub, err := relay.Subscribe(ctx, nostr.Filters{filter})
+			if err != nil {
+				return
+			}
+			defer sub.Unsub()
+			for {
+				select {
+				case evt, ok := <-sub.Events:
+					if !ok {
+						return
+					}
+					add(evt)
+				case <-sub.EndOfStoredEvents:
+					// Events sent before EOSE may still be buffered
+				drain:
+					for {
+						select {
+						case evt, ok := <-sub.Events:
+							if !ok {
+								break drain
+							}
+							add(evt)
+						default:
+							break drain
+						}
+					}
+					mu.Lock()
+					confirmed = true
+					mu.Unlock()
+					return
+				case <-sub.ClosedReason:
+					return
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return events, confirmed, nil
+}
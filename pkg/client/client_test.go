@@ -0,0 +1,336 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"noscli/internal/mockwallet"
+	"noscli/internal/testrelay"
+	"noscli/internal/testsigner"
+	"noscli/pkg/nwc"
+	"noscli/pkg/zap"
+)
+
+// newTestClient starts a relay and returns it with a client for it that
+// signs with a fresh secret key
+func newTestClient(t *testing.T) (*testrelay.Relay, *Client) {
+	t.Helper()
+	relay := testrelay.New()
+	t.Cleanup(relay.Close)
+
+	c := New([]string{relay.URL})
+	c.SecretKey = nostr.GeneratePrivateKey()
+	c.PubKey, _ = nostr.GetPublicKey(c.SecretKey)
+	return relay, c
+}
+
+// stored returns the event with id from relay, failing the test if it
+// isn't there
+func stored(t *testing.T, relay *testrelay.Relay, id string) nostr.Event {
+	t.Helper()
+	events := relay.Events(nostr.Filter{IDs: []string{id}})
+	if len(events) != 1 {
+		t.Fatalf("event %s wasn't stored", id)
+	}
+	return events[0]
+}
+
+func TestSign(t *testing.T) {
+	_, c := newTestClient(t)
+	s, signerPK := testsigner.New()
+	c.Signer = s
+
+	// The secret key wins over the signer
+	evt := &nostr.Event{Kind: nostr.KindTextNote, CreatedAt: nostr.Now()}
+	if err := c.Sign(evt); err != nil || evt.PubKey != c.PubKey {
+		t.Errorf("signed by %s (%v), want the secret key's %s", evt.PubKey, err, c.PubKey)
+	}
+
+	c.SecretKey = ""
+	evt = &nostr.Event{Kind: nostr.KindTextNote, CreatedAt: nostr.Now()}
+	if err := c.Sign(evt); err != nil || evt.PubKey != signerPK {
+		t.Errorf("signed by %s (%v), want the signer's %s", evt.PubKey, err, signerPK)
+	}
+
+	c.Signer = nil
+	if err := c.Sign(&nostr.Event{}); err == nil {
+		t.Error("signed without a key or signer")
+	}
+}
+
+func TestNoRelays(t *testing.T) {
+	c := New(nil)
+	if _, err := c.FetchFeed(context.Background(), nil); !errors.Is(err, ErrNoRelays) {
+		t.Errorf("FetchFeed: err = %v, want ErrNoRelays", err)
+	}
+	c.SecretKey = nostr.GeneratePrivateKey()
+	if _, err := c.Post(context.Background(), "hello", nil, nil); !errors.Is(err, ErrNoRelays) {
+		t.Errorf("Post: err = %v, want ErrNoRelays", err)
+	}
+}
+
+func TestQuoteAndRepost(t *testing.T) {
+	relay, c := newTestClient(t)
+	alice := nostr.GeneratePrivateKey()
+	note := nostr.Event{Kind: nostr.KindTextNote, Content: "worth sharing", CreatedAt: 100}
+	note.Sign(alice)
+	relay.Publish(note)
+
+	quote, err := c.Quote(context.Background(), "look", &note, []zap.Split{{Pubkey: note.PubKey, Weight: 1}})
+	if err != nil {
+		t.Fatalf("Quote: %v", err)
+	}
+	got := stored(t, relay, quote.ID)
+	if got.PubKey != c.PubKey || !strings.HasPrefix(got.Content, "look\nnostr:nevent1") {
+		t.Errorf("quote = %q by %s", got.Content, got.PubKey)
+	}
+	if got.Tags.GetFirst([]string{"e", note.ID}) == nil || got.Tags.GetFirst([]string{"zap", note.PubKey}) == nil {
+		t.Errorf("quote tags = %v", got.Tags)
+	}
+
+	repost, err := c.Repost(context.Background(), &note)
+	if err != nil {
+		t.Fatalf("Repost: %v", err)
+	}
+	if got := stored(t, relay, repost.ID); got.Kind != nostr.KindRepost || got.PubKey != c.PubKey {
+		t.Errorf("repost = %+v", got)
+	}
+}
+
+func TestFollow(t *testing.T) {
+	relay, c := newTestClient(t)
+	bob, carol := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	bobPK, _ := nostr.GetPublicKey(bob)
+	carolPK, _ := nostr.GetPublicKey(carol)
+	contacts := nostr.Event{Kind: nostr.KindFollowList, Content: `{"wss://relay.example.com":{}}`, CreatedAt: 100,
+		Tags: nostr.Tags{{"p", bobPK}, {"t", "nostr"}}}
+	contacts.Sign(c.SecretKey)
+	relay.Publish(contacts)
+
+	following, err := c.Follow(context.Background(), carolPK)
+	if err != nil {
+		t.Fatalf("Follow: %v", err)
+	}
+	if len(following) != 2 || following[1] != carolPK {
+		t.Errorf("following %v", following)
+	}
+	got, err := c.FetchFollowing(context.Background())
+	if err != nil || len(got) != 2 || got[0] != bobPK || got[1] != carolPK {
+		t.Errorf("FetchFollowing = %v, %v", got, err)
+	}
+	newest, _, _ := c.fetchContactList(context.Background())
+	if newest.Content != contacts.Content || newest.Tags.GetFirst([]string{"t", "nostr"}) == nil {
+		t.Errorf("lost the rest of the list: %+v", newest)
+	}
+
+	// Following someone twice doesn't publish again
+	if _, err := c.Follow(context.Background(), bobPK); err != nil {
+		t.Fatalf("Follow: %v", err)
+	}
+	if again, _, _ := c.fetchContactList(context.Background()); again.ID != newest.ID {
+		t.Error("published a list that didn't change")
+	}
+}
+
+func TestFollowWithoutContactList(t *testing.T) {
+	relay, c := newTestClient(t)
+	bob := nostr.GeneratePrivateKey()
+	bobPK, _ := nostr.GetPublicKey(bob)
+
+	// A relay that has no list for us starts one
+	following, err := c.Follow(context.Background(), bobPK)
+	if err != nil || len(following) != 1 || following[0] != bobPK {
+		t.Fatalf("Follow = %v, %v", following, err)
+	}
+
+	// A relay that doesn't answer could be hiding a list we'd replace
+	contacts := relay.Events(nostr.Filter{Kinds: []int{nostr.KindFollowList}})[0]
+	relay.Stall()
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if _, err := c.Follow(ctx, nostr.GeneratePrivateKey()); !errors.Is(err, ErrContactListUnavailable) {
+		t.Errorf("stalled relay: err = %v, want ErrContactListUnavailable", err)
+	}
+	if lists := relay.Events(nostr.Filter{Kinds: []int{nostr.KindFollowList}}); len(lists) != 1 || lists[0].ID != contacts.ID {
+		t.Errorf("contact list was replaced: %v", lists)
+	}
+
+	// And so could one that can't be reached
+	c.Relays = []string{"ws://127.0.0.1:1"}
+	if _, err := c.Follow(context.Background(), bobPK); !errors.Is(err, ErrContactListUnavailable) {
+		t.Errorf("unreachable relay: err = %v, want ErrContactListUnavailable", err)
+	}
+}
+
+func TestFetchEvent(t *testing.T) {
+	relay, c := newTestClient(t)
+	note := nostr.Event{Kind: nostr.KindTextNote, Content: "find me", CreatedAt: 100}
+	note.Sign(nostr.GeneratePrivateKey())
+	relay.Publish(note)
+
+	if evt, err := c.FetchEvent(context.Background(), note.ID); err != nil || evt == nil || evt.Content != "find me" {
+		t.Errorf("FetchEvent = %v, %v", evt, err)
+	}
+	if evt, err := c.FetchEvent(context.Background(), strings.Repeat("0", 64)); err != nil || evt != nil {
+		t.Errorf("FetchEvent of a missing note = %v, %v", evt, err)
+	}
+}
+
+func TestSendDM(t *testing.T) {
+	relay, c := newTestClient(t)
+	_, alice := newTestClient(t)
+	alice.Pool, alice.Relays = c.Pool, c.Relays
+
+	// With a secret key it's NIP-17, and the recipient can unwrap it
+	wrap, err := c.SendDM(context.Background(), alice.PubKey, "secret")
+	if err != nil {
+		t.Fatalf("SendDM: %v", err)
+	}
+	if wrap.Kind != nostr.KindGiftWrap {
+		t.Fatalf("kind %d, want a gift wrap", wrap.Kind)
+	}
+	rumor, err := alice.UnwrapDM(stored(t, relay, wrap.ID))
+	if err != nil || rumor.Content != "secret" || rumor.PubKey != c.PubKey {
+		t.Errorf("alice unwrapped %+v, %v", rumor, err)
+	}
+
+	// Through Pleb Signer it's NIP-04
+	s, signerPK := testsigner.New()
+	c.SecretKey, c.PubKey, c.Signer = "", signerPK, s
+	dm, err := c.SendDM(context.Background(), alice.PubKey, "also secret")
+	if err != nil {
+		t.Fatalf("SendDM: %v", err)
+	}
+	if dm.Kind != nostr.KindEncryptedDirectMessage {
+		t.Fatalf("kind %d, want a NIP-04 DM", dm.Kind)
+	}
+	plaintext, err := alice.DecryptDM(stored(t, relay, dm.ID).Content, signerPK)
+	if err != nil || plaintext != "also secret" {
+		t.Errorf("alice decrypted %q, %v", plaintext, err)
+	}
+
+	// We can't unwrap NIP-17 without the key
+	if _, err := c.UnwrapDM(*wrap); err == nil {
+		t.Error("unwrapped a gift wrap through Pleb Signer")
+	}
+}
+
+func TestZap(t *testing.T) {
+	relay, c := newTestClient(t)
+	wallet := mockwallet.New(relay, 50_000_000)
+
+	var zapRequest nostr.Event
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/lnurlp/alice":
+			json.NewEncoder(w).Encode(zap.LNURLResponse{
+				Callback:    server.URL + "/callback",
+				MinSendable: 1000,
+				MaxSendable: 100_000_000,
+				Tag:         "payRequest",
+				AllowsNostr: true,
+			})
+		case "/callback":
+			description := r.URL.Query().Get("nostr")
+			json.Unmarshal([]byte(description), &zapRequest)
+			amount, _ := strconv.ParseInt(r.URL.Query().Get("amount"), 10, 64)
+			invoice, _ := mockwallet.Invoice(amount, description)
+			json.NewEncoder(w).Encode(map[string]string{"pr": invoice})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	// Trust the test server's certificate
+	c.LNURL = zap.NewClient(server.Client())
+
+	aliceSK := nostr.GeneratePrivateKey()
+	profile := nostr.Event{
+		Kind:      nostr.KindProfileMetadata,
+		Content:   `{"name":"alice","lud16":"alice@` + strings.TrimPrefix(server.URL, "https://") + `"}`,
+		CreatedAt: 100,
+	}
+	profile.Sign(aliceSK)
+	relay.Publish(profile)
+
+	target, err := c.ResolveZapTarget(context.Background(), profile.PubKey)
+	if err != nil {
+		t.Fatalf("ResolveZapTarget: %v", err)
+	}
+	if !strings.HasPrefix(target.Address, "alice@") || target.Info.Callback != server.URL+"/callback" {
+		t.Errorf("target = %+v", target)
+	}
+
+	payer, err := nwc.NewNWCClient(wallet.URI())
+	if err != nil {
+		t.Fatalf("NewNWCClient: %v", err)
+	}
+	defer payer.Close()
+
+	result, err := c.Zap(context.Background(), target, ZapOptions{EventID: strings.Repeat("ab", 32), AmountSats: 21, Comment: "gm"}, payer)
+	if err != nil {
+		t.Fatalf("Zap: %v", err)
+	}
+	if result.Preimage == "" || result.Invoice == "" {
+		t.Errorf("result = %+v", result)
+	}
+	if zapRequest.PubKey != c.PubKey || zapRequest.Content != "gm" ||
+		zapRequest.Tags.GetFirst([]string{"p", profile.PubKey}) == nil ||
+		zapRequest.Tags.GetFirst([]string{"amount", "21000"}) == nil {
+		t.Errorf("zap request = %+v", zapRequest)
+	}
+	if got := wallet.Balance(); got != 50_000_000-21_000 {
+		t.Errorf("wallet balance = %d msats, want 21 sats less", got)
+	}
+
+	// Anonymous zaps aren't signed with our key
+	if _, err := c.Zap(context.Background(), target, ZapOptions{AmountSats: 21, Anon: true}, payer); err != nil {
+		t.Fatalf("anonymous Zap: %v", err)
+	}
+	if zapRequest.PubKey == c.PubKey {
+		t.Error("anonymous zap request is signed with our key")
+	}
+}
+
+func TestFetchProfiles(t *testing.T) {
+	relay1, c := newTestClient(t)
+	relay2 := testrelay.New()
+	defer relay2.Close()
+	c.Relays = append(c.Relays, relay2.URL)
+
+	alice, bob := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	profile := func(sk, content string, createdAt nostr.Timestamp) nostr.Event {
+		evt := nostr.Event{Kind: nostr.KindProfileMetadata, Content: content, CreatedAt: createdAt}
+		evt.Sign(sk)
+		return evt
+	}
+	// Relays that missed alice's update still serve her old profile
+	relay1.Publish(profile(alice, `{"name":"alice","display_name":"Alice Old"}`, 100))
+	relay2.Publish(profile(alice, `{"name":"alice","display_name":"Alice"}`, 200))
+	relay1.Publish(profile(bob, `not json`, 100))
+	alicePK, _ := nostr.GetPublicKey(alice)
+	bobPK, _ := nostr.GetPublicKey(bob)
+
+	profiles, err := c.FetchProfiles(context.Background(), []string{alicePK, bobPK})
+	if err != nil {
+		t.Fatalf("FetchProfiles: %v", err)
+	}
+	if got := profiles[alicePK]; got == nil || got.BestName() != "Alice" || got.CreatedAt != 200 {
+		t.Errorf("alice = %+v, want the newest profile", got)
+	}
+	if _, ok := profiles[bobPK]; ok {
+		t.Error("got a profile for bob's unparseable metadata")
+	}
+	if requests := len(relay1.Requests()); requests != 1 {
+		t.Errorf("%d queries, want one for both authors", requests)
+	}
+}
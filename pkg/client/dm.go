@@ -0,0 +1,53 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip04"
+	"github.com/nbd-wtf/go-nostr/nip44"
+	"github.com/nbd-wtf/go-nostr/nip59"
+)
+
+// DecryptDM decrypts the content of a NIP-04 DM exchanged with otherPubkey
+func (c *Client) DecryptDM(ciphertext, otherPubkey string) (string, error) {
+	if c.SecretKey != "" {
+		sharedSecret, err := nip04.ComputeSharedSecret(otherPubkey, c.SecretKey)
+		if err != nil {
+			return "", err
+		}
+		return nip04.Decrypt(ciphertext, sharedSecret)
+	}
+	if c.Signer == nil {
+		return "", fmt.Errorf("no key or signer to decrypt with")
+	}
+	// Pleb Signer takes the sender's pubkey first, then the ciphertext
+	return c.Signer.Nip04Decrypt(otherPubkey, ciphertext)
+}
+
+// UnwrapDM opens a NIP-17 gift wrap (kind 1059) addressed to us and returns
+// the DM inside
+func (c *Client) UnwrapDM(giftWrap nostr.Event) (*nostr.Event, error) {
+	if c.SecretKey == "" {
+		// Pleb Signer doesn't support NIP-44 yet
+		return nil, fmt.Errorf("NIP-17 DMs require nsec authentication (Pleb Signer NIP-44 support coming soon)")
+	}
+
+	decrypt := func(otherPubkey, ciphertext string) (string, error) {
+		conversationKey, err := nip44.GenerateConversationKey(otherPubkey, c.SecretKey)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate conversation key: %w", err)
+		}
+		plaintext, err := nip44.Decrypt(ciphertext, conversationKey)
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt: %w", err)
+		}
+		return plaintext, nil
+	}
+
+	rumor, err := nip59.GiftUnwrap(giftWrap, decrypt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap gift: %w", err)
+	}
+	return &rumor, nil
+}
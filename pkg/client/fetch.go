@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"sort"
+
+	"github.com/nbd-wtf/go-nostr"
+	"noscli/pkg/zap"
+)
+
+// FetchFollowing returns the pubkeys in our newest contact list (kind 3)
+func (c *Client) FetchFollowing(ctx context.Context) ([]string, error) {
+	contacts, _, err := c.fetchContactList(ctx)
+	if err != nil || contacts == nil {
+		return nil, err
+	}
+
+	var following []string
+	for _, tag := range contacts.Tags {
+		if len(tag) >= 2 && tag[0] == "p" {
+			following = append(following, tag[1])
+		}
+	}
+	return following, nil
+}
+
+// fetchContactList returns our newest contact list, or nil if none was
+// found. confirmed reports whether a relay said it has nothing more, so a
+// nil list means we really have none rather than that no relay answered.
+func (c *Client) fetchContactList(ctx context.Context) (contacts *nostr.Event, confirmed bool, err error) {
+	lists, confirmed, err := c.queryConfirmed(ctx, nostr.Filter{
+		Kinds:   []int{nostr.KindFollowList},
+		Authors: []string{c.PubKey},
+		Limit:   1,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	for i := range lists {
+		if contacts == nil || lists[i].CreatedAt > contacts.CreatedAt {
+			contacts = &lists[i]
+		}
+	}
+	return contacts, confirmed, nil
+}
+
+// FetchFeed returns the latest notes by authors, or the global feed if
+// authors is empty
+func (c *Client) FetchFeed(ctx context.Context, authors []string) ([]nostr.Event, error) {
+	return c.FetchCustomFeed(ctx, authors, nil)
+}
+
+// FetchCustomFeed returns the latest notes by authors with any of hashtags.
+// Either can be empty to not filter on it.
+func (c *Client) FetchCustomFeed(ctx context.Context, authors, hashtags []string) ([]nostr.Event, error) {
+	filter := nostr.Filter{
+		Kinds: []int{nostr.KindTextNote},
+		Limit: 50,
+	}
+	if len(authors) > 0 {
+		filter.Authors = authors
+	}
+	if len(hashtags) > 0 {
+		filter.Tags = nostr.TagMap{"t": hashtags}
+	}
+	return c.query(ctx, filter)
+}
+
+// FetchSearch returns the latest notes matching query, from relays that
+// support NIP-50 search
+func (c *Client) FetchSearch(ctx context.Context, query string) ([]nostr.Event, error) {
+	return c.query(ctx, nostr.Filter{
+		Kinds:  []int{nostr.KindTextNote},
+		Search: query,
+		Limit:  50,
+	})
+}
+
+// FetchEvent returns the event with id, or nil if no relay has it
+func (c *Client) FetchEvent(ctx context.Context, id string) (*nostr.Event, error) {
+	events, err := c.query(ctx, nostr.Filter{IDs: []string{id}, Limit: 1})
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
+	return &events[0], nil
+}
+
+// FetchDMs returns NIP-04 DMs (kind 4) and NIP-17 gift wraps (kind 1059)
+// sent to us, and the NIP-04 DMs we sent. They're returned encrypted; see
+// DecryptDM and UnwrapDM.
+func (c *Client) FetchDMs(ctx context.Context) ([]nostr.Event, error) {
+	received, err := c.query(ctx, nostr.Filter{
+		Kinds: []int{nostr.KindEncryptedDirectMessage, nostr.KindGiftWrap},
+		Tags:  nostr.TagMap{"p": []string{c.PubKey}},
+		Limit: 50,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sent, err := c.query(ctx, nostr.Filter{
+		Kinds:   []int{nostr.KindEncryptedDirectMessage},
+		Authors: []string{c.PubKey},
+		Limit:   50,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(received, sent...), nil
+}
+
+// FetchNotifications returns notes that tag us (mentions and replies),
+// reactions to us and zap receipts for us
+func (c *Client) FetchNotifications(ctx context.Context) ([]nostr.Event, error) {
+	filters := []nostr.Filter{
+		{Kinds: []int{nostr.KindTextNote}, Limit: 30},
+		{Kinds: []int{nostr.KindReaction}, Limit: 20},
+		{Kinds: []int{zap.KindZapReceipt}, Limit: 30},
+	}
+
+	var notifications []nostr.Event
+	for _, filter := range filters {
+		filter.Tags = nostr.TagMap{"p": []string{c.PubKey}}
+		events, err := c.query(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, events...)
+	}
+	return notifications, nil
+}
+
+// FetchThread returns the notes that reference root, oldest first
+func (c *Client) FetchThread(ctx context.Context, root *nostr.Event) ([]nostr.Event, error) {
+	events, err := c.query(ctx, nostr.Filter{
+		Kinds: []int{nostr.KindTextNote},
+		Tags:  nostr.TagMap{"e": []string{root.ID}},
+		Limit: 100,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].CreatedAt < events[j].CreatedAt
+	})
+	return events, nil
+}
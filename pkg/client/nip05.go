@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr/nip05"
+)
+
+// maxNIP05Response caps how much of a nostr.json we read; some domains
+// list every user they host
+const maxNIP05Response = 1 << 20
+
+// nip05HTTP is used when Client.HTTP is nil. NIP-05 forbids following
+// redirects.
+var nip05HTTP = &http.Client{
+	Timeout: 10 * time.Second,
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// VerifyNIP05 checks that identifier (name@domain) maps to pubkey in the
+// domain's /.well-known/nostr.json. It returns false with no error when the
+// domain answers but doesn't vouch for pubkey, and an error when the check
+// couldn't be made, so it can be retried.
+func (c *Client) VerifyNIP05(ctx context.Context, pubkey, identifier string) (bool, error) {
+	name, domain, err := nip05.ParseIdentifier(identifier)
+	if err != nil {
+		// A malformed identifier can't ever verify
+		return false, nil
+	}
+
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = nip05HTTP
+	}
+
+	wellKnown := fmt.Sprintf("https://%s/.well-known/nostr.json?name=%s", domain, url.QueryEscape(name))
+	req, err := http.NewRequestWithContext(ctx, "GET", wellKnown, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch %s: %w", wellKnown, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("failed to fetch %s: status %d", wellKnown, resp.StatusCode)
+	}
+
+	var result nip05.WellKnownResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxNIP05Response)).Decode(&result); err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", wellKnown, err)
+	}
+	return result.Names[name] == pubkey, nil
+}
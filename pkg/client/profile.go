@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// profileBatchSize caps the authors in one kind 0 query; relays limit how
+// large a filter they accept
+const profileBatchSize = 100
+
+// Profile is a user's parsed metadata (kind 0)
+type Profile struct {
+	PubKey      string          `json:"pubkey"`
+	Name        string          `json:"name,omitempty"`
+	DisplayName string          `json:"display_name,omitempty"`
+	Username    string          `json:"username,omitempty"` // Non-standard, but some clients only set this
+	Picture     string          `json:"picture,omitempty"`
+	About       string          `json:"about,omitempty"`
+	NIP05       string          `json:"nip05,omitempty"` // Claimed identifier; see VerifyNIP05
+	LUD16       string          `json:"lud16,omitempty"`
+	LUD06       string          `json:"lud06,omitempty"`
+	CreatedAt   nostr.Timestamp `json:"created_at"`
+}
+
+// ParseProfile parses a kind 0 event. Fields of the wrong type are left
+// empty rather than failing the whole profile.
+func ParseProfile(evt *nostr.Event) (*Profile, error) {
+	var metadata map[string]any
+	if err := json.Unmarshal([]byte(evt.Content), &metadata); err != nil {
+		return nil, fmt.Errorf("invalid profile metadata: %w", err)
+	}
+	field := func(key string) string {
+		value, _ := metadata[key].(string)
+		return strings.TrimSpace(value)
+	}
+	return &Profile{
+		PubKey:      evt.PubKey,
+		Name:        field("name"),
+		DisplayName: field("display_name"),
+		Username:    field("username"),
+		Picture:     field("picture"),
+		About:       field("about"),
+		NIP05:       field("nip05"),
+		LUD16:       field("lud16"),
+		LUD06:       field("lud06"),
+		CreatedAt:   evt.CreatedAt,
+	}, nil
+}
+
+// BestName returns display_name, falling back to name and then username
+func (p *Profile) BestName() string {
+	if p.DisplayName != "" {
+		return p.DisplayName
+	}
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.Username
+}
+
+// LightningAddress returns the lud16 address, or else the lud06 LNURL
+func (p *Profile) LightningAddress() string {
+	if p.LUD16 != "" {
+		return p.LUD16
+	}
+	return p.LUD06
+}
+
+// FetchProfiles returns the newest profile of each of pubkeys that has
+// one, querying the relays for up to profileBatchSize authors at a time.
+// Profiles that don't parse are skipped.
+func (c *Client) FetchProfiles(ctx context.Context, pubkeys []string) (map[string]*Profile, error) {
+	profiles := make(map[string]*Profile)
+	for start := 0; start < len(pubkeys); start += profileBatchSize {
+		batch := pubkeys[start:min(start+profileBatchSize, len(pubkeys))]
+		events, err := c.query(ctx, nostr.Filter{
+			Kinds:   []int{nostr.KindProfileMetadata},
+			Authors: batch,
+		})
+		if err != nil {
+			return profiles, err
+		}
+
+		for i := range events {
+			if existing, ok := profiles[events[i].PubKey]; ok && existing.CreatedAt >= events[i].CreatedAt {
+				continue
+			}
+			profile, err := ParseProfile(&events[i])
+			if err != nil {
+				continue
+			}
+			profiles[profile.PubKey] = profile
+		}
+	}
+	return profiles, nil
+}
@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestParseProfile(t *testing.T) {
+	evt := &nostr.Event{
+		PubKey:    strings.Repeat("ab", 32),
+		CreatedAt: 100,
+		Content: `{"name":"alice","display_name":" Alice ","picture":"https://example.com/a.png",` +
+			`"about":"hi","nip05":"alice@example.com","lud16":"alice@getalby.com","lud06":42}`,
+	}
+	profile, err := ParseProfile(evt)
+	if err != nil {
+		t.Fatalf("ParseProfile: %v", err)
+	}
+	want := Profile{
+		PubKey:      evt.PubKey,
+		Name:        "alice",
+		DisplayName: "Alice",
+		Picture:     "https://example.com/a.png",
+		About:       "hi",
+		NIP05:       "alice@example.com",
+		LUD16:       "alice@getalby.com",
+		CreatedAt:   100,
+	}
+	if *profile != want {
+		t.Errorf("got %+v, want %+v (a numeric lud06 is ignored)", *profile, want)
+	}
+	if profile.BestName() != "Alice" || profile.LightningAddress() != "alice@getalby.com" {
+		t.Errorf("BestName %q, LightningAddress %q", profile.BestName(), profile.LightningAddress())
+	}
+
+	if _, err := ParseProfile(&nostr.Event{Content: "not json"}); err == nil {
+		t.Error("parsed invalid metadata")
+	}
+}
+
+func TestProfileCache(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	pk := strings.Repeat("ab", 32)
+	cache := NewProfileCache()
+
+	cache.Put(&Profile{PubKey: pk, Name: "new", NIP05: "a@example.com", CreatedAt: 200}, now)
+	cache.SetNIP05(pk, "a@example.com", true, now)
+
+	// An older profile from a lagging relay doesn't win, but counts as a fetch
+	later := now.Add(time.Hour)
+	cache.Put(&Profile{PubKey: pk, Name: "old", CreatedAt: 100}, later)
+	if got := cache.Get(pk); got.Name != "new" || !got.FetchedAt.Equal(later) || !got.NIP05Valid {
+		t.Errorf("after an older profile: %+v", got)
+	}
+
+	// A newer profile keeps the NIP-05 result only if the identifier is the same
+	cache.Put(&Profile{PubKey: pk, Name: "newer", NIP05: "a@example.com", CreatedAt: 300}, later)
+	if got := cache.Get(pk); !got.NIP05Valid || got.NIP05Due(later) {
+		t.Errorf("same identifier lost its check: %+v", got)
+	}
+	cache.Put(&Profile{PubKey: pk, Name: "moved", NIP05: "a@elsewhere.com", CreatedAt: 400}, later)
+	if got := cache.Get(pk); got.NIP05Valid || !got.NIP05Due(later) {
+		t.Errorf("new identifier kept the old check: %+v", got)
+	}
+	// A check that started before the identifier changed is ignored
+	cache.SetNIP05(pk, "a@example.com", true, later)
+	if cache.Get(pk).NIP05Valid {
+		t.Error("stale NIP-05 result was stored")
+	}
+
+	cached := cache.Get(pk)
+	if cached.Stale(later) || !cached.Stale(later.Add(ProfileStaleAfter+time.Minute)) {
+		t.Errorf("Stale is wrong around ProfileStaleAfter")
+	}
+}
+
+func TestProfileCacheSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	fresh, expired := strings.Repeat("ab", 32), strings.Repeat("cd", 32)
+
+	cache := NewProfileCache()
+	cache.Put(&Profile{PubKey: fresh, Name: "alice", LUD16: "alice@getalby.com", CreatedAt: 100}, time.Now())
+	cache.SetNIP05(fresh, "", true, time.Now()) // No identifier, so nothing to record
+	cache.Profiles[expired] = &CachedProfile{Profile: Profile{PubKey: expired, Name: "gone"}, FetchedAt: time.Now().Add(-ProfileExpireAfter - time.Hour)}
+	if err := cache.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadProfileCache(path)
+	if err != nil {
+		t.Fatalf("LoadProfileCache: %v", err)
+	}
+	if got := loaded.Get(fresh); got == nil || got.Name != "alice" || got.LightningAddress() != "alice@getalby.com" {
+		t.Errorf("fresh profile = %+v", got)
+	}
+	if loaded.Get(expired) != nil {
+		t.Error("expired profile was kept")
+	}
+
+	// No file yet is an empty cache
+	empty, err := LoadProfileCache(filepath.Join(t.TempDir(), "none.json"))
+	if err != nil || len(empty.Profiles) != 0 {
+		t.Errorf("missing file: %v, %v", empty, err)
+	}
+}
+
+func TestVerifyNIP05(t *testing.T) {
+	alice, bob := strings.Repeat("ab", 32), strings.Repeat("cd", 32)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/nostr.json" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("name") == "broken" {
+			http.Error(w, "oops", http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"names": map[string]string{"alice": alice, "_": bob}})
+	}))
+	defer server.Close()
+
+	// Identifiers can't carry the test server's port, so example.com (which
+	// its certificate is valid for) is dialed to it
+	c := New(nil)
+	c.HTTP = server.Client()
+	transport := c.HTTP.Transport.(*http.Transport)
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	domain := "example.com"
+
+	tests := []struct {
+		pubkey, identifier string
+		valid, fails       bool
+	}{
+		{alice, "alice@" + domain, true, false},
+		{bob, domain, true, false}, // A bare domain is _@domain
+		{bob, "alice@" + domain, false, false},
+		{alice, "nobody@" + domain, false, false},
+		{alice, "broken@" + domain, false, true},
+		{alice, "not an identifier", false, false},
+	}
+	for _, tt := range tests {
+		valid, err := c.VerifyNIP05(context.Background(), tt.pubkey, tt.identifier)
+		if valid != tt.valid || (err != nil) != tt.fails {
+			t.Errorf("VerifyNIP05(%s, %q) = %v, %v; want %v, error %v", tt.pubkey[:4], tt.identifier, valid, err, tt.valid, tt.fails)
+		}
+	}
+}
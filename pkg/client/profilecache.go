@@ -0,0 +1,154 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Staleness policy for cached profiles. Stale profiles are still shown and
+// refreshed in the background; expired ones are forgotten.
+const (
+	ProfileStaleAfter  = 24 * time.Hour
+	ProfileExpireAfter = 30 * 24 * time.Hour
+	NIP05RecheckAfter  = 24 * time.Hour
+)
+
+// CachedProfile is a profile with when we fetched it and what we know
+// about its NIP-05 identifier
+type CachedProfile struct {
+	Profile
+	FetchedAt      time.Time `json:"fetched_at"`
+	NIP05Valid     bool      `json:"nip05_valid,omitempty"`
+	NIP05CheckedAt time.Time `json:"nip05_checked_at,omitzero"` // Zero until the identifier was checked
+}
+
+// Stale reports whether the profile should be fetched again
+func (p *CachedProfile) Stale(now time.Time) bool {
+	return now.Sub(p.FetchedAt) > ProfileStaleAfter
+}
+
+// NIP05Due reports whether the profile's NIP-05 identifier needs checking
+func (p *CachedProfile) NIP05Due(now time.Time) bool {
+	return p.NIP05 != "" && (p.NIP05CheckedAt.IsZero() || now.Sub(p.NIP05CheckedAt) > NIP05RecheckAfter)
+}
+
+// ProfileCache holds profiles by pubkey and persists them as JSON. It isn't
+// safe for concurrent use.
+type ProfileCache struct {
+	Profiles map[string]*CachedProfile `json:"profiles"`
+}
+
+// NewProfileCache returns an empty cache
+func NewProfileCache() *ProfileCache {
+	return &ProfileCache{Profiles: make(map[string]*CachedProfile)}
+}
+
+// LoadProfileCache reads a cache written by Save, returning an empty one if
+// the file doesn't exist. Expired profiles are dropped.
+func LoadProfileCache(path string) (*ProfileCache, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewProfileCache(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile cache: %w", err)
+	}
+
+	cache := NewProfileCache()
+	if err := json.Unmarshal(data, cache); err != nil {
+		return nil, fmt.Errorf("failed to parse profile cache: %w", err)
+	}
+	if cache.Profiles == nil {
+		cache.Profiles = make(map[string]*CachedProfile)
+	}
+	cache.Prune(time.Now())
+	return cache, nil
+}
+
+// Save writes the cache to path, dropping expired profiles first
+func (c *ProfileCache) Save(path string) error {
+	data, err := c.Encode()
+	if err != nil {
+		return err
+	}
+	return WriteProfileCache(path, data)
+}
+
+// Encode drops expired profiles and returns the cache as JSON, so callers
+// can write it with WriteProfileCache without holding their lock
+func (c *ProfileCache) Encode() ([]byte, error) {
+	c.Prune(time.Now())
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal profile cache: %w", err)
+	}
+	return data, nil
+}
+
+// WriteProfileCache writes an encoded cache to path. It goes through a
+// temporary file in the same directory, so a crash mid-write leaves the old
+// cache in place rather than half a file.
+func WriteProfileCache(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write profile cache: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly once renamed
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write profile cache: %w", err)
+	}
+	return nil
+}
+
+// Get returns the cached profile of pubkey, or nil
+func (c *ProfileCache) Get(pubkey string) *CachedProfile {
+	return c.Profiles[pubkey]
+}
+
+// Put stores a fetched profile unless we have a newer one, and marks the
+// pubkey as fetched at now either way. A changed NIP-05 identifier has to be
+// checked again.
+func (c *ProfileCache) Put(profile *Profile, now time.Time) {
+	existing := c.Profiles[profile.PubKey]
+	if existing != nil && existing.CreatedAt > profile.CreatedAt {
+		existing.FetchedAt = now
+		return
+	}
+
+	cached := &CachedProfile{Profile: *profile, FetchedAt: now}
+	if existing != nil && existing.NIP05 == profile.NIP05 {
+		cached.NIP05Valid, cached.NIP05CheckedAt = existing.NIP05Valid, existing.NIP05CheckedAt
+	}
+	c.Profiles[profile.PubKey] = cached
+}
+
+// SetNIP05 records the result of checking pubkey's NIP-05 identifier. It's
+// ignored if the profile changed its identifier since the check started.
+func (c *ProfileCache) SetNIP05(pubkey, identifier string, valid bool, now time.Time) {
+	cached := c.Profiles[pubkey]
+	if cached == nil || cached.NIP05 != identifier {
+		return
+	}
+	cached.NIP05Valid, cached.NIP05CheckedAt = valid, now
+}
+
+// Prune drops profiles fetched more than ProfileExpireAfter before now
+func (c *ProfileCache) Prune(now time.Time) {
+	for pubkey, cached := range c.Profiles {
+		if now.Sub(cached.FetchedAt) > ProfileExpireAfter {
+			delete(c.Profiles, pubkey)
+		}
+	}
+}
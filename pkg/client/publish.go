@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/nbd-wtf/go-nostr/nip44"
+	"github.com/nbd-wtf/go-nostr/nip59"
+	"noscli/pkg/zap"
+)
+
+// Post publishes a note, as a reply to replyTo if it's not nil, with zap
+// split tags for splits
+func (c *Client) Post(ctx context.Context, content string, replyTo *nostr.Event, splits []zap.Split) (*nostr.Event, error) {
+	evt := &nostr.Event{
+		Kind:      nostr.KindTextNote,
+		Content:   content,
+		CreatedAt: nostr.Now(),
+		Tags:      nostr.Tags{},
+	}
+
+	if replyTo != nil {
+		evt.Tags = append(evt.Tags, nostr.Tag{"e", replyTo.ID, "", "reply"})
+		evt.Tags = append(evt.Tags, nostr.Tag{"p", replyTo.PubKey})
+
+		// Replies to replies keep pointing at the thread's root
+		for _, tag := range replyTo.Tags {
+			if len(tag) >= 2 && tag[0] == "e" {
+				evt.Tags = append(evt.Tags, nostr.Tag{"e", tag[1], "", "root"})
+				break
+			}
+		}
+	}
+
+	// NIP-57 zap split tags
+	evt.Tags = append(evt.Tags, zap.SplitTags(splits)...)
+
+	if err := c.Publish(ctx, evt); err != nil {
+		return nil, err
+	}
+	return evt, nil
+}
+
+// Quote publishes a note quoting quoted, with a nostr:nevent reference
+// appended to content
+func (c *Client) Quote(ctx context.Context, content string, quoted *nostr.Event, splits []zap.Split) (*nostr.Event, error) {
+	nevent, err := nip19.EncodeEvent(quoted.ID, []string{}, quoted.PubKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode quoted event: %w", err)
+	}
+
+	evt := &nostr.Event{
+		Kind:      nostr.KindTextNote,
+		Content:   content + "\nnostr:" + nevent,
+		CreatedAt: nostr.Now(),
+		Tags: nostr.Tags{
+			nostr.Tag{"e", quoted.ID, "", "mention"},
+			nostr.Tag{"p", quoted.PubKey},
+		},
+	}
+	evt.Tags = append(evt.Tags, zap.SplitTags(splits)...)
+
+	if err := c.Publish(ctx, evt); err != nil {
+		return nil, err
+	}
+	return evt, nil
+}
+
+// Follow adds pubkey to our contact list and publishes it, keeping the
+// rest of the newest list as it was. It returns who we follow afterwards.
+// A contact list replaces the previous one, so if no relay could say
+// whether we have one, nothing is published rather than risk wiping it.
+func (c *Client) Follow(ctx context.Context, pubkey string) ([]string, error) {
+	contacts, confirmed, err := c.fetchContactList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch contact list: %w", err)
+	}
+	if contacts == nil && !confirmed {
+		return nil, ErrContactListUnavailable
+	}
+
+	evt := &nostr.Event{
+		Kind:      nostr.KindFollowList,
+		CreatedAt: nostr.Now(),
+		Tags:      nostr.Tags{},
+	}
+	if contacts != nil {
+		// Relays some clients keep in the content stay as they are
+		evt.Content = contacts.Content
+		evt.Tags = append(evt.Tags, contacts.Tags...)
+	}
+
+	var following []string
+	for _, tag := range evt.Tags {
+		if len(tag) >= 2 && tag[0] == "p" {
+			following = append(following, tag[1])
+		}
+	}
+	if slices.Contains(following, pubkey) {
+		return following, nil
+	}
+	evt.Tags = append(evt.Tags, nostr.Tag{"p", pubkey})
+
+	if err := c.Publish(ctx, evt); err != nil {
+		return nil, err
+	}
+	return append(following, pubkey), nil
+}
+
+// Repost publishes a kind 6 repost of original
+func (c *Client) Repost(ctx context.Context, original *nostr.Event) (*nostr.Event, error) {
+	evt := &nostr.Event{
+		Kind:      nostr.KindRepost,
+		Content:   "",
+		CreatedAt: nostr.Now(),
+		Tags: nostr.Tags{
+			nostr.Tag{"e", original.ID},
+			nostr.Tag{"p", original.PubKey},
+		},
+	}
+
+	if err := c.Publish(ctx, evt); err != nil {
+		return nil, err
+	}
+	return evt, nil
+}
+
+// SendDM sends content to recipient. With a secret key it's a NIP-17
+// gift-wrapped DM, with a copy wrapped to ourselves so it shows in our DM
+// list, and the recipient's gift wrap is returned. Pleb Signer can't do
+// NIP-44 yet, so through the signer it's a NIP-04 DM (kind 4).
+func (c *Client) SendDM(ctx context.Context, recipient, content string) (*nostr.Event, error) {
+	if c.SecretKey != "" {
+		return c.sendGiftWrappedDM(ctx, recipient, content)
+	}
+	if c.Signer == nil {
+		return nil, fmt.Errorf("no key or signer to encrypt with")
+	}
+
+	encrypted, err := c.Signer.Nip04Encrypt(recipient, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt DM: %w", err)
+	}
+
+	evt := &nostr.Event{
+		Kind:      nostr.KindEncryptedDirectMessage,
+		Content:   encrypted,
+		CreatedAt: nostr.Now(),
+		Tags:      nostr.Tags{nostr.Tag{"p", recipient}},
+	}
+	if err := c.Publish(ctx, evt); err != nil {
+		return nil, err
+	}
+	return evt, nil
+}
+
+// sendGiftWrappedDM sends a NIP-17 DM: a kind 14 rumor, sealed and gift
+// wrapped once for the recipient and once for ourselves
+func (c *Client) sendGiftWrappedDM(ctx context.Context, recipient, content string) (*nostr.Event, error) {
+	rumor := nostr.Event{
+		Kind:      nostr.KindDirectMessage,
+		Content:   content,
+		CreatedAt: nostr.Now(),
+		Tags:      nostr.Tags{nostr.Tag{"p", recipient}},
+		PubKey:    c.PubKey,
+	}
+	rumor.ID = rumor.GetID()
+
+	wrap := func(to string) (nostr.Event, error) {
+		return nip59.GiftWrap(
+			rumor,
+			to,
+			func(plaintext string) (string, error) {
+				conversationKey, err := nip44.GenerateConversationKey(to, c.SecretKey)
+				if err != nil {
+					return "", err
+				}
+				return nip44.Encrypt(plaintext, conversationKey)
+			},
+			func(evt *nostr.Event) error { return evt.Sign(c.SecretKey) },
+			nil,
+		)
+	}
+
+	toRecipient, err := wrap(recipient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gift wrap for recipient: %w", err)
+	}
+	toUs, err := wrap(c.PubKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gift wrap for self: %w", err)
+	}
+
+	if err := c.publish(ctx, toRecipient, toUs); err != nil {
+		return nil, err
+	}
+	return &toRecipient, nil
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"noscli/pkg/nwc"
+	"noscli/pkg/zap"
+)
+
+// profileTimeout bounds the profile lookup when resolving a zap recipient
+const profileTimeout = 5 * time.Second
+
+// ZapTarget is a zap recipient with their LNURL-pay endpoint resolved
+type ZapTarget struct {
+	Pubkey  string
+	Address string             // lud16 address or lud06 LNURL from their profile
+	Info    *zap.LNURLResponse // Callback, min/max and comment limits
+}
+
+// ZapOptions describe a zap to one recipient
+type ZapOptions struct {
+	EventID    string // Note ID or addressable event coordinate; empty for profile zaps
+	AmountSats int64
+	Comment    string
+	Anon       bool // Sign with a throwaway key instead of ours
+}
+
+// ZapResult is a paid zap
+type ZapResult struct {
+	Invoice   string
+	Preimage  string
+	FeesMsats int64 // Routing fees, if the wallet reported them
+}
+
+// Payer pays lightning invoices. *nwc.NWCClient is one.
+type Payer interface {
+	PayInvoice(ctx context.Context, invoice string) (*nwc.PayInvoiceResult, error)
+}
+
+// PayerFunc lets an ordinary function be a Payer
+type PayerFunc func(ctx context.Context, invoice string) (*nwc.PayInvoiceResult, error)
+
+// PayInvoice calls f(ctx, invoice)
+func (f PayerFunc) PayInvoice(ctx context.Context, invoice string) (*nwc.PayInvoiceResult, error) {
+	return f(ctx, invoice)
+}
+
+// ResolveZapTarget fetches pubkey's newest profile and the LNURL-pay info
+// of the lightning address in it
+func (c *Client) ResolveZapTarget(ctx context.Context, pubkey string) (*ZapTarget, error) {
+	log.Printf("🔍 Fetching profile of %s for lightning address...", pubkey[:8])
+	profileCtx, cancel := context.WithTimeout(ctx, profileTimeout)
+	defer cancel()
+
+	profiles, err := c.FetchProfiles(profileCtx, []string{pubkey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recipient profile: %w", err)
+	}
+	profile := profiles[pubkey]
+	if profile == nil {
+		return nil, fmt.Errorf("recipient has no lightning address in profile")
+	}
+	return c.ResolveProfileZapTarget(ctx, profile)
+}
+
+// ResolveProfileZapTarget fetches the LNURL-pay info of the lightning
+// address in an already known profile
+func (c *Client) ResolveProfileZapTarget(ctx context.Context, profile *Profile) (*ZapTarget, error) {
+	address := profile.LightningAddress()
+	if address == "" {
+		return nil, fmt.Errorf("recipient has no lightning address in profile")
+	}
+	log.Printf("✅ Found lightning address: %s", address)
+
+	endpoint, err := zap.ResolvePayEndpoint(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lightning address: %w", err)
+	}
+
+	info, err := c.LNURL.FetchLNURLPayInfo(ctx, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch LNURL info: %w", err)
+	}
+	log.Printf("✅ LNURL callback: %s", info.Callback)
+
+	return &ZapTarget{Pubkey: profile.PubKey, Address: address, Info: info}, nil
+}
+
+// Zap zaps target: it signs a zap request (kind 9734), gets an invoice for
+// it from the recipient's LNURL server and pays the invoice with payer
+func (c *Client) Zap(ctx context.Context, target *ZapTarget, opts ZapOptions, payer Payer) (*ZapResult, error) {
+	recipient := target.Pubkey
+
+	log.Printf("📝 Creating zap request for %s (anon: %v)...", recipient[:8], opts.Anon)
+	var zapRequest *nostr.Event
+	var err error
+	switch {
+	case opts.Anon:
+		zapRequest, err = zap.CreateAnonZapRequest(recipient, opts.EventID, opts.Comment, opts.AmountSats, c.Relays)
+	case c.SecretKey != "":
+		zapRequest, err = zap.CreateZapRequest(recipient, opts.EventID, opts.Comment, opts.AmountSats, c.Relays, c.SecretKey)
+	default:
+		zapRequest = zap.NewZapRequest(c.PubKey, recipient, opts.EventID, opts.Comment, opts.AmountSats, c.Relays)
+		if err := c.Sign(zapRequest); err != nil {
+			log.Printf("❌ Failed to sign zap request: %v", err)
+			return nil, fmt.Errorf("failed to sign zap request: %w", err)
+		}
+	}
+	if err != nil {
+		log.Printf("❌ Failed to create zap request: %v", err)
+		return nil, fmt.Errorf("failed to create zap request: %w", err)
+	}
+	log.Printf("✅ Zap request created and signed")
+
+	log.Printf("💰 Requesting invoice...")
+	invoice, err := c.LNURL.GetInvoice(ctx, target.Info.Callback, opts.AmountSats, zapRequest)
+	if err != nil {
+		log.Printf("❌ Failed to get invoice: %v", err)
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	log.Printf("✅ Invoice received: %s...", invoice[:min(20, len(invoice))])
+
+	result, err := payer.PayInvoice(ctx, invoice)
+	if err != nil {
+		return nil, err
+	}
+	return &ZapResult{Invoice: invoice, Preimage: result.Preimage, FeesMsats: result.FeesPaid}, nil
+}
@@ -12,7 +12,69 @@ type Config struct {
 AuthMethod string   `json:"auth_method"` // "pleb_signer" or "nsec"
 Nsec       string   `json:"nsec"`        // Encrypted or plaintext nsec (user's choice to save)
 Relays     []string `json:"relays"`
-NWC        string   `json:"nwc"` // Nostr Wallet Connect string
+NWC        string   `json:"nwc,omitempty"` // Legacy single Nostr Wallet Connect string, migrated into Wallets on load
+Wallets    []Wallet `json:"wallets,omitempty"`
+DefaultWallet string `json:"default_wallet,omitempty"` // Name of the wallet used unless another is picked
+WalletNotifications bool `json:"wallet_notifications"` // Show NWC payment notifications in the Notifications view
+ZapConfirmAbove int64 `json:"zap_confirm_above"` // Zaps above this many sats need confirming in a dialog; 0 confirms every zap
+FiatCurrency string `json:"fiat_currency,omitempty"` // Currency code for fiat estimates, e.g. "USD"; empty disables them
+FiatRateURL  string `json:"fiat_rate_url,omitempty"` // Rate source returning {"USD": <price of 1 BTC>, ...}; empty uses mempool.space
+Columns []Column `json:"columns,omitempty"` // Timeline columns in order; empty uses DefaultColumns
+Keys map[string][]string `json:"keys,omitempty"` // Action name -> keys, replacing that action's default keys
+}
+
+// Column kinds
+const (
+ColumnFollowing     = "following"
+ColumnDMs           = "dms"
+ColumnNotifications = "notifications"
+ColumnFeed          = "feed"
+)
+
+// Column is one timeline column: a tab on narrow terminals, side by side with
+// the others on wide ones
+type Column struct {
+Kind     string   `json:"kind"`               // One of the Column* kinds
+Title    string   `json:"title,omitempty"`    // Shown in the tab; defaults to the kind's name
+Authors  []string `json:"authors,omitempty"`  // Hex pubkeys a feed shows notes by; empty for everyone
+Hashtags []string `json:"hashtags,omitempty"` // Hashtags a feed shows notes with, without the #
+Search   string   `json:"search,omitempty"`   // NIP-50 search a feed shows the results of, instead of authors and hashtags
+}
+
+// DefaultColumns is the layout used until columns are configured
+func DefaultColumns() []Column {
+return []Column{{Kind: ColumnFollowing}, {Kind: ColumnDMs}, {Kind: ColumnNotifications}}
+}
+
+// Wallet is a named Nostr Wallet Connect connection with optional client-side spending limits.
+// All amounts are in sats; zero means no limit.
+type Wallet struct {
+Name          string `json:"name"`
+NWC           string `json:"nwc"`
+MaxPerZap     int64  `json:"max_per_zap,omitempty"`
+DailyBudget   int64  `json:"daily_budget,omitempty"`
+MonthlyBudget int64  `json:"monthly_budget,omitempty"`
+}
+
+// GetWallet returns the wallet with the given name, or nil
+func (c *Config) GetWallet(name string) *Wallet {
+for i := range c.Wallets {
+if c.Wallets[i].Name == name {
+return &c.Wallets[i]
+}
+}
+return nil
+}
+
+// GetDefaultWallet returns the default wallet, falling back to the first one
+func (c *Config) GetDefaultWallet() *Wallet {
+if w := c.GetWallet(c.DefaultWallet); w != nil {
+return w
+}
+if len(c.Wallets) > 0 {
+return &c.Wallets[0]
+}
+return nil
 }
 
 // GetConfigPath returns the path to the config file
@@ -37,6 +99,15 @@ return "", fmt.Errorf("failed to create config directory: %w", err)
 return filepath.Join(noscliDir, "config.json"), nil
 }
 
+// GetProfilesPath returns the path to the profile cache, next to the config file
+func GetProfilesPath() (string, error) {
+configPath, err := GetConfigPath()
+if err != nil {
+return "", err
+}
+return filepath.Join(filepath.Dir(configPath), "profiles.json"), nil
+}
+
 // Load reads the config file
 func Load() (*Config, error) {
 path, err := GetConfigPath()
@@ -66,6 +137,15 @@ if len(cfg.Relays) == 0 {
 cfg.Relays = []string{"wss://relay.damus.io", "wss://relay.nostr.band"}
 }
 
+// Migrate the old single NWC string into the wallet list
+if cfg.NWC != "" {
+if len(cfg.Wallets) == 0 {
+cfg.Wallets = []Wallet{{Name: "Default", NWC: cfg.NWC}}
+cfg.DefaultWallet = "Default"
+}
+cfg.NWC = ""
+}
+
 return &cfg, nil
 }
 
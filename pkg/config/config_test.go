@@ -0,0 +1,109 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeConfig writes a raw config file where Load looks for it
+func writeConfig(t *testing.T, data string) {
+	t.Helper()
+	path, err := GetConfigPath()
+	if err != nil {
+		t.Fatalf("GetConfigPath: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Relays) == 0 || cfg.AuthMethod != "" || len(cfg.Wallets) != 0 {
+		t.Errorf("no config file: got %+v", cfg)
+	}
+	if path, _ := GetConfigPath(); path != filepath.Join(dir, "noscli", "config.json") {
+		t.Errorf("config path = %s", path)
+	}
+
+	// A saved config without relays gets the defaults too
+	writeConfig(t, `{"auth_method":"nsec","relays":[]}`)
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Relays) == 0 || cfg.AuthMethod != "nsec" {
+		t.Errorf("empty relays: got %+v", cfg)
+	}
+}
+
+func TestLoadMigratesLegacyWallet(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// The single NWC string becomes the default wallet
+	writeConfig(t, `{"auth_method":"nsec","relays":["wss://relay.example.com"],"nwc":"nostr+walletconnect://legacy"}`)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Wallets) != 1 || cfg.Wallets[0].Name != "Default" || cfg.Wallets[0].NWC != "nostr+walletconnect://legacy" {
+		t.Fatalf("wallets = %+v", cfg.Wallets)
+	}
+	if cfg.NWC != "" || cfg.DefaultWallet != "Default" {
+		t.Errorf("NWC = %q, default = %q", cfg.NWC, cfg.DefaultWallet)
+	}
+	if w := cfg.GetDefaultWallet(); w == nil || w.NWC != "nostr+walletconnect://legacy" {
+		t.Errorf("default wallet = %+v", w)
+	}
+
+	// Saving writes the migrated form, which loads the same
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	path, _ := GetConfigPath()
+	data, _ := os.ReadFile(path)
+	var saved map[string]json.RawMessage
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("saved config: %v", err)
+	}
+	if _, ok := saved["nwc"]; ok {
+		t.Errorf("saved config still has the legacy field:\n%s", data)
+	}
+	again, err := Load()
+	if err != nil || len(again.Wallets) != 1 || again.DefaultWallet != "Default" {
+		t.Errorf("reloaded %+v, %v", again, err)
+	}
+
+	// A leftover legacy string doesn't replace wallets that already exist
+	writeConfig(t, `{"nwc":"nostr+walletconnect://legacy","wallets":[{"name":"Alby","nwc":"nostr+walletconnect://alby"}],"default_wallet":"Alby"}`)
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Wallets) != 1 || cfg.Wallets[0].Name != "Alby" || cfg.DefaultWallet != "Alby" || cfg.NWC != "" {
+		t.Errorf("existing wallets: got %+v", cfg)
+	}
+}
+
+func TestGetDefaultWallet(t *testing.T) {
+	cfg := &Config{Wallets: []Wallet{{Name: "a"}, {Name: "b"}}, DefaultWallet: "b"}
+	if w := cfg.GetDefaultWallet(); w == nil || w.Name != "b" {
+		t.Errorf("default = %+v, want b", w)
+	}
+	// A default that's gone falls back to the first wallet
+	cfg.DefaultWallet = "c"
+	if w := cfg.GetDefaultWallet(); w == nil || w.Name != "a" {
+		t.Errorf("missing default = %+v, want a", w)
+	}
+	if w := (&Config{}).GetDefaultWallet(); w != nil {
+		t.Errorf("no wallets = %+v", w)
+	}
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SpendEntry is a single zap paid from a wallet
+type SpendEntry struct {
+	Wallet     string `json:"wallet"`
+	AmountSats int64  `json:"amount_sats"`
+	Timestamp  int64  `json:"timestamp"`
+}
+
+// SpendingLedger tracks zaps per wallet so budgets survive restarts
+type SpendingLedger struct {
+	Entries []SpendEntry `json:"entries"`
+}
+
+// GetSpendingPath returns the path to the spending ledger file
+func GetSpendingPath() (string, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "spending.json"), nil
+}
+
+// LoadSpending reads the spending ledger, returning an empty one if none exists
+func LoadSpending() (*SpendingLedger, error) {
+	path, err := GetSpendingPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &SpendingLedger{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spending ledger: %w", err)
+	}
+
+	var ledger SpendingLedger
+	if err := json.Unmarshal(data, &ledger); err != nil {
+		return nil, fmt.Errorf("failed to parse spending ledger: %w", err)
+	}
+
+	return &ledger, nil
+}
+
+// SaveSpending writes the spending ledger, dropping entries older than any budget window
+func SaveSpending(ledger *SpendingLedger) error {
+	path, err := GetSpendingPath()
+	if err != nil {
+		return err
+	}
+
+	ledger.Prune(StartOfMonth(time.Now()))
+
+	data, err := json.MarshalIndent(ledger, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal spending ledger: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write spending ledger: %w", err)
+	}
+
+	return nil
+}
+
+// Record adds a payment to the ledger
+func (l *SpendingLedger) Record(wallet string, amountSats int64, at time.Time) {
+	l.Entries = append(l.Entries, SpendEntry{
+		Wallet:     wallet,
+		AmountSats: amountSats,
+		Timestamp:  at.Unix(),
+	})
+}
+
+// SpentSince returns how many sats were paid from a wallet since the given time
+func (l *SpendingLedger) SpentSince(wallet string, since time.Time) int64 {
+	var total int64
+	for _, entry := range l.Entries {
+		if entry.Wallet == wallet && entry.Timestamp >= since.Unix() {
+			total += entry.AmountSats
+		}
+	}
+	return total
+}
+
+// Prune drops entries older than the given time
+func (l *SpendingLedger) Prune(before time.Time) {
+	kept := l.Entries[:0]
+	for _, entry := range l.Entries {
+		if entry.Timestamp >= before.Unix() {
+			kept = append(kept, entry)
+		}
+	}
+	l.Entries = kept
+}
+
+// CheckBudget returns a description of every limit a payment of amountSats
+// from the wallet would exceed. An empty result means the payment is within budget.
+func CheckBudget(w Wallet, ledger *SpendingLedger, amountSats int64, now time.Time) []string {
+	var exceeded []string
+
+	if w.MaxPerZap > 0 && amountSats > w.MaxPerZap {
+		exceeded = append(exceeded, fmt.Sprintf("per-zap max of %d sats", w.MaxPerZap))
+	}
+
+	if w.DailyBudget > 0 {
+		spent := ledger.SpentSince(w.Name, StartOfDay(now))
+		if spent+amountSats > w.DailyBudget {
+			exceeded = append(exceeded, fmt.Sprintf("daily budget (%d/%d sats spent)", spent, w.DailyBudget))
+		}
+	}
+
+	if w.MonthlyBudget > 0 {
+		spent := ledger.SpentSince(w.Name, StartOfMonth(now))
+		if spent+amountSats > w.MonthlyBudget {
+			exceeded = append(exceeded, fmt.Sprintf("monthly budget (%d/%d sats spent)", spent, w.MonthlyBudget))
+		}
+	}
+
+	return exceeded
+}
+
+// StartOfDay returns midnight of t's day, where daily budgets reset
+func StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// StartOfMonth returns midnight of the first of t's month, where monthly
+// budgets reset
+func StartOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
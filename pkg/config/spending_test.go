@@ -0,0 +1,91 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartOfPeriods(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	now := time.Date(2025, 6, 17, 0, 30, 0, 0, loc)
+	if got, want := StartOfDay(now), time.Date(2025, 6, 17, 0, 0, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("StartOfDay = %v, want %v", got, want)
+	}
+	if got, want := StartOfMonth(now), time.Date(2025, 6, 1, 0, 0, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("StartOfMonth = %v, want %v", got, want)
+	}
+	// Days start at local midnight, not UTC's
+	if got := StartOfDay(now); got.UTC().Day() != 16 {
+		t.Errorf("StartOfDay in UTC = %v, want the 16th", got.UTC())
+	}
+}
+
+func TestCheckBudget(t *testing.T) {
+	now := time.Date(2025, 6, 17, 12, 0, 0, 0, time.UTC)
+	ledger := &SpendingLedger{}
+	ledger.Record("main", 400, now.Add(-time.Hour))                            // Today
+	ledger.Record("main", 500, now.Add(-24*time.Hour))                         // Yesterday, this month
+	ledger.Record("main", 9000, time.Date(2025, 5, 31, 23, 0, 0, 0, time.UTC)) // Last month
+	ledger.Record("other", 5000, now.Add(-time.Hour))                          // Another wallet
+
+	if got := ledger.SpentSince("main", StartOfDay(now)); got != 400 {
+		t.Errorf("spent today = %d, want 400", got)
+	}
+	if got := ledger.SpentSince("main", StartOfMonth(now)); got != 900 {
+		t.Errorf("spent this month = %d, want 900", got)
+	}
+
+	w := Wallet{Name: "main", MaxPerZap: 1000, DailyBudget: 1000, MonthlyBudget: 1600}
+	tests := []struct {
+		amount int64
+		want   []string
+	}{
+		{100, nil},
+		{600, nil}, // Exactly the rest of today's budget
+		{601, []string{"daily budget (400/1000"}}, // One sat over today's
+		{1001, []string{"per-zap max", "daily", "monthly budget (900/1600"}},
+	}
+	for _, tt := range tests {
+		exceeded := CheckBudget(w, ledger, tt.amount, now)
+		if len(exceeded) != len(tt.want) {
+			t.Errorf("%d sats: exceeded %q, want %d limits", tt.amount, exceeded, len(tt.want))
+			continue
+		}
+		for i, want := range tt.want {
+			if !strings.HasPrefix(exceeded[i], want) {
+				t.Errorf("%d sats: limit %d is %q, want %q...", tt.amount, i, exceeded[i], want)
+			}
+		}
+	}
+
+	// Zero means no limit
+	if exceeded := CheckBudget(Wallet{Name: "main"}, ledger, 1_000_000, now); len(exceeded) != 0 {
+		t.Errorf("unlimited wallet exceeded %q", exceeded)
+	}
+}
+
+func TestSpendingSaveLoad(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// No file yet is an empty ledger
+	ledger, err := LoadSpending()
+	if err != nil || len(ledger.Entries) != 0 {
+		t.Fatalf("LoadSpending = %+v, %v", ledger, err)
+	}
+
+	// Saving drops what no budget looks at any more
+	now := time.Now()
+	ledger.Record("main", 21, now)
+	ledger.Record("main", 1000, StartOfMonth(now).Add(-time.Second))
+	if err := SaveSpending(ledger); err != nil {
+		t.Fatalf("SaveSpending: %v", err)
+	}
+	loaded, err := LoadSpending()
+	if err != nil {
+		t.Fatalf("LoadSpending: %v", err)
+	}
+	if len(loaded.Entries) != 1 || loaded.Entries[0].AmountSats != 21 {
+		t.Errorf("loaded %+v, want only this month's entry", loaded.Entries)
+	}
+}
@@ -0,0 +1,75 @@
+package fiat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+)
+
+// DefaultRateURL returns the BTC price keyed by currency code, e.g. {"USD": 97000}
+const DefaultRateURL = "https://mempool.space/api/v1/prices"
+
+// maxResponseSize caps the rate source's response; a price list is tiny
+const maxResponseSize = 64 << 10
+
+// FetchRate fetches the price of one bitcoin in currency from rateURL, which
+// must return a JSON object mapping currency codes to prices
+func FetchRate(ctx context.Context, client *http.Client, rateURL, currency string) (float64, error) {
+	if rateURL == "" {
+		rateURL = DefaultRateURL
+	}
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return 0, fmt.Errorf("no currency configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rateURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch rate: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return 0, fmt.Errorf("rate source returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read rate: %w", err)
+	}
+
+	var prices map[string]json.RawMessage
+	if err := json.Unmarshal(body, &prices); err != nil {
+		return 0, fmt.Errorf("failed to parse rate: %w", err)
+	}
+
+	raw, ok := prices[currency]
+	if !ok {
+		return 0, fmt.Errorf("rate source has no %s price", currency)
+	}
+	var rate float64
+	if err := json.Unmarshal(raw, &rate); err != nil || rate <= 0 || math.IsInf(rate, 0) {
+		return 0, fmt.Errorf("invalid %s price: %s", currency, raw)
+	}
+
+	return rate, nil
+}
+
+// Estimate formats what sats are worth at rate (price of one bitcoin), e.g. "≈ 0.21 USD"
+func Estimate(sats int64, rate float64, currency string) string {
+	value := float64(sats) * rate / 1e8
+	currency = strings.ToUpper(currency)
+	if value > 0 && value < 0.01 {
+		return "≈ <0.01 " + currency
+	}
+	return fmt.Sprintf("≈ %.2f %s", value, currency)
+}
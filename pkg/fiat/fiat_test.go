@@ -0,0 +1,48 @@
+package fiat
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"time":1700000000,"USD":100000,"EUR":"bad"}`))
+	}))
+	defer server.Close()
+
+	rate, err := FetchRate(context.Background(), server.Client(), server.URL, "usd")
+	if err != nil {
+		t.Fatalf("FetchRate: %v", err)
+	}
+	if rate != 100000 {
+		t.Errorf("rate = %v, want 100000", rate)
+	}
+
+	if _, err := FetchRate(context.Background(), server.Client(), server.URL, "EUR"); err == nil {
+		t.Error("expected error for a non-numeric price")
+	}
+	if _, err := FetchRate(context.Background(), server.Client(), server.URL, "JPY"); err == nil {
+		t.Error("expected error for a missing currency")
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		sats int64
+		rate float64
+		want string
+	}{
+		{21, 100000, "≈ 0.02 USD"},
+		{1000, 100000, "≈ 1.00 USD"},
+		{1, 100000, "≈ <0.01 USD"},
+		{0, 100000, "≈ 0.00 USD"},
+	}
+	for _, tt := range tests {
+		if got := Estimate(tt.sats, tt.rate, "usd"); got != tt.want {
+			t.Errorf("Estimate(%d, %v) = %q, want %q", tt.sats, tt.rate, got, tt.want)
+		}
+	}
+}
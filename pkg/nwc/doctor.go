@@ -0,0 +1,242 @@
+package nwc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// doctorTimeout bounds each step of Diagnose that talks to the wallet
+const doctorTimeout = 15 * time.Second
+
+// Check is the outcome of one diagnostic step
+type Check struct {
+	Name    string
+	Passed  bool
+	Skipped bool
+	Detail  string
+}
+
+// Report is the outcome of diagnosing a wallet connection
+type Report struct {
+	WalletPubkey string
+	ClientPubkey string
+	Relay        string
+	Info         *Info // Nil if the wallet publishes no info event
+	Checks       []Check
+}
+
+// Passed reports whether no check failed
+func (r *Report) Passed() bool {
+	for _, c := range r.Checks {
+		if !c.Passed && !c.Skipped {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the report as plain text for pasting into bug reports
+func (r *Report) String() string {
+	var b strings.Builder
+	b.WriteString("NWC wallet doctor\n")
+	fmt.Fprintf(&b, "  Wallet pubkey: %s\n", orUnknown(r.WalletPubkey))
+	fmt.Fprintf(&b, "  Client pubkey: %s\n", orUnknown(r.ClientPubkey))
+	fmt.Fprintf(&b, "  Relay:         %s\n", orUnknown(r.Relay))
+	if r.Info != nil {
+		fmt.Fprintf(&b, "  Methods:       %s\n", strings.Join(r.Info.Methods, " "))
+		encryption := strings.Join(r.Info.Encryption, " ")
+		if encryption == "" {
+			encryption = "nip04 (no encryption tag)"
+		}
+		fmt.Fprintf(&b, "  Encryption:    %s\n", encryption)
+		if len(r.Info.Notifications) > 0 {
+			fmt.Fprintf(&b, "  Notifications: %s\n", strings.Join(r.Info.Notifications, " "))
+		}
+	}
+	b.WriteString("\n")
+
+	passed, failed := 0, 0
+	for _, c := range r.Checks {
+		status := "PASS"
+		switch {
+		case c.Skipped:
+			status = "SKIP"
+		case c.Passed:
+			passed++
+		default:
+			status = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(&b, "[%s] %s", status, c.Name)
+		if c.Detail != "" {
+			b.WriteString(": " + c.Detail)
+		}
+		b.WriteString("\n")
+	}
+
+	result := "PASS"
+	if failed > 0 {
+		result = "FAIL"
+	}
+	fmt.Fprintf(&b, "\nResult: %s (%d passed, %d failed)\n", result, passed, failed)
+	return b.String()
+}
+
+// add records a check
+func (r *Report) add(name string, passed bool, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, Check{Name: name, Passed: passed, Detail: fmt.Sprintf(format, args...)})
+}
+
+// skip records a check that couldn't run
+func (r *Report) skip(name, reason string) {
+	r.Checks = append(r.Checks, Check{Name: name, Skipped: true, Detail: reason})
+}
+
+// Diagnose checks a wallet connection end to end: the URI, the relay, the
+// wallet's info event, and get_info/get_balance round trips. It never pays
+// anything.
+func Diagnose(ctx context.Context, uri string) *Report {
+	report := &Report{}
+
+	walletPubkey, relay, secret, err := ParseNWCString(uri)
+	if err == nil {
+		report.WalletPubkey, report.Relay = walletPubkey, relay
+		report.ClientPubkey, err = ClientPubkey(secret)
+	}
+	if err == nil && !nostr.IsValidPublicKey(walletPubkey) {
+		err = fmt.Errorf("invalid wallet pubkey: %s", walletPubkey)
+	}
+	if err != nil {
+		report.add("Parse connection URI", false, "%v", err)
+		return report
+	}
+	report.add("Parse connection URI", true, "")
+
+	connectCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	start := time.Now()
+	session, err := Connect(connectCtx, uri)
+	cancel()
+	if err != nil {
+		report.add("Connect to relay", false, "%v", err)
+		return report
+	}
+	defer session.Close()
+	report.add("Connect to relay", true, "%s", time.Since(start).Round(time.Millisecond))
+
+	infoCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	info, err := session.FetchInfo(infoCtx)
+	cancel()
+	switch {
+	case err != nil:
+		report.add("Info event (kind 13194)", false, "%v", err)
+	case info == nil:
+		report.add("Info event (kind 13194)", false, "wallet publishes no info event on this relay")
+	default:
+		report.Info = info
+		report.add("Info event (kind 13194)", true, "%d methods", len(info.Methods))
+		report.add("Supports pay_invoice", info.Supports("pay_invoice"), "needed for zaps and payments")
+		report.add("Supports NIP-04 encryption", info.SupportsEncryption(EncryptionNIP04), "noscli encrypts payment requests with NIP-04")
+	}
+
+	// Prefer NIP-44 for our own requests when the wallet supports it
+	if info != nil && info.SupportsEncryption(EncryptionNIP44) {
+		session.Encryption = EncryptionNIP44
+	}
+
+	var replies []*Reply
+	for _, method := range []string{"get_info", "get_balance"} {
+		name := method + " request"
+		if info != nil && !info.Supports(method) {
+			report.skip(name, "not advertised by the wallet")
+			continue
+		}
+
+		requestCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+		reply, err := session.Request(requestCtx, method, nil)
+		cancel()
+		if err != nil {
+			report.add(name, false, "%v", err)
+			continue
+		}
+		replies = append(replies, reply)
+
+		resp := reply.Response
+		latency := reply.Latency.Round(time.Millisecond)
+		switch {
+		case resp.Error != nil:
+			report.add(name, false, "%s: %s (after %s)", resp.Error.Code, resp.Error.Message, latency)
+		case resp.ResultType != method:
+			report.add(name, false, "response has result_type %q (after %s)", resp.ResultType, latency)
+		default:
+			report.add(name, true, "%s in %s", describeResult(method, resp.Result), latency)
+		}
+	}
+
+	if len(replies) == 0 {
+		report.skip("Response tagging", "no responses received")
+		return report
+	}
+	var problems []string
+	for _, reply := range replies {
+		if !hasTag(reply.Event.Tags, "e", reply.RequestID) {
+			problems = append(problems, reply.Response.ResultType+" response has no 'e' tag for the request")
+		}
+		if !hasTag(reply.Event.Tags, "p", report.ClientPubkey) {
+			problems = append(problems, reply.Response.ResultType+" response has no 'p' tag for the client")
+		}
+	}
+	if len(problems) > 0 {
+		report.add("Response tagging", false, "%s", strings.Join(problems, "; "))
+	} else {
+		report.add("Response tagging", true, "responses carry 'e' and 'p' tags")
+	}
+
+	return report
+}
+
+// describeResult summarizes a successful get_info or get_balance result
+func describeResult(method string, result json.RawMessage) string {
+	switch method {
+	case "get_balance":
+		var balance struct {
+			Balance int64 `json:"balance"` // msats
+		}
+		if err := json.Unmarshal(result, &balance); err != nil {
+			return "unreadable balance"
+		}
+		return fmt.Sprintf("balance %d sats", balance.Balance/1000)
+	case "get_info":
+		var info struct {
+			Alias   string `json:"alias"`
+			Network string `json:"network"`
+		}
+		if err := json.Unmarshal(result, &info); err != nil {
+			return "unreadable info"
+		}
+		var parts []string
+		if info.Alias != "" {
+			parts = append(parts, info.Alias)
+		}
+		if info.Network != "" {
+			parts = append(parts, info.Network)
+		}
+		if len(parts) == 0 {
+			return "ok"
+		}
+		return strings.Join(parts, ", ")
+	}
+	return "ok"
+}
+
+// orUnknown returns s, or "unknown" if it's empty
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
@@ -0,0 +1,124 @@
+package nwc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip04"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/nbd-wtf/go-nostr/nip44"
+)
+
+// NIP-47 notification event kinds
+const (
+	KindNotificationNIP04 = 23196 // NIP-04 encrypted notification
+	KindNotificationNIP44 = 23197 // NIP-44 encrypted notification
+)
+
+// Notification types pushed by the wallet service
+const (
+	NotificationPaymentReceived = "payment_received"
+	NotificationPaymentSent     = "payment_sent"
+)
+
+// Transaction describes a payment as reported by the wallet service
+type Transaction struct {
+	Type            string                 `json:"type"` // "incoming" or "outgoing"
+	Invoice         string                 `json:"invoice,omitempty"`
+	Description     string                 `json:"description,omitempty"`
+	DescriptionHash string                 `json:"description_hash,omitempty"`
+	Preimage        string                 `json:"preimage,omitempty"`
+	PaymentHash     string                 `json:"payment_hash"`
+	Amount          int64                  `json:"amount"` // msats
+	FeesPaid        int64                  `json:"fees_paid"`
+	CreatedAt       int64                  `json:"created_at"`
+	ExpiresAt       int64                  `json:"expires_at,omitempty"`
+	SettledAt       int64                  `json:"settled_at,omitempty"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Notification is the decrypted content of a kind 23196/23197 event
+type Notification struct {
+	NotificationType string      `json:"notification_type"`
+	Notification     Transaction `json:"notification"`
+}
+
+// IsNotificationKind reports whether kind is a NIP-47 notification kind
+func IsNotificationKind(kind int) bool {
+	return kind == KindNotificationNIP04 || kind == KindNotificationNIP44
+}
+
+// SecretKeyHex returns the connection secret as hex, accepting either the
+// hex form used by NWC URIs or an nsec
+func SecretKeyHex(secret string) (string, error) {
+	if len(secret) == 64 {
+		if _, err := hex.DecodeString(secret); err == nil {
+			return secret, nil
+		}
+	}
+
+	prefix, sk, err := nip19.Decode(secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret: %w", err)
+	}
+	if prefix != "nsec" {
+		return "", fmt.Errorf("secret is not a private key (got %s)", prefix)
+	}
+
+	return sk.(string), nil
+}
+
+// ClientPubkey returns the pubkey the wallet uses to address this connection
+func ClientPubkey(secret string) (string, error) {
+	sk, err := SecretKeyHex(secret)
+	if err != nil {
+		return "", err
+	}
+	return nostr.GetPublicKey(sk)
+}
+
+// DecryptNotification decrypts and parses a wallet notification event using
+// the connection secret. Kind 23196 uses NIP-04, kind 23197 uses NIP-44.
+func DecryptNotification(evt *nostr.Event, walletPubkey, secret string) (*Notification, error) {
+	if evt.PubKey != walletPubkey {
+		return nil, fmt.Errorf("notification not from wallet %s", walletPubkey[:8])
+	}
+
+	sk, err := SecretKeyHex(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	var plaintext string
+	switch evt.Kind {
+	case KindNotificationNIP04:
+		sharedSecret, err := nip04.ComputeSharedSecret(walletPubkey, sk)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute shared secret: %w", err)
+		}
+		plaintext, err = nip04.Decrypt(evt.Content, sharedSecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt notification: %w", err)
+		}
+	case KindNotificationNIP44:
+		conversationKey, err := nip44.GenerateConversationKey(walletPubkey, sk)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate conversation key: %w", err)
+		}
+		plaintext, err = nip44.Decrypt(evt.Content, conversationKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt notification: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unexpected notification kind %d", evt.Kind)
+	}
+
+	var notification Notification
+	if err := json.Unmarshal([]byte(plaintext), &notification); err != nil {
+		return nil, fmt.Errorf("failed to parse notification: %w", err)
+	}
+
+	return &notification, nil
+}
@@ -0,0 +1,119 @@
+package nwc_test
+
+import (
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip04"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/nbd-wtf/go-nostr/nip44"
+	"noscli/pkg/nwc"
+)
+
+// notificationEvent returns a notification of kind from the wallet key to
+// the connection secret's pubkey, encrypted the way that kind requires
+func notificationEvent(t *testing.T, kind int, walletKey, secret, content string) *nostr.Event {
+	t.Helper()
+	clientPubkey, err := nwc.ClientPubkey(secret)
+	if err != nil {
+		t.Fatalf("ClientPubkey: %v", err)
+	}
+
+	var ciphertext string
+	switch kind {
+	case nwc.KindNotificationNIP04:
+		shared, err := nip04.ComputeSharedSecret(clientPubkey, walletKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ciphertext, err = nip04.Encrypt(content, shared)
+		if err != nil {
+			t.Fatal(err)
+		}
+	default:
+		key, err := nip44.GenerateConversationKey(clientPubkey, walletKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ciphertext, err = nip44.Encrypt(content, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	evt := &nostr.Event{Kind: kind, Content: ciphertext, CreatedAt: nostr.Now(), Tags: nostr.Tags{{"p", clientPubkey}}}
+	if err := evt.Sign(walletKey); err != nil {
+		t.Fatal(err)
+	}
+	return evt
+}
+
+func TestDecryptNotification(t *testing.T) {
+	walletKey := nostr.GeneratePrivateKey()
+	walletPubkey, _ := nostr.GetPublicKey(walletKey)
+	secret := nostr.GeneratePrivateKey()
+	content := `{"notification_type":"payment_received","notification":{"type":"incoming","payment_hash":"abc","amount":21000,"description":"thanks"}}`
+
+	for _, kind := range []int{nwc.KindNotificationNIP04, nwc.KindNotificationNIP44} {
+		if !nwc.IsNotificationKind(kind) {
+			t.Errorf("%d isn't a notification kind", kind)
+		}
+		notification, err := nwc.DecryptNotification(notificationEvent(t, kind, walletKey, secret, content), walletPubkey, secret)
+		if err != nil {
+			t.Fatalf("kind %d: %v", kind, err)
+		}
+		tx := notification.Notification
+		if notification.NotificationType != nwc.NotificationPaymentReceived || tx.Amount != 21000 || tx.Description != "thanks" || tx.PaymentHash != "abc" {
+			t.Errorf("kind %d: got %+v", kind, notification)
+		}
+	}
+
+	// The secret can also be an nsec
+	nsec, _ := nip19.EncodePrivateKey(secret)
+	if _, err := nwc.DecryptNotification(notificationEvent(t, nwc.KindNotificationNIP44, walletKey, secret, content), walletPubkey, nsec); err != nil {
+		t.Errorf("with an nsec: %v", err)
+	}
+}
+
+func TestDecryptNotificationRejects(t *testing.T) {
+	walletKey := nostr.GeneratePrivateKey()
+	walletPubkey, _ := nostr.GetPublicKey(walletKey)
+	secret := nostr.GeneratePrivateKey()
+	content := `{"notification_type":"payment_sent","notification":{"amount":1000}}`
+
+	// Only the wallet we're connected to can send notifications
+	impostor := nostr.GeneratePrivateKey()
+	if _, err := nwc.DecryptNotification(notificationEvent(t, nwc.KindNotificationNIP44, impostor, secret, content), walletPubkey, secret); err == nil {
+		t.Error("accepted a notification from another key")
+	}
+
+	// Encrypted for someone else
+	other := nostr.GeneratePrivateKey()
+	if _, err := nwc.DecryptNotification(notificationEvent(t, nwc.KindNotificationNIP44, walletKey, other, content), walletPubkey, secret); err == nil {
+		t.Error("decrypted a notification for another connection")
+	}
+
+	// A NIP-44 payload on the NIP-04 kind doesn't decrypt
+	evt := notificationEvent(t, nwc.KindNotificationNIP44, walletKey, secret, content)
+	evt.Kind = nwc.KindNotificationNIP04
+	if _, err := nwc.DecryptNotification(evt, walletPubkey, secret); err == nil {
+		t.Error("decrypted NIP-44 content as NIP-04")
+	}
+
+	if _, err := nwc.DecryptNotification(notificationEvent(t, nwc.KindNotificationNIP44, walletKey, secret, "not json"), walletPubkey, secret); err == nil {
+		t.Error("parsed a notification that isn't JSON")
+	}
+
+	evt = notificationEvent(t, nwc.KindNotificationNIP44, walletKey, secret, content)
+	evt.Kind = 23195
+	if nwc.IsNotificationKind(evt.Kind) {
+		t.Error("23195 is a response, not a notification")
+	}
+	if _, err := nwc.DecryptNotification(evt, walletPubkey, secret); err == nil {
+		t.Error("decrypted a response as a notification")
+	}
+
+	if _, err := nwc.DecryptNotification(notificationEvent(t, nwc.KindNotificationNIP44, walletKey, secret, content), walletPubkey, "not a secret"); err == nil {
+		t.Error("accepted a malformed secret")
+	}
+}
@@ -11,7 +11,6 @@ import (
 
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/nbd-wtf/go-nostr/nip04"
-	"github.com/nbd-wtf/go-nostr/nip19"
 )
 
 // NWCClient handles Nostr Wallet Connect (NIP-47) operations
@@ -35,9 +34,13 @@ type PayInvoiceResponse struct {
 		Code    string `json:"code"`
 		Message string `json:"message"`
 	} `json:"error,omitempty"`
-	Result *struct {
-		Preimage string `json:"preimage"`
-	} `json:"result,omitempty"`
+	Result *PayInvoiceResult `json:"result,omitempty"`
+}
+
+// PayInvoiceResult is what the wallet reports for a successful payment
+type PayInvoiceResult struct {
+	Preimage string `json:"preimage"`
+	FeesPaid int64  `json:"fees_paid,omitempty"` // Routing fees in msats, if the wallet reports them
 }
 
 // ParseNWCString parses a nostr+walletconnect:// URI
@@ -88,16 +91,14 @@ func NewNWCClient(connectionString string) (*NWCClient, error) {
 	}, nil
 }
 
-// PayInvoice pays a lightning invoice via NWC
-func (c *NWCClient) PayInvoice(ctx context.Context, invoice string) error {
-	// Decode the secret (it's an nsec)
-	_, sk, err := nip19.Decode(c.secret)
+// PayInvoice pays a lightning invoice via NWC and returns the wallet's result
+func (c *NWCClient) PayInvoice(ctx context.Context, invoice string) (*PayInvoiceResult, error) {
+	// The secret is usually hex, but some wallets hand out an nsec
+	secretKey, err := SecretKeyHex(c.secret)
 	if err != nil {
-		return fmt.Errorf("failed to decode secret: %w", err)
+		return nil, err
 	}
 
-	secretKey := sk.(string)
-
 	// Create the request
 	requestContent := PayInvoiceRequest{
 		Method: "pay_invoice",
@@ -108,24 +109,24 @@ func (c *NWCClient) PayInvoice(ctx context.Context, invoice string) error {
 
 	contentBytes, err := json.Marshal(requestContent)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	// Encrypt the content using NIP-04
 	sharedSecret, err := nip04.ComputeSharedSecret(c.walletPubkey, secretKey)
 	if err != nil {
-		return fmt.Errorf("failed to compute shared secret: %w", err)
+		return nil, fmt.Errorf("failed to compute shared secret: %w", err)
 	}
 	
 	encryptedContent, err := nip04.Encrypt(string(contentBytes), sharedSecret)
 	if err != nil {
-		return fmt.Errorf("failed to encrypt content: %w", err)
+		return nil, fmt.Errorf("failed to encrypt content: %w", err)
 	}
 
 	// Get public key from secret
 	pubkey, err := nostr.GetPublicKey(secretKey)
 	if err != nil {
-		return fmt.Errorf("failed to get public key: %w", err)
+		return nil, fmt.Errorf("failed to get public key: %w", err)
 	}
 
 	// Create event (kind 23194 for NIP-47)
@@ -141,7 +142,7 @@ func (c *NWCClient) PayInvoice(ctx context.Context, invoice string) error {
 
 	// Sign the event
 	if err := evt.Sign(secretKey); err != nil {
-		return fmt.Errorf("failed to sign event: %w", err)
+		return nil, fmt.Errorf("failed to sign event: %w", err)
 	}
 
 	// Publish to wallet relay and wait for response
@@ -174,7 +175,7 @@ func (c *NWCClient) PayInvoice(ctx context.Context, invoice string) error {
 	}
 	
 	if !published {
-		return fmt.Errorf("failed to publish request to wallet relay")
+		return nil, fmt.Errorf("failed to publish request to wallet relay")
 	}
 
 	log.Printf("⏳ Waiting for wallet response (timeout: 30s)...")
@@ -224,7 +225,7 @@ func (c *NWCClient) PayInvoice(ctx context.Context, invoice string) error {
 			decrypted, err := nip04.Decrypt(event.Content, sharedSecret)
 			if err != nil {
 				log.Printf("❌ Failed to decrypt: %v", err)
-				return fmt.Errorf("failed to decrypt response: %w", err)
+				return nil, fmt.Errorf("failed to decrypt response: %w", err)
 			}
 
 			log.Printf("📄 Decrypted response: %s", decrypted)
@@ -233,26 +234,26 @@ func (c *NWCClient) PayInvoice(ctx context.Context, invoice string) error {
 			var response PayInvoiceResponse
 			if err := json.Unmarshal([]byte(decrypted), &response); err != nil {
 				log.Printf("❌ Failed to parse JSON: %v", err)
-				return fmt.Errorf("failed to parse response: %w", err)
+				return nil, fmt.Errorf("failed to parse response: %w", err)
 			}
 
 			// Check for errors
 			if response.Error != nil {
 				log.Printf("❌ Wallet returned error: %s - %s", response.Error.Code, response.Error.Message)
-				return fmt.Errorf("wallet error: %s - %s", response.Error.Code, response.Error.Message)
+				return nil, fmt.Errorf("wallet error: %s - %s", response.Error.Code, response.Error.Message)
 			}
 
 			if response.Result == nil {
 				log.Printf("❌ No result in response")
-				return fmt.Errorf("no result in response")
+				return nil, fmt.Errorf("no result in response")
 			}
 
 			log.Printf("✅ Payment successful!")
-			return nil
+			return response.Result, nil
 
 		case <-ctx.Done():
 			log.Printf("⏱️  Timeout after receiving %d events", eventCount)
-			return fmt.Errorf("timeout waiting for wallet response (30s) - received %d events but none matched", eventCount)
+			return nil, fmt.Errorf("timeout waiting for wallet response (30s) - received %d events but none matched", eventCount)
 		}
 	}
 }
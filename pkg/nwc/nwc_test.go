@@ -0,0 +1,181 @@
+package nwc_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"noscli/internal/mockwallet"
+	"noscli/internal/testrelay"
+	"noscli/pkg/nwc"
+)
+
+// newWallet starts a relay and a wallet with 10,000 sats on it
+func newWallet(t *testing.T) *mockwallet.Wallet {
+	t.Helper()
+	relay := testrelay.New()
+	t.Cleanup(relay.Close)
+	return mockwallet.New(relay, 10_000_000)
+}
+
+func TestPayInvoice(t *testing.T) {
+	wallet := newWallet(t)
+	wallet.SetFees(3000)
+
+	client, err := nwc.NewNWCClient(wallet.URI())
+	if err != nil {
+		t.Fatalf("NewNWCClient: %v", err)
+	}
+	defer client.Close()
+
+	invoice, _ := mockwallet.Invoice(21000, "test")
+	result, err := client.PayInvoice(context.Background(), invoice)
+	if err != nil {
+		t.Fatalf("PayInvoice: %v", err)
+	}
+	if len(result.Preimage) != 64 {
+		t.Errorf("preimage = %q, want 32 hex bytes", result.Preimage)
+	}
+	if result.FeesPaid != 3000 {
+		t.Errorf("fees = %d, want 3000", result.FeesPaid)
+	}
+	if got := wallet.Balance(); got != 10_000_000-24000 {
+		t.Errorf("balance = %d, want %d", got, 10_000_000-24000)
+	}
+
+	requests := wallet.Requests()
+	if len(requests) != 1 || requests[0].Method != "pay_invoice" || requests[0].Params["invoice"] != invoice {
+		t.Errorf("wallet got %+v, want one pay_invoice for the invoice", requests)
+	}
+	if requests[0].Event.PubKey != wallet.ClientPubkey {
+		t.Errorf("request signed by %s, want the connection key %s", requests[0].Event.PubKey, wallet.ClientPubkey)
+	}
+}
+
+func TestPayInvoiceSecretFormats(t *testing.T) {
+	wallet := newWallet(t)
+	nsec, _ := nip19.EncodePrivateKey(wallet.ClientSecret)
+	withSecret := func(secret string) string {
+		return fmt.Sprintf("nostr+walletconnect://%s?relay=%s&secret=%s", wallet.Pubkey, wallet.Relay.URL, secret)
+	}
+
+	// NIP-47 secrets are hex. PayInvoice used to decode them as an nsec
+	// only, so paying with a standard connection string failed.
+	for name, uri := range map[string]string{"hex": withSecret(wallet.ClientSecret), "nsec": withSecret(nsec)} {
+		client, err := nwc.NewNWCClient(uri)
+		if err != nil {
+			t.Fatalf("%s: NewNWCClient: %v", name, err)
+		}
+		invoice, _ := mockwallet.Invoice(1000, name)
+		if _, err := client.PayInvoice(context.Background(), invoice); err != nil {
+			t.Errorf("%s secret: %v", name, err)
+		}
+		client.Close()
+	}
+
+	client, err := nwc.NewNWCClient(withSecret("not-a-secret"))
+	if err != nil {
+		t.Fatalf("NewNWCClient: %v", err)
+	}
+	defer client.Close()
+	invoice, _ := mockwallet.Invoice(1000, "bad")
+	if _, err := client.PayInvoice(context.Background(), invoice); err == nil || !strings.Contains(err.Error(), "secret") {
+		t.Errorf("malformed secret: err = %v", err)
+	}
+}
+
+func TestPayInvoiceErrors(t *testing.T) {
+	wallet := newWallet(t)
+	client, err := nwc.NewNWCClient(wallet.URI())
+	if err != nil {
+		t.Fatalf("NewNWCClient: %v", err)
+	}
+	defer client.Close()
+
+	t.Run("scripted", func(t *testing.T) {
+		wallet.Script("pay_invoice", mockwallet.Result{Code: "RATE_LIMITED", Message: "slow down"})
+		invoice, _ := mockwallet.Invoice(1000, "")
+		_, err := client.PayInvoice(context.Background(), invoice)
+		if err == nil || !strings.Contains(err.Error(), "RATE_LIMITED - slow down") {
+			t.Errorf("err = %v, want the wallet's error", err)
+		}
+	})
+
+	t.Run("insufficient balance", func(t *testing.T) {
+		invoice, _ := mockwallet.Invoice(20_000_000, "")
+		_, err := client.PayInvoice(context.Background(), invoice)
+		if err == nil || !strings.Contains(err.Error(), "INSUFFICIENT_BALANCE") {
+			t.Errorf("err = %v, want INSUFFICIENT_BALANCE", err)
+		}
+	})
+
+	t.Run("no reply", func(t *testing.T) {
+		wallet.Script("pay_invoice", mockwallet.Result{NoReply: true})
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		invoice, _ := mockwallet.Invoice(1000, "")
+		if _, err := client.PayInvoice(ctx, invoice); err == nil || !strings.Contains(err.Error(), "timeout") {
+			t.Errorf("err = %v, want a timeout", err)
+		}
+	})
+}
+
+func TestSessionRequest(t *testing.T) {
+	wallet := newWallet(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session, err := nwc.Connect(ctx, wallet.URI())
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer session.Close()
+
+	for _, encryption := range []string{nwc.EncryptionNIP04, nwc.EncryptionNIP44} {
+		session.Encryption = encryption
+		reply, err := session.Request(ctx, "make_invoice", map[string]interface{}{"amount": 5000, "description": "coffee"})
+		if err != nil {
+			t.Fatalf("%s make_invoice: %v", encryption, err)
+		}
+		if reply.Response.Error != nil || !strings.Contains(string(reply.Response.Result), `"invoice":"lnbc50n1`) {
+			t.Errorf("%s make_invoice = %+v %s, want a 50n invoice", encryption, reply.Response.Error, reply.Response.Result)
+		}
+	}
+
+	reply, err := session.Request(ctx, "pay_keysend", nil)
+	if err != nil {
+		t.Fatalf("pay_keysend: %v", err)
+	}
+	if reply.Response.Error == nil || reply.Response.Error.Code != "NOT_IMPLEMENTED" {
+		t.Errorf("pay_keysend error = %+v, want NOT_IMPLEMENTED", reply.Response.Error)
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	wallet := newWallet(t)
+	ctx := context.Background()
+
+	report := nwc.Diagnose(ctx, wallet.URI())
+	if !report.Passed() {
+		t.Fatalf("healthy wallet failed:\n%s", report)
+	}
+	if report.Info == nil || !report.Info.SupportsEncryption(nwc.EncryptionNIP44) {
+		t.Errorf("info = %+v, want NIP-44 support", report.Info)
+	}
+	if !strings.Contains(report.String(), "[PASS] get_balance request: balance 10000 sats") {
+		t.Errorf("report doesn't show the balance:\n%s", report)
+	}
+
+	wallet.SetUntagged(true)
+	report = nwc.Diagnose(ctx, wallet.URI())
+	if report.Passed() || !strings.Contains(report.String(), "[FAIL] Response tagging") {
+		t.Errorf("untagged responses weren't flagged:\n%s", report)
+	}
+
+	if report := nwc.Diagnose(ctx, "nostr+walletconnect://nope?relay=wss://x&secret=y"); report.Passed() {
+		t.Errorf("bad URI passed:\n%s", report)
+	}
+}
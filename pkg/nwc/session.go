@@ -0,0 +1,304 @@
+package nwc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip04"
+	"github.com/nbd-wtf/go-nostr/nip44"
+)
+
+// NIP-47 event kinds
+const (
+	KindInfo     = 13194
+	KindRequest  = 23194
+	KindResponse = 23195
+)
+
+// NIP-47 encryption schemes
+const (
+	EncryptionNIP04 = "nip04"
+	EncryptionNIP44 = "nip44_v2"
+)
+
+// Info is what a wallet service advertises in its kind 13194 info event
+type Info struct {
+	Methods       []string
+	Encryption    []string // Empty if the wallet predates the encryption tag (NIP-04 only)
+	Notifications []string
+}
+
+// ParseInfo reads a wallet service's info event
+func ParseInfo(evt *nostr.Event) *Info {
+	info := &Info{Methods: strings.Fields(evt.Content)}
+	for _, tag := range evt.Tags {
+		if len(tag) < 2 {
+			continue
+		}
+		switch tag[0] {
+		case "encryption":
+			info.Encryption = strings.Fields(tag[1])
+		case "notifications":
+			info.Notifications = strings.Fields(tag[1])
+		}
+	}
+	return info
+}
+
+// Supports reports whether the wallet advertises a method
+func (i *Info) Supports(method string) bool {
+	for _, m := range i.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsEncryption reports whether the wallet accepts requests encrypted
+// with scheme. Wallets without an encryption tag only speak NIP-04.
+func (i *Info) SupportsEncryption(scheme string) bool {
+	if len(i.Encryption) == 0 {
+		return scheme == EncryptionNIP04
+	}
+	for _, e := range i.Encryption {
+		if e == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+// Response is the decrypted content of a kind 23195 response
+type Response struct {
+	ResultType string          `json:"result_type"`
+	Error      *ResponseError  `json:"error,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+}
+
+// ResponseError is an error returned by the wallet service
+type ResponseError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("wallet error: %s - %s", e.Code, e.Message)
+}
+
+// Reply is a wallet's response to a request sent through a Session
+type Reply struct {
+	Response  *Response
+	Event     *nostr.Event  // The kind 23195 event carrying the response
+	RequestID string        // ID of the request event
+	Latency   time.Duration // From publishing the request to receiving the response
+}
+
+// Session is a direct connection to a wallet's relay for sending NIP-47
+// requests signed with the connection secret
+type Session struct {
+	WalletPubkey string
+	ClientPubkey string
+	RelayURL     string
+	Encryption   string // Scheme used for requests, NIP-04 unless changed
+	secret       string
+	relay        *nostr.Relay
+}
+
+// Connect parses a connection URI and connects to the wallet's relay
+func Connect(ctx context.Context, uri string) (*Session, error) {
+	walletPubkey, relayURL, secret, err := ParseNWCString(uri)
+	if err != nil {
+		return nil, err
+	}
+	if !nostr.IsValidPublicKey(walletPubkey) {
+		return nil, fmt.Errorf("invalid wallet pubkey: %s", walletPubkey)
+	}
+
+	sk, err := SecretKeyHex(secret)
+	if err != nil {
+		return nil, err
+	}
+	clientPubkey, err := nostr.GetPublicKey(sk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	relay, err := nostr.RelayConnect(ctx, relayURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", relayURL, err)
+	}
+
+	return &Session{
+		WalletPubkey: walletPubkey,
+		ClientPubkey: clientPubkey,
+		RelayURL:     relayURL,
+		Encryption:   EncryptionNIP04,
+		secret:       sk,
+		relay:        relay,
+	}, nil
+}
+
+// FetchInfo fetches the wallet's info event, or returns nil if it has none
+func (s *Session) FetchInfo(ctx context.Context) (*Info, error) {
+	events, err := s.relay.QuerySync(ctx, nostr.Filter{
+		Kinds:   []int{KindInfo},
+		Authors: []string{s.WalletPubkey},
+		Limit:   1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch info event: %w", err)
+	}
+
+	var newest *nostr.Event
+	for _, evt := range events {
+		if newest == nil || evt.CreatedAt > newest.CreatedAt {
+			newest = evt
+		}
+	}
+	if newest == nil {
+		return nil, nil
+	}
+	return ParseInfo(newest), nil
+}
+
+// Request sends a request and waits for the wallet's response. Responses
+// are matched by their 'e' tag; an untagged response to the same method
+// that arrives after the request is accepted too, so callers can report it.
+func (s *Session) Request(ctx context.Context, method string, params map[string]interface{}) (*Reply, error) {
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	content, err := json.Marshal(map[string]interface{}{"method": method, "params": params})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	encrypted, err := s.encrypt(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	evt := nostr.Event{
+		PubKey:    s.ClientPubkey,
+		CreatedAt: nostr.Now(),
+		Kind:      KindRequest,
+		Tags:      nostr.Tags{nostr.Tag{"p", s.WalletPubkey}},
+		Content:   encrypted,
+	}
+	if s.Encryption != EncryptionNIP04 {
+		evt.Tags = append(evt.Tags, nostr.Tag{"encryption", s.Encryption})
+	}
+	if err := evt.Sign(s.secret); err != nil {
+		return nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	// Subscribe before publishing so a fast wallet's response isn't missed.
+	// Allow for the wallet's clock running a little behind ours.
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	since := evt.CreatedAt - 60
+	sub, err := s.relay.Subscribe(subCtx, nostr.Filters{{
+		Kinds:   []int{KindResponse},
+		Authors: []string{s.WalletPubkey},
+		Since:   &since,
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to responses: %w", err)
+	}
+
+	start := time.Now()
+	if err := s.relay.Publish(ctx, evt); err != nil {
+		return nil, fmt.Errorf("failed to publish request: %w", err)
+	}
+
+	for {
+		select {
+		case response, ok := <-sub.Events:
+			if !ok {
+				return nil, fmt.Errorf("relay closed the subscription")
+			}
+			tagged := hasTag(response.Tags, "e", evt.ID)
+			if !tagged && hasTag(response.Tags, "e", "") {
+				continue // Response to another request
+			}
+
+			decrypted, err := s.decrypt(response.Content)
+			if err != nil {
+				if tagged {
+					return nil, fmt.Errorf("failed to decrypt response: %w", err)
+				}
+				continue
+			}
+			var resp Response
+			if err := json.Unmarshal([]byte(decrypted), &resp); err != nil {
+				if tagged {
+					return nil, fmt.Errorf("failed to parse response: %w", err)
+				}
+				continue
+			}
+			if !tagged && (resp.ResultType != method || response.CreatedAt < evt.CreatedAt) {
+				continue
+			}
+
+			return &Reply{Response: &resp, Event: response, RequestID: evt.ID, Latency: time.Since(start)}, nil
+
+		case <-ctx.Done():
+			return nil, fmt.Errorf("no response to %s: %w", method, ctx.Err())
+		}
+	}
+}
+
+// Close disconnects from the wallet's relay
+func (s *Session) Close() {
+	s.relay.Close()
+}
+
+// encrypt encrypts request content with the session's scheme
+func (s *Session) encrypt(plaintext string) (string, error) {
+	if s.Encryption == EncryptionNIP44 {
+		key, err := nip44.GenerateConversationKey(s.WalletPubkey, s.secret)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate conversation key: %w", err)
+		}
+		return nip44.Encrypt(plaintext, key)
+	}
+
+	shared, err := nip04.ComputeSharedSecret(s.WalletPubkey, s.secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute shared secret: %w", err)
+	}
+	return nip04.Encrypt(plaintext, shared)
+}
+
+// decrypt decrypts response content; NIP-04 ciphertexts carry an "?iv=" suffix
+func (s *Session) decrypt(ciphertext string) (string, error) {
+	if strings.Contains(ciphertext, "?iv=") {
+		shared, err := nip04.ComputeSharedSecret(s.WalletPubkey, s.secret)
+		if err != nil {
+			return "", fmt.Errorf("failed to compute shared secret: %w", err)
+		}
+		return nip04.Decrypt(ciphertext, shared)
+	}
+
+	key, err := nip44.GenerateConversationKey(s.WalletPubkey, s.secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate conversation key: %w", err)
+	}
+	return nip44.Decrypt(ciphertext, key)
+}
+
+// hasTag reports whether tags contain key with the given value, or with any
+// value if value is empty
+func hasTag(tags nostr.Tags, key, value string) bool {
+	for _, tag := range tags {
+		if len(tag) >= 2 && tag[0] == key && (value == "" || tag[1] == value) {
+			return true
+		}
+	}
+	return false
+}
@@ -29,6 +29,12 @@ func NewPlebSigner() (*PlebSigner, error) {
 	return &PlebSigner{conn: conn, obj: obj}, nil
 }
 
+// NewPlebSignerWithObject talks to the signer through obj instead of the
+// session bus, e.g. a stand-in signer in tests
+func NewPlebSignerWithObject(obj dbus.BusObject) *PlebSigner {
+	return &PlebSigner{obj: obj}
+}
+
 func (s *PlebSigner) IsReady() (bool, error) {
 	var ready bool
 	err := s.obj.Call(Interface+".IsReady", 0).Store(&ready)
@@ -200,5 +206,8 @@ func (s *PlebSigner) Nip04Decrypt(senderPubKey string, ciphertext string) (strin
 }
 
 func (s *PlebSigner) Close() error {
+	if s.conn == nil {
+		return nil
+	}
 	return s.conn.Close()
 }
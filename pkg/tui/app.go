@@ -0,0 +1,298 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/nbd-wtf/go-nostr"
+	"noscli/pkg/client"
+	"noscli/pkg/config"
+	"noscli/pkg/nwc"
+	"noscli/pkg/signer"
+	"noscli/pkg/zap"
+)
+
+// appContext is the state every screen shares: who we are, where we
+// connect, caches, settings and the wallet connection. Model embeds it, and
+// screens get it passed to their Update and View.
+type appContext struct {
+	state     sessionState
+	overlay   overlayKind // Prompt or dialog open over the timeline
+	signer    *signer.PlebSigner
+	pubKey    string
+	privKey   string // Private key if using nsec auth
+	npub      string
+	width     int
+	height    int
+	statusMsg string
+	pool      *nostr.SimplePool
+	relays    []string
+	lnurl     *zap.Client     // LNURL-pay requests for zaps and payments
+	profiles  *profileService // Names of the people we show
+	// Settings
+	authMethod         string                 // "pleb_signer" or "nsec" or ""
+	nsecKey            string                 // User's nsec key if authMethod is "nsec"
+	wallets            []config.Wallet        // Named NWC connections
+	defaultWallet      string                 // Name of the wallet used unless another is picked
+	nwcString          string                 // Connection string of the default wallet
+	spending           *config.SpendingLedger // Zaps paid per wallet, for budgets
+	walletNotifsInFeed bool                   // Also show wallet notifications in Notifications view
+	zapConfirmAbove    int64                  // Zaps above this many sats open the confirmation dialog
+	fiatCurrency       string                 // Currency code, "" disables estimates
+	fiatRateURL        string                 // Where to fetch the BTC price from
+	fiatRate           float64                // Price of one bitcoin in fiatCurrency
+	fiatRateTime       time.Time              // When fiatRate was fetched
+	fiatRatePending    bool                   // Whether a rate fetch is in flight
+	columns            []config.Column        // Timeline columns, in order
+	keys               *keymap                // What keys do
+	keyOverrides       map[string][]string    // Keys set in the config, saved back as they are
+	// NWC persistent subscription
+	nwcActive       bool                         // Whether NWC subscription is active
+	nwcResponseChan chan nostr.RelayEvent        // Channel for NWC responses
+	nwcCancelFunc   context.CancelFunc           // Cancel function for NWC subscription
+	pendingPayments map[string]chan *nostr.Event // Map of request ID -> response channel; guarded by paymentsMu
+	paymentsMu      sync.Mutex                   // Payments register in commands, responses arrive in Update
+	nwcSubStarted   nostr.Timestamp              // When the current NWC subscription was opened
+	walletHistory   []nwc.Transaction            // Payments reported by the wallet, newest first
+}
+
+// newAppContext builds the shared state from loaded settings
+func newAppContext(s *signer.PlebSigner, cfg *config.Config, spending *config.SpendingLedger) *appContext {
+	nwcString := ""
+	if w := cfg.GetDefaultWallet(); w != nil {
+		nwcString = w.NWC
+	}
+
+	keys, err := newKeymap(cfg.Keys)
+	statusMsg := ""
+	if err != nil {
+		log.Printf("⚠️  Using the default keys: %v", err)
+		keys, _ = newKeymap(nil)
+		statusMsg = fmt.Sprintf("⚠️ Using the default keys: %v", err)
+	}
+
+	return &appContext{
+		state:              stateLanding,
+		statusMsg:          statusMsg,
+		signer:             s,
+		pool:               nostr.NewSimplePool(context.Background()),
+		relays:             cfg.Relays,
+		lnurl:              zap.NewClient(nil),
+		profiles:           newProfileService(),
+		authMethod:         cfg.AuthMethod,
+		nsecKey:            cfg.Nsec,
+		wallets:            cfg.Wallets,
+		defaultWallet:      cfg.DefaultWallet,
+		nwcString:          nwcString,
+		spending:           spending,
+		walletNotifsInFeed: cfg.WalletNotifications,
+		zapConfirmAbove:    cfg.ZapConfirmAbove,
+		fiatCurrency:       cfg.FiatCurrency,
+		fiatRateURL:        cfg.FiatRateURL,
+		columns:            cfg.Columns,
+		keys:               keys,
+		keyOverrides:       cfg.Keys,
+		nwcResponseChan:    make(chan nostr.RelayEvent, 10),
+		pendingPayments:    make(map[string]chan *nostr.Event),
+	}
+}
+
+// connect switches to the connecting screen and logs in with the chosen
+// auth method
+func (a *appContext) connect() tea.Cmd {
+	a.state = stateConnecting
+	if a.authMethod == "pleb_signer" {
+		return connectToPlebSignerCmd(a.signer)
+	}
+	return connectWithNsecCmd(a.nsecKey)
+}
+
+// client returns a Nostr client for our relays that signs as the logged-in
+// user
+func (a *appContext) client() *client.Client {
+	c := &client.Client{Pool: a.pool, Relays: a.relays, PubKey: a.pubKey, Signer: a.signer, LNURL: a.lnurl}
+	if a.authMethod == "nsec" {
+		c.SecretKey = a.privKey
+	}
+	return c
+}
+
+// requestProfiles fetches the profiles of pubkeys we don't know yet in the
+// background; they arrive as profilesMsg
+func (a *appContext) requestProfiles(pubkeys ...string) {
+	a.profiles.request(a.client(), pubkeys...)
+}
+
+// Close writes out anything still waiting to be saved. Call it once the
+// program has exited.
+func (a *appContext) Close() {
+	a.profiles.flushSave()
+}
+
+// saveConfig persists the current settings to disk
+func (a *appContext) saveConfig() {
+	cfg := &config.Config{
+		AuthMethod:          a.authMethod,
+		Nsec:                a.nsecKey,
+		Relays:              a.relays,
+		Wallets:             a.wallets,
+		DefaultWallet:       a.defaultWallet,
+		WalletNotifications: a.walletNotifsInFeed,
+		ZapConfirmAbove:     a.zapConfirmAbove,
+		FiatCurrency:        a.fiatCurrency,
+		FiatRateURL:         a.fiatRateURL,
+		Columns:             a.columns,
+		Keys:                a.keyOverrides,
+	}
+
+	// Don't block UI if save fails
+	_ = config.Save(cfg)
+}
+
+// startNWCSubscription starts listening for NWC responses on the wallet relay
+func (a *appContext) startNWCSubscription() {
+	if a.nwcString == "" {
+		return
+	}
+
+	// Stop existing subscription if any
+	a.stopNWCSubscription()
+
+	log.Printf("🔌 [NWC] Starting persistent NWC subscription...")
+
+	// Parse NWC string
+	walletPubkey, relay, secret, err := nwc.ParseNWCString(a.nwcString)
+	if err != nil {
+		log.Printf("❌ [NWC] Failed to parse NWC string: %v", err)
+		return
+	}
+
+	log.Printf("✅ [NWC] Wallet: %s, Relay: %s", walletPubkey[:8], relay)
+	log.Printf("🔑 [NWC] Full wallet pubkey: %s", walletPubkey)
+	if a.pubKey != "" {
+		log.Printf("🔑 [NWC] Our pubkey: %s", a.pubKey)
+	} else {
+		log.Printf("⚠️  [NWC] Our pubkey not set yet (will accept all events)")
+	}
+
+	// Create context for this subscription
+	ctx, cancel := context.WithCancel(context.Background())
+	a.nwcCancelFunc = cancel
+
+	// Subscribe to kind 23195 events from wallet (no 'p' filter for compatibility)
+	filters := []nostr.Filter{{
+		Kinds:   []int{23195},
+		Authors: []string{walletPubkey},
+	}}
+
+	// Wallet notifications are addressed to the connection's own key, not ours
+	if clientPubkey, err := nwc.ClientPubkey(secret); err == nil {
+		filters = append(filters, nostr.Filter{
+			Kinds:   []int{nwc.KindNotificationNIP04, nwc.KindNotificationNIP44},
+			Authors: []string{walletPubkey},
+			Tags:    nostr.TagMap{"p": []string{clientPubkey}},
+			Limit:   20,
+		})
+		log.Printf("🔔 [NWC] Also subscribing to notifications for client %s", clientPubkey[:8])
+	} else {
+		log.Printf("⚠️  [NWC] Can't derive client pubkey, notifications disabled: %v", err)
+	}
+
+	log.Printf("📡 [NWC] Subscribing to relay: %s", relay)
+	if a.pubKey != "" {
+		log.Printf("🔍 [NWC] Filter: kinds=[23195], authors=[%s] (will check p=%s in code)", walletPubkey[:8], a.pubKey[:8])
+	} else {
+		log.Printf("🔍 [NWC] Filter: kinds=[23195], authors=[%s] (no p-tag check yet)", walletPubkey[:8])
+	}
+	responseChan := a.pool.SubMany(ctx, []string{relay}, filters)
+	a.nwcActive = true
+	a.nwcSubStarted = nostr.Now()
+	log.Printf("✅ [NWC] SubMany returned, nwcActive set to true")
+
+	// Start goroutine to forward events to our channel
+	go func() {
+		defer log.Printf("🛑 [NWC] Goroutine exiting")
+		log.Printf("👂 [NWC] Listening goroutine started, waiting for events...")
+		eventCount := 0
+		for {
+			select {
+			case event, ok := <-responseChan:
+				eventCount++
+				log.Printf("📬 [NWC] Received something from channel (count=%d, ok=%v)", eventCount, ok)
+				if !ok {
+					log.Printf("❌ [NWC] Response channel closed")
+					return
+				}
+				if event.Event != nil {
+					log.Printf("📨 [NWC] Event details: ID=%s, kind=%d, author=%s, created=%d",
+						event.Event.ID[:8], event.Event.Kind, event.Event.PubKey[:8], event.Event.CreatedAt)
+					log.Printf("📋 [NWC] Event tags: %v", event.Event.Tags)
+
+					// Check if it's for us (only if we have a pubkey)
+					isForUs := (a.pubKey == "") // Accept all if we don't have pubkey yet
+					if a.pubKey != "" {
+						for _, tag := range event.Event.Tags {
+							if len(tag) >= 2 && tag[0] == "p" && tag[1] == a.pubKey {
+								isForUs = true
+								break
+							}
+						}
+					}
+					log.Printf("🎯 [NWC] Event is for us: %v (our pubkey=%s)", isForUs, func() string {
+						if a.pubKey != "" {
+							return a.pubKey[:8]
+						}
+						return "(not set)"
+					}())
+
+					// Forward to main channel regardless (we'll filter in handler)
+					select {
+					case a.nwcResponseChan <- event:
+						log.Printf("✅ [NWC] Forwarded to main channel")
+					default:
+						log.Printf("⚠️  [NWC] Response channel full, dropping event")
+					}
+				} else {
+					log.Printf("⚠️  [NWC] Received event with nil Event field")
+				}
+			case <-ctx.Done():
+				log.Printf("🛑 [NWC] Subscription stopped")
+				return
+			}
+		}
+	}()
+
+	log.Printf("✅ [NWC] Persistent subscription active")
+}
+
+// stopNWCSubscription stops the NWC subscription
+func (a *appContext) stopNWCSubscription() {
+	if a.nwcCancelFunc != nil {
+		log.Printf("🛑 [NWC] Stopping NWC subscription...")
+		a.nwcCancelFunc()
+		a.nwcCancelFunc = nil
+	}
+	a.nwcActive = false
+}
+
+// addPendingPayment registers the channel a wallet response to request id
+// is delivered on
+func (a *appContext) addPendingPayment(id string, ch chan *nostr.Event) {
+	a.paymentsMu.Lock()
+	defer a.paymentsMu.Unlock()
+	a.pendingPayments[id] = ch
+}
+
+// takePendingPayment removes and returns the channel waiting for a response
+// to request id
+func (a *appContext) takePendingPayment(id string) (chan *nostr.Event, bool) {
+	a.paymentsMu.Lock()
+	defer a.paymentsMu.Unlock()
+	ch, ok := a.pendingPayments[id]
+	delete(a.pendingPayments, id)
+	return ch, ok
+}
@@ -0,0 +1,318 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nbd-wtf/go-nostr"
+	"noscli/pkg/client"
+	"noscli/pkg/config"
+)
+
+// minColumnWidth is how wide every column has to get before they're shown
+// side by side instead of as tabs
+const minColumnWidth = 40
+
+// columnViews maps configured column kinds to what they show
+var columnViews = map[string]viewMode{
+	config.ColumnFollowing:     viewFollowing,
+	config.ColumnDMs:           viewDMs,
+	config.ColumnNotifications: viewNotifications,
+	config.ColumnFeed:          viewFeed,
+}
+
+// column is one timeline column. The focused column's cursor and scroll
+// live in Model, so everything that acts on the selected note works on
+// whichever column has the focus; the others keep theirs in state.
+type column struct {
+	config.Column
+	id     int // Stays put when columns are moved, so fetches find their column
+	view   viewMode
+	events []nostr.Event // Notes of a custom feed; the other views keep theirs in Model
+	state  columnState
+	opened bool // Opened from the command palette for this session, so not saved
+}
+
+// columnState is where a column's cursor and scroll are
+type columnState struct {
+	cursor   int
+	top      int
+	topLine  int
+	viewport viewport.Model
+}
+
+// feedMsg delivers the notes of a custom feed column
+type feedMsg struct {
+	column int // Column id
+	events []nostr.Event
+}
+
+// newColumns builds the columns of layout, or of the default layout if it's
+// empty. Columns of unknown kinds are skipped.
+func newColumns(layout []config.Column) []column {
+	var columns []column
+	for _, c := range layout {
+		view, ok := columnViews[c.Kind]
+		if !ok {
+			log.Printf("⚠️  Skipping column of unknown kind %q", c.Kind)
+			continue
+		}
+		columns = append(columns, column{Column: c, id: len(columns), view: view, state: columnState{viewport: viewport.New(0, 0)}})
+	}
+	if len(columns) == 0 {
+		return newColumns(config.DefaultColumns())
+	}
+	return columns
+}
+
+// title returns the column's name for its tab
+func (c *column) title() string {
+	if c.Title != "" {
+		return c.Title
+	}
+	switch c.view {
+	case viewDMs:
+		return "DMs"
+	case viewNotifications:
+		return "Notifications"
+	case viewFeed:
+		if c.Search != "" {
+			return "🔍 " + c.Search
+		}
+		if len(c.Hashtags) > 0 {
+			return "#" + strings.Join(c.Hashtags, " #")
+		}
+		if len(c.Authors) > 0 {
+			return "Feed"
+		}
+		return "Global"
+	}
+	return "Following"
+}
+
+// dashboard reports whether the columns are shown side by side
+func (m *Model) dashboard() bool {
+	return len(m.columns) > 1 && m.width >= len(m.columns)*minColumnWidth
+}
+
+// columnWidth returns how wide the timeline of one column is
+func (m *Model) columnWidth() int {
+	if !m.dashboard() {
+		return m.width
+	}
+	// One space between columns
+	return (m.width - (len(m.columns) - 1)) / len(m.columns)
+}
+
+// switchColumn moves the focus to column i, swapping the cursors and
+// scroll positions
+func (m *Model) switchColumn(i int) {
+	m.columns[m.focus].state = columnState{cursor: m.cursor, top: m.top, topLine: m.topLine, viewport: m.viewport}
+	m.focus = i
+	state := m.columns[i].state
+	m.cursor, m.top, m.topLine, m.viewport = state.cursor, state.top, state.topLine, state.viewport
+	m.currentView = m.columns[i].view
+}
+
+// inColumn runs f as if column i had the focus
+func (m *Model) inColumn(i int, f func()) {
+	focused := m.focus
+	m.switchColumn(i)
+	f()
+	m.switchColumn(focused)
+}
+
+// focusColumn focuses column i, loading it the first time it's shown
+func (m *Model) focusColumn(i int) tea.Cmd {
+	m.switchColumn(i)
+	if cmd := m.fetchColumn(i); cmd != nil {
+		m.statusMsg = fmt.Sprintf("Loading %s...", m.columns[i].title())
+		return cmd
+	}
+	m.markVisibleAsRead(m.currentView)
+	return nil
+}
+
+// fetchColumn fetches what column i shows if nothing has been loaded yet
+func (m *Model) fetchColumn(i int) tea.Cmd {
+	c := &m.columns[i]
+	switch c.view {
+	case viewFollowing:
+		if len(m.events) == 0 {
+			return fetchEventsCmd(m.client(), m.following)
+		}
+	case viewDMs:
+		if len(m.dms) == 0 {
+			return fetchDMsCmd(m.client())
+		}
+	case viewNotifications:
+		if len(m.notifications) == 0 {
+			return fetchNotificationsCmd(m.client())
+		}
+	case viewFeed:
+		if len(c.events) == 0 {
+			return fetchFeedCmd(m.client(), c)
+		}
+	}
+	return nil
+}
+
+// loadColumns fetches the columns on screen other than the followed feed,
+// which login fetches itself: every column on a dashboard, or the focused
+// one
+func (m *Model) loadColumns() tea.Cmd {
+	var cmds []tea.Cmd
+	for i, c := range m.columns {
+		if c.view != viewFollowing && (i == m.focus || m.dashboard()) {
+			cmds = append(cmds, m.fetchColumn(i))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// moveColumn moves the focused column by delta places and saves the layout
+func (m *Model) moveColumn(delta int) {
+	to := m.focus + delta
+	if to < 0 || to >= len(m.columns) {
+		return
+	}
+	m.columns[m.focus], m.columns[to] = m.columns[to], m.columns[m.focus]
+	m.focus = to
+
+	m.appContext.columns = nil
+	for _, c := range m.columns {
+		if !c.opened {
+			m.appContext.columns = append(m.appContext.columns, c.Column)
+		}
+	}
+	m.saveConfig()
+}
+
+// openColumn focuses the column showing feed, adding it after the others
+// for this session if there's none yet
+func (m *Model) openColumn(feed config.Column) tea.Cmd {
+	for i, c := range m.columns {
+		if c.view == viewFeed && c.Search == feed.Search && slices.Equal(c.Authors, feed.Authors) && slices.Equal(c.Hashtags, feed.Hashtags) {
+			cmd := m.focusColumn(i)
+			m.updateContent()
+			return cmd
+		}
+	}
+	m.columns = append(m.columns, column{Column: feed, id: len(m.columns), view: viewFeed, state: columnState{viewport: viewport.New(0, 0)}, opened: true})
+	cmd := m.focusColumn(len(m.columns) - 1)
+	m.updateContent()
+	return cmd
+}
+
+// storeFeed merges a custom feed's notes into its column
+func (m *Model) storeFeed(msg feedMsg) *column {
+	var c *column
+	for i := range m.columns {
+		if m.columns[i].id == msg.column {
+			c = &m.columns[i]
+		}
+	}
+	if c == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(c.events))
+	for _, evt := range c.events {
+		seen[evt.ID] = true
+	}
+	newCount := 0
+	for _, evt := range msg.events {
+		if seen[evt.ID] {
+			continue
+		}
+		seen[evt.ID] = true
+		c.events = append(c.events, evt)
+		newCount++
+		m.requestProfiles(evt.PubKey)
+	}
+	sort.Slice(c.events, func(i, j int) bool {
+		return c.events[i].CreatedAt > c.events[j].CreatedAt
+	})
+	m.statusMsg = fmt.Sprintf("Loaded %d new notes in %s (%d total)", newCount, c.title(), len(c.events))
+	return c
+}
+
+// renderTabs renders the column titles, lined up above their columns on a
+// dashboard
+func (m *Model) renderTabs() string {
+	tabStyle := lipgloss.NewStyle().Padding(0, 2)
+	activeTabStyle := tabStyle.Copy().Bold(true).Foreground(lipgloss.Color("205"))
+	inactiveTabStyle := tabStyle.Copy().Foreground(lipgloss.Color("241"))
+	unreadBadgeStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214"))
+	if m.dashboard() {
+		activeTabStyle = activeTabStyle.Width(m.columnWidth() + 1)
+		inactiveTabStyle = inactiveTabStyle.Width(m.columnWidth() + 1)
+	}
+
+	tabs := make([]string, len(m.columns))
+	for i := range m.columns {
+		label := m.columns[i].title()
+		if unread := m.countUnread(m.columns[i].view); unread > 0 {
+			label = fmt.Sprintf("%s %s", label, unreadBadgeStyle.Render(fmt.Sprintf("(%d)", unread)))
+		}
+		if i == m.focus {
+			tabs[i] = activeTabStyle.Render(label)
+		} else {
+			tabs[i] = inactiveTabStyle.Render(label)
+		}
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
+}
+
+// renderColumns renders every column's viewport side by side
+func (m *Model) renderColumns() string {
+	views := make([]string, 0, 2*len(m.columns))
+	for i := range m.columns {
+		if i > 0 {
+			views = append(views, " ")
+		}
+		if i == m.focus {
+			views = append(views, m.viewport.View())
+		} else {
+			views = append(views, m.columns[i].state.viewport.View())
+		}
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, views...)
+}
+
+// fetchFeedCmd fetches the notes of a custom feed column
+func fetchFeedCmd(c *client.Client, col *column) tea.Cmd {
+	id, authors, hashtags, search := col.id, col.Authors, col.Hashtags, col.Search
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				// Catch panics from relay operations; an empty batch still
+				// reaches the column so it isn't left waiting
+				msg = feedMsg{column: id}
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var events []nostr.Event
+		var err error
+		if search != "" {
+			events, err = c.FetchSearch(ctx, search)
+		} else {
+			events, err = c.FetchCustomFeed(ctx, authors, hashtags)
+		}
+		if err != nil {
+			log.Printf("⚠️  Failed to fetch feed: %v", err)
+		}
+		return feedMsg{column: id, events: events}
+	}
+}
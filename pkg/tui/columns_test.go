@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nbd-wtf/go-nostr"
+	"noscli/pkg/config"
+)
+
+func TestDashboardColumns(t *testing.T) {
+	h := newUIHarness(t, &config.Config{AuthMethod: "nsec", Columns: []config.Column{
+		{Kind: config.ColumnFollowing},
+		{Kind: config.ColumnFeed, Hashtags: []string{"nostr"}},
+		{Kind: "bogus"},
+		{Kind: config.ColumnNotifications, Title: "Mentions"},
+	}})
+	h.loggedIn(
+		h.event(goldenAlice, 1, "first followed note", time.Minute),
+		h.event(goldenBob, 1, "second followed note", time.Hour),
+	)
+	if len(h.m.columns) != 3 || h.m.dashboard() {
+		t.Fatalf("%d columns, dashboard %v at 100 columns", len(h.m.columns), h.m.dashboard())
+	}
+
+	// Widening shows them side by side and loads the ones not yet shown
+	if cmd := h.update(tea.WindowSizeMsg{Width: 150, Height: 30}); cmd == nil || !h.m.dashboard() {
+		t.Fatal("no dashboard at 150 columns")
+	}
+	h.send(
+		feedMsg{column: 1, events: []nostr.Event{h.event(goldenBob, 1, "tagged note", 2*time.Minute, nostr.Tag{"t", "nostr"})}},
+		notificationsMsg{events: []nostr.Event{h.event(goldenBob, 1, "hey me", 3*time.Minute, nostr.Tag{"p", goldenMe.pk})}},
+	)
+	h.golden("dashboard")
+
+	// Each column keeps its own cursor
+	h.keys("down")
+	h.keys("tab")
+	if h.m.currentView != viewFeed || h.m.cursor != 0 {
+		t.Errorf("tab: view %d, cursor %d", h.m.currentView, h.m.cursor)
+	}
+	if view := h.m.View(); !strings.Contains(view, "> 🔵 @bob  1 hour ago") || strings.Count(view, "│ > ") != 3 {
+		t.Errorf("cursors moved:\n%s", view)
+	}
+	h.keys("shift+tab")
+	if h.m.currentView != viewFollowing || h.m.cursor != 1 {
+		t.Errorf("shift+tab: view %d, cursor %d", h.m.currentView, h.m.cursor)
+	}
+
+	// Moving a column saves the layout
+	h.keys(">")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Columns) != 3 || cfg.Columns[0].Kind != config.ColumnFeed || cfg.Columns[1].Kind != config.ColumnFollowing || cfg.Columns[2].Title != "Mentions" {
+		t.Errorf("saved columns %+v", cfg.Columns)
+	}
+	if h.m.focus != 1 || h.m.cursor != 1 {
+		t.Errorf("moved column lost focus: focus %d, cursor %d", h.m.focus, h.m.cursor)
+	}
+}
@@ -0,0 +1,388 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip04"
+	"github.com/nbd-wtf/go-nostr/nip44"
+	"github.com/nbd-wtf/go-nostr/nip59"
+	"noscli/internal/testrelay"
+	"noscli/internal/testsigner"
+	"noscli/pkg/client"
+	"noscli/pkg/config"
+	"noscli/pkg/signer"
+	"noscli/pkg/zap"
+)
+
+// testUser is a key pair for building fixtures
+type testUser struct {
+	sk, pk string
+}
+
+func newTestUser() testUser {
+	sk := nostr.GeneratePrivateKey()
+	pk, _ := nostr.GetPublicKey(sk)
+	return testUser{sk, pk}
+}
+
+// publish stores an event signed by u on relay
+func (u testUser) publish(t *testing.T, relay *testrelay.Relay, kind int, content string, createdAt nostr.Timestamp, tags ...nostr.Tag) nostr.Event {
+	t.Helper()
+	evt := nostr.Event{Kind: kind, Content: content, CreatedAt: createdAt, Tags: tags}
+	if err := evt.Sign(u.sk); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	relay.Publish(evt)
+	return evt
+}
+
+// client returns a client for relays that signs with u's key
+func (u testUser) client(pool *nostr.SimplePool, relays ...string) *client.Client {
+	return &client.Client{Pool: pool, Relays: relays, PubKey: u.pk, SecretKey: u.sk}
+}
+
+// readClient returns a client for relays that can only read, as pubkey
+func readClient(pool *nostr.SimplePool, pubkey string, relays ...string) *client.Client {
+	return &client.Client{Pool: pool, Relays: relays, PubKey: pubkey}
+}
+
+// signerClient returns a client for relays that signs through Pleb Signer
+func signerClient(pool *nostr.SimplePool, s *signer.PlebSigner, pubkey string, relays ...string) *client.Client {
+	return &client.Client{Pool: pool, Relays: relays, PubKey: pubkey, Signer: s}
+}
+
+// newTestRelay starts a relay and a pool to talk to it
+func newTestRelay(t *testing.T) (*testrelay.Relay, *nostr.SimplePool) {
+	t.Helper()
+	relay := testrelay.New()
+	t.Cleanup(relay.Close)
+	return relay, nostr.NewSimplePool(context.Background())
+}
+
+// contents returns the events' contents in order
+func contents(events []nostr.Event) []string {
+	var out []string
+	for _, evt := range events {
+		out = append(out, evt.Content)
+	}
+	return out
+}
+
+// hasContents reports whether events hold exactly want, in any order
+func hasContents(events []nostr.Event, want ...string) bool {
+	if len(events) != len(want) {
+		return false
+	}
+	seen := make(map[string]int)
+	for _, evt := range events {
+		seen[evt.Content]++
+	}
+	for _, w := range want {
+		if seen[w] == 0 {
+			return false
+		}
+		seen[w]--
+	}
+	return true
+}
+
+func TestFetchEventsCmd(t *testing.T) {
+	relay, pool := newTestRelay(t)
+	alice, bob := newTestUser(), newTestUser()
+	alice.publish(t, relay, 1, "alice note", 100)
+	alice.publish(t, relay, 7, "+", 101)
+	bob.publish(t, relay, 1, "bob note", 102)
+
+	msg := fetchEventsCmd(readClient(pool, "", relay.URL), []string{alice.pk})()
+	events, ok := msg.(eventsMsg)
+	if !ok {
+		t.Fatalf("got %T, want eventsMsg", msg)
+	}
+	if !hasContents(events.events, "alice note") {
+		t.Errorf("following alice: got %q", contents(events.events))
+	}
+
+	// Without a follow list the timeline is global
+	events = fetchEventsCmd(readClient(pool, "", relay.URL), nil)().(eventsMsg)
+	if !hasContents(events.events, "alice note", "bob note") {
+		t.Errorf("global: got %q", contents(events.events))
+	}
+}
+
+func TestFetchFeedCmd(t *testing.T) {
+	relay, pool := newTestRelay(t)
+	alice, bob := newTestUser(), newTestUser()
+	alice.publish(t, relay, 1, "alice on nostr", 100, nostr.Tag{"t", "nostr"})
+	alice.publish(t, relay, 1, "alice on bitcoin", 101, nostr.Tag{"t", "bitcoin"})
+	bob.publish(t, relay, 1, "bob on nostr", 102, nostr.Tag{"t", "nostr"})
+
+	col := &column{id: 3, Column: config.Column{Kind: config.ColumnFeed, Hashtags: []string{"nostr"}}}
+	feed := fetchFeedCmd(readClient(pool, "", relay.URL), col)().(feedMsg)
+	if feed.column != 3 || !hasContents(feed.events, "alice on nostr", "bob on nostr") || len(feed.events) != 2 {
+		t.Errorf("#nostr: got column %d, %q", feed.column, contents(feed.events))
+	}
+
+	col.Authors = []string{alice.pk}
+	feed = fetchFeedCmd(readClient(pool, "", relay.URL), col)().(feedMsg)
+	if len(feed.events) != 1 || !hasContents(feed.events, "alice on nostr") {
+		t.Errorf("#nostr by alice: got %q", contents(feed.events))
+	}
+
+	// A panic in the relay code still answers for the column
+	if msg, ok := fetchFeedCmd(nil, col)().(feedMsg); !ok || msg.column != 3 {
+		t.Errorf("after a panic: got %+v", msg)
+	}
+}
+
+func TestFetchEventsCmdDeduplicatesRelays(t *testing.T) {
+	relay1, pool := newTestRelay(t)
+	relay2 := testrelay.New()
+	defer relay2.Close()
+
+	alice := newTestUser()
+	note := alice.publish(t, relay1, 1, "on both", 100)
+	relay2.Publish(note)
+	alice.publish(t, relay2, 1, "only on relay2", 101)
+
+	events := fetchEventsCmd(readClient(pool, "", relay1.URL, relay2.URL), []string{alice.pk})().(eventsMsg)
+	if !hasContents(events.events, "on both", "only on relay2") {
+		t.Errorf("got %q, want each note once", contents(events.events))
+	}
+}
+
+func TestFetchDMsCmd(t *testing.T) {
+	relay, pool := newTestRelay(t)
+	me, alice, bob := newTestUser(), newTestUser(), newTestUser()
+	alice.publish(t, relay, 4, "alice to me", 100, nostr.Tag{"p", me.pk})
+	alice.publish(t, relay, 1059, "gift wrap to me", 101, nostr.Tag{"p", me.pk})
+	me.publish(t, relay, 4, "me to bob", 102, nostr.Tag{"p", bob.pk})
+	alice.publish(t, relay, 4, "alice to bob", 103, nostr.Tag{"p", bob.pk})
+	alice.publish(t, relay, 1, "note mentioning me", 104, nostr.Tag{"p", me.pk})
+
+	msg := fetchDMsCmd(readClient(pool, me.pk, relay.URL))()
+	dms, ok := msg.(dmsMsg)
+	if !ok {
+		t.Fatalf("got %T, want dmsMsg", msg)
+	}
+	if !hasContents(dms.events, "alice to me", "gift wrap to me", "me to bob") {
+		t.Errorf("got %q", contents(dms.events))
+	}
+}
+
+func TestFetchNotificationsCmd(t *testing.T) {
+	relay, pool := newTestRelay(t)
+	me, alice := newTestUser(), newTestUser()
+	alice.publish(t, relay, 1, "hey @me", 100, nostr.Tag{"p", me.pk})
+	alice.publish(t, relay, 7, "🤙", 101, nostr.Tag{"p", me.pk})
+	alice.publish(t, relay, zap.KindZapReceipt, "", 102, nostr.Tag{"p", me.pk})
+	alice.publish(t, relay, 1, "talking to myself", 103)
+	alice.publish(t, relay, 4, "a DM isn't a notification", 104, nostr.Tag{"p", me.pk})
+
+	msg := fetchNotificationsCmd(readClient(pool, me.pk, relay.URL))()
+	notifications, ok := msg.(notificationsMsg)
+	if !ok {
+		t.Fatalf("got %T, want notificationsMsg", msg)
+	}
+	if !hasContents(notifications.events, "hey @me", "🤙", "") {
+		t.Errorf("got %q", contents(notifications.events))
+	}
+}
+
+func TestFetchThreadCmd(t *testing.T) {
+	relay, pool := newTestRelay(t)
+	alice, bob := newTestUser(), newTestUser()
+	root := alice.publish(t, relay, 1, "root", 100)
+	bob.publish(t, relay, 1, "second reply", 300, nostr.Tag{"e", root.ID, "", "root"})
+	bob.publish(t, relay, 1, "first reply", 200, nostr.Tag{"e", root.ID, "", "reply"})
+	bob.publish(t, relay, 7, "+", 250, nostr.Tag{"e", root.ID})
+	bob.publish(t, relay, 1, "unrelated", 150)
+
+	msg := fetchThreadCmd(readClient(pool, "", relay.URL), &root)()
+	thread, ok := msg.(threadEventsMsg)
+	if !ok {
+		t.Fatalf("got %T, want threadEventsMsg", msg)
+	}
+	if got := strings.Join(contents(thread.events), ", "); got != "first reply, second reply" {
+		t.Errorf("got %q, want replies oldest first", got)
+	}
+}
+
+func TestFetchNoteCmd(t *testing.T) {
+	relay, pool := newTestRelay(t)
+	note := newTestUser().publish(t, relay, 1, "open me", 100)
+
+	msg := fetchNoteCmd(readClient(pool, "", relay.URL), note.ID)().(noteMsg)
+	if msg.err != nil || msg.event == nil || msg.event.Content != "open me" {
+		t.Errorf("got %+v", msg)
+	}
+	if msg := fetchNoteCmd(readClient(pool, "", relay.URL), strings.Repeat("0", 64))().(noteMsg); msg.err == nil {
+		t.Error("no error for a missing note")
+	}
+}
+
+func TestFollowCmd(t *testing.T) {
+	relay, pool := newTestRelay(t)
+	me, alice, bob := newTestUser(), newTestUser(), newTestUser()
+	me.publish(t, relay, nostr.KindFollowList, "", 100, nostr.Tag{"p", alice.pk})
+
+	msg := followCmd(me.client(pool, relay.URL), bob.pk)().(followMsg)
+	if msg.err != nil || msg.pubkey != bob.pk || strings.Join(msg.following, ",") != alice.pk+","+bob.pk {
+		t.Errorf("got %+v", msg)
+	}
+	if lists := relay.Events(nostr.Filter{Kinds: []int{nostr.KindFollowList}}); len(lists) != 1 || len(lists[0].Tags) != 2 {
+		t.Errorf("contact lists on the relay: %v", lists)
+	}
+}
+
+func TestPublishPostCmd(t *testing.T) {
+	relay, pool := newTestRelay(t)
+	me, alice := newTestUser(), newTestUser()
+	root := alice.publish(t, relay, 1, "root", 100)
+	parent := alice.publish(t, relay, 1, "parent", 200, nostr.Tag{"e", root.ID, "", "root"})
+	splits := []zap.Split{{Pubkey: alice.pk, Weight: 1}}
+
+	msg := publishPostCmd(me.client(pool, relay.URL), "my reply", &parent, splits)()
+	published, ok := msg.(publishSuccessMsg)
+	if !ok {
+		t.Fatalf("got %v, want publishSuccessMsg", msg)
+	}
+
+	stored := relay.Events(nostr.Filter{IDs: []string{published.eventID}})
+	if len(stored) != 1 {
+		t.Fatalf("reply wasn't stored")
+	}
+	reply := stored[0]
+	if reply.PubKey != me.pk || reply.Content != "my reply" {
+		t.Errorf("reply = %s by %s", reply.Content, reply.PubKey)
+	}
+	for _, want := range []nostr.Tag{
+		{"e", parent.ID, "", "reply"},
+		{"e", root.ID, "", "root"},
+		{"p", alice.pk},
+	} {
+		if !hasExactTag(reply.Tags, want) {
+			t.Errorf("reply tags %v are missing %v", reply.Tags, want)
+		}
+	}
+	if reply.Tags.GetFirst([]string{"zap", alice.pk}) == nil {
+		t.Errorf("reply tags %v are missing the zap split", reply.Tags)
+	}
+
+	// Pleb Signer signs with the user's key
+	s, signerPK := testsigner.New()
+	published = publishPostCmd(signerClient(pool, s, signerPK, relay.URL), "signed by the signer", nil, nil)().(publishSuccessMsg)
+	if stored := relay.Events(nostr.Filter{IDs: []string{published.eventID}}); len(stored) != 1 || stored[0].PubKey != signerPK {
+		t.Errorf("signer post wasn't stored under the signer's key")
+	}
+}
+
+func TestPublishPostCmdRejected(t *testing.T) {
+	relay, pool := newTestRelay(t)
+	relay.Reject(func(nostr.Event) string { return "blocked: read only" })
+	me := newTestUser()
+
+	msg := publishPostCmd(me.client(pool, relay.URL), "hello", nil, nil)()
+	err, ok := msg.(errMsg)
+	if !ok || !strings.Contains(err.err.Error(), "read only") {
+		t.Fatalf("got %v, want the relay's rejection", msg)
+	}
+
+	// One accepting relay is enough
+	open := testrelay.New()
+	defer open.Close()
+	msg = publishPostCmd(me.client(pool, relay.URL, open.URL), "hello", nil, nil)()
+	if _, ok := msg.(publishSuccessMsg); !ok {
+		t.Errorf("got %v, want success through the open relay", msg)
+	}
+}
+
+func TestPublishDMCmdNIP04(t *testing.T) {
+	relay, pool := newTestRelay(t)
+	alice := newTestUser()
+	s, signerPK := testsigner.New()
+
+	msg := publishDMCmd(signerClient(pool, s, signerPK, relay.URL), alice.pk, "secret")()
+	published, ok := msg.(publishSuccessMsg)
+	if !ok || published.status != "DM sent (NIP-04) ✓" {
+		t.Fatalf("got %v, want a NIP-04 DM", msg)
+	}
+
+	stored := relay.Events(nostr.Filter{IDs: []string{published.eventID}})
+	if len(stored) != 1 || stored[0].Kind != 4 || !hasExactTag(stored[0].Tags, nostr.Tag{"p", alice.pk}) {
+		t.Fatalf("stored %v, want a kind 4 DM tagging alice", stored)
+	}
+	shared, _ := nip04.ComputeSharedSecret(signerPK, alice.sk)
+	if plaintext, err := nip04.Decrypt(stored[0].Content, shared); err != nil || plaintext != "secret" {
+		t.Errorf("alice decrypted %q, %v", plaintext, err)
+	}
+}
+
+func TestPublishDMCmdNIP17(t *testing.T) {
+	relay, pool := newTestRelay(t)
+	me, alice := newTestUser(), newTestUser()
+
+	msg := publishDMCmd(me.client(pool, relay.URL), alice.pk, "secret")()
+	if published, ok := msg.(publishSuccessMsg); !ok || published.status != "DM sent (NIP-17) ✓" {
+		t.Fatalf("got %v, want a NIP-17 DM", msg)
+	}
+
+	// One gift wrap for the recipient and one for ourselves, neither signed by us
+	for _, user := range []testUser{alice, me} {
+		wraps := relay.Events(nostr.Filter{Kinds: []int{1059}, Tags: nostr.TagMap{"p": []string{user.pk}}})
+		if len(wraps) != 1 {
+			t.Fatalf("%d gift wraps for %s, want 1", len(wraps), user.pk)
+		}
+		if wraps[0].PubKey == me.pk {
+			t.Error("gift wrap is signed with the sender's key")
+		}
+		rumor, err := nip59.GiftUnwrap(wraps[0], func(otherPubkey, ciphertext string) (string, error) {
+			key, err := nip44.GenerateConversationKey(otherPubkey, user.sk)
+			if err != nil {
+				return "", err
+			}
+			return nip44.Decrypt(ciphertext, key)
+		})
+		if err != nil {
+			t.Fatalf("GiftUnwrap: %v", err)
+		}
+		if rumor.Content != "secret" || rumor.PubKey != me.pk || rumor.Kind != nostr.KindDirectMessage {
+			t.Errorf("rumor = %+v", rumor)
+		}
+	}
+}
+
+func TestRepostCmd(t *testing.T) {
+	relay, pool := newTestRelay(t)
+	alice := newTestUser()
+	note := alice.publish(t, relay, 1, "worth sharing", 100)
+	s, signerPK := testsigner.New()
+
+	msg := repostCmd(signerClient(pool, s, signerPK, relay.URL), &note)()
+	published, ok := msg.(publishSuccessMsg)
+	if !ok {
+		t.Fatalf("got %v, want publishSuccessMsg", msg)
+	}
+
+	stored := relay.Events(nostr.Filter{IDs: []string{published.eventID}})
+	if len(stored) != 1 {
+		t.Fatal("repost wasn't stored")
+	}
+	repost := stored[0]
+	if repost.Kind != nostr.KindRepost || repost.PubKey != signerPK ||
+		!hasExactTag(repost.Tags, nostr.Tag{"e", note.ID}) || !hasExactTag(repost.Tags, nostr.Tag{"p", alice.pk}) {
+		t.Errorf("repost = %+v", repost)
+	}
+}
+
+// hasExactTag reports whether tags contain want exactly
+func hasExactTag(tags nostr.Tags, want nostr.Tag) bool {
+	for _, tag := range tags {
+		if strings.Join(tag, "\x00") == strings.Join(want, "\x00") {
+			return true
+		}
+	}
+	return false
+}
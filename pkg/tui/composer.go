@@ -0,0 +1,234 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nbd-wtf/go-nostr"
+	"noscli/pkg/zap"
+)
+
+// composePlaceholders are the textarea hints for each compose mode
+var composePlaceholders = map[composeMode]string{
+	composePost:  "What's on your mind? (Ctrl+S to send, Esc to cancel)",
+	composeReply: "Write your reply... (Ctrl+S to send, Esc to cancel)",
+	composeDM:    "Reply to DM... (Ctrl+S to send, Esc to cancel)",
+	composeQuote: "Add your thoughts... (Ctrl+S to send, Esc to cancel)",
+}
+
+// composeMsg asks the router to open the composer, for screens that can't
+// reach it themselves
+type composeMsg struct {
+	mode    composeMode
+	replyTo *nostr.Event
+}
+
+// composerScreen writes posts, replies, quotes and DMs
+type composerScreen struct {
+	textarea      textarea.Model
+	mode          composeMode
+	replyingTo    *nostr.Event // Event we're replying to, quoting or answering the DM of
+	recipient     string       // Who a new DM goes to, when it doesn't answer one
+	splits        []zap.Split  // Zap split tags to attach to our post
+	editingSplits bool         // Whether we're editing the zap splits
+	splitsInput   string       // Input for zap splits: "<npub|me> <weight> ..."
+}
+
+func newComposerScreen() composerScreen {
+	ta := textarea.New()
+	ta.Placeholder = "What's on your mind?"
+	ta.Focus()
+	ta.CharLimit = 5000
+	ta.SetWidth(maxCardWidth)
+	ta.SetHeight(5)
+	return composerScreen{textarea: ta}
+}
+
+// open starts writing in the given mode. replyTo is the note replied to or
+// quoted, or the DM answered, and nil for a new post.
+func (c *composerScreen) open(app *appContext, mode composeMode, replyTo *nostr.Event) {
+	app.state = stateComposing
+	c.mode = mode
+	c.replyingTo = replyTo
+	c.textarea.Placeholder = composePlaceholders[mode]
+	c.textarea.Focus()
+
+	switch {
+	case replyTo == nil:
+		app.statusMsg = "Composing new post"
+	case mode == composeQuote:
+		app.statusMsg = "Quote reposting @" + app.displayName(replyTo.PubKey)
+	default:
+		app.statusMsg = "Replying to @" + app.displayName(replyTo.PubKey)
+	}
+}
+
+// openDM starts a new DM to pubkey
+func (c *composerScreen) openDM(app *appContext, pubkey string) {
+	c.open(app, composeDM, nil)
+	c.recipient = pubkey
+	c.textarea.Placeholder = "Write your message... (Ctrl+S to send, Esc to cancel)"
+	app.statusMsg = "Messaging @" + app.displayName(pubkey)
+}
+
+// close empties the composer and goes back to the timeline
+func (c *composerScreen) close(app *appContext) {
+	app.state = stateTimeline
+	c.textarea.Reset()
+	c.replyingTo = nil
+	c.recipient = ""
+	c.splits = nil
+}
+
+// Update types into the composer, edits zap splits and sends
+func (c *composerScreen) Update(app *appContext, msg tea.KeyMsg) tea.Cmd {
+	if c.editingSplits {
+		c.updateSplits(app, msg)
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		// Cancel composing
+		c.close(app)
+		app.statusMsg = "Cancelled"
+		return nil
+	case "ctrl+o":
+		// Attach zap splits to posts, replies and quotes
+		if c.mode == composeDM {
+			return nil
+		}
+		c.editingSplits = true
+		c.splitsInput = app.zapSplitsInput(c.splits)
+		return nil
+	case "ctrl+s":
+		// Submit post
+		content := c.textarea.Value()
+		if strings.TrimSpace(content) == "" {
+			app.statusMsg = "Cannot send empty message"
+			return nil
+		}
+
+		replyTo, recipient, splits := c.replyingTo, c.recipient, c.splits
+		c.close(app)
+
+		switch c.mode {
+		case composePost:
+			app.statusMsg = "Publishing post..."
+			return publishPostCmd(app.client(), content, nil, splits)
+		case composeReply:
+			app.statusMsg = "Publishing reply..."
+			return publishPostCmd(app.client(), content, replyTo, splits)
+		case composeQuote:
+			app.statusMsg = "Publishing quote..."
+			return publishQuoteCmd(app.client(), content, replyTo, splits)
+		case composeDM:
+			if replyTo != nil {
+				recipient = replyTo.PubKey
+			}
+			if recipient == "" {
+				app.statusMsg = "Error: No DM recipient"
+				return nil
+			}
+			app.statusMsg = "Sending DM..."
+			return publishDMCmd(app.client(), recipient, content)
+		}
+		return nil
+	}
+
+	var cmd tea.Cmd
+	c.textarea, cmd = c.textarea.Update(msg)
+	return cmd
+}
+
+// updateSplits handles keys while the zap splits line is being edited
+func (c *composerScreen) updateSplits(app *appContext, msg tea.KeyMsg) {
+	switch msg.String() {
+	case "esc":
+		c.editingSplits = false
+		app.statusMsg = ""
+	case "enter":
+		relayHint := ""
+		if len(app.relays) > 0 {
+			relayHint = app.relays[0]
+		}
+		splits, err := parseZapSplits(c.splitsInput, app.pubKey, relayHint)
+		if err != nil {
+			app.statusMsg = fmt.Sprintf("⚠️ %v", err)
+			return
+		}
+		c.splits = splits
+		c.editingSplits = false
+		app.statusMsg = "Zap splits updated"
+		if len(splits) == 0 {
+			app.statusMsg = "Zap splits removed"
+		}
+	case "backspace":
+		if len(c.splitsInput) > 0 {
+			c.splitsInput = c.splitsInput[:len(c.splitsInput)-1]
+		}
+	case " ", "space":
+		c.splitsInput += " "
+	default:
+		if msg.Type == tea.KeyRunes {
+			c.splitsInput += string(msg.Runes)
+		}
+	}
+}
+
+// View draws the note being replied to or quoted, the textarea and the splits
+func (c *composerScreen) View(app *appContext) string {
+	header := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("205")).
+		Render(fmt.Sprintf("Noscli - %s", app.statusMsg))
+
+	footerText := "Ctrl+S send • Esc cancel"
+	if c.mode != composeDM {
+		footerText = "Ctrl+S send • Ctrl+O zap splits • Esc cancel"
+	}
+	footer := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Render(footerText)
+
+	// Show zap splits attached to the post
+	var splitsView string
+	if c.editingSplits {
+		splitsView = "\n" + fmt.Sprintf("⚡ Zap splits (<npub|me> <weight> ..., empty to remove): %s_", c.splitsInput)
+	} else if len(c.splits) > 0 {
+		splitsView = "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render("⚡ Zap splits: "+app.formatZapSplits(c.splits))
+	}
+
+	// Show reply context if replying or quoting
+	var contextView string
+	if c.replyingTo != nil {
+		username := app.profiles.name(c.replyingTo.PubKey)
+		if username == "" {
+			username = c.replyingTo.PubKey[:8] + "..."
+		}
+
+		// Process and truncate content for display
+		content := truncateRunes(app.processContent(c.replyingTo.Content), 200)
+
+		contextStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241")).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("63")).
+			Padding(0, 1).
+			Width(app.cardWidth())
+
+		var contextLabel string
+		if c.mode == composeQuote {
+			contextLabel = "Quoting @"
+		} else {
+			contextLabel = "Replying to @"
+		}
+
+		contextView = contextStyle.Render(fmt.Sprintf("%s%s:\n%s", contextLabel, username, content)) + "\n\n"
+	}
+
+	return fmt.Sprintf("%s\n\n%s%s%s\n\n%s", header, contextView, c.textarea.View(), splitsView, footer)
+}
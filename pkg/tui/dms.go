@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/nbd-wtf/go-nostr"
+	"noscli/pkg/client"
+)
+
+// dmResult is a decrypted DM, or why it couldn't be decrypted. Plaintext is
+// only kept in memory.
+type dmResult struct {
+	text string
+	err  error
+}
+
+// dmDecryptedMsg delivers the outcome of decrypting one DM to Update
+type dmDecryptedMsg struct {
+	id string
+	dmResult
+}
+
+// isDM reports whether evt is a NIP-04 DM (kind 4) or NIP-17 gift wrap
+// (kind 1059)
+func isDM(evt nostr.Event) bool {
+	return evt.Kind == nostr.KindEncryptedDirectMessage || evt.Kind == nostr.KindGiftWrap
+}
+
+// queueDecrypt queues the DMs among events that haven't been decrypted or
+// queued yet, in order, and starts on the queue if nothing is being
+// decrypted
+func (m *Model) queueDecrypt(events ...nostr.Event) tea.Cmd {
+	for _, evt := range events {
+		if !isDM(evt) || m.dmQueued[evt.ID] {
+			continue
+		}
+		if _, done := m.dmTexts[evt.ID]; done {
+			continue
+		}
+		m.dmQueued[evt.ID] = true
+		m.decryptQueue = append(m.decryptQueue, evt)
+	}
+	return m.nextDecrypt()
+}
+
+// nextDecrypt decrypts the next queued DM. DMs are decrypted one at a time
+// so Pleb Signer never has more than one approval prompt open.
+func (m *Model) nextDecrypt() tea.Cmd {
+	if m.decrypting || len(m.decryptQueue) == 0 {
+		return nil
+	}
+	evt := m.decryptQueue[0]
+	m.decryptQueue = m.decryptQueue[1:]
+	m.decrypting = true
+	return decryptDMCmd(m.client(), evt)
+}
+
+// storeDecrypted records a decrypted DM and moves on to the next one
+func (m *Model) storeDecrypted(msg dmDecryptedMsg) tea.Cmd {
+	m.decrypting = false
+	delete(m.dmQueued, msg.id)
+	m.dmTexts[msg.id] = msg.dmResult
+	if msg.err != nil {
+		log.Printf("⚠️  Failed to decrypt DM %s: %v", msg.id[:8], msg.err)
+	}
+	return m.nextDecrypt()
+}
+
+// retryDecrypt decrypts evt again if it failed before
+func (m *Model) retryDecrypt(evt nostr.Event) tea.Cmd {
+	if result, ok := m.dmTexts[evt.ID]; !ok || result.err == nil {
+		return nil
+	}
+	delete(m.dmTexts, evt.ID)
+	m.statusMsg = "Decrypting DM again..."
+	return m.queueDecrypt(evt)
+}
+
+// dmPlaintext returns a DM's decrypted content, a placeholder while it's
+// being decrypted, or the error if it couldn't be
+func (m *Model) dmPlaintext(evt nostr.Event) string {
+	result, ok := m.dmTexts[evt.ID]
+	var text string
+	switch {
+	case !ok:
+		return "🔐 Decrypting…"
+	case result.err == nil:
+		return result.text
+	case evt.Kind == nostr.KindGiftWrap:
+		text = fmt.Sprintf("[🎁 NIP-17 Gift-wrap - Unwrap error: %v]", result.err)
+	default:
+		text = fmt.Sprintf("[🔒 NIP-04 Encrypted - Decrypt error: %v]", result.err)
+	}
+	if retry := m.keys.hint("to retry", actRefresh); retry != "" {
+		text += " (" + retry + ")"
+	}
+	return text
+}
+
+// decryptedText returns a DM's plaintext if it has been decrypted
+func (m *Model) decryptedText(evt nostr.Event) (string, bool) {
+	result, ok := m.dmTexts[evt.ID]
+	return result.text, ok && result.err == nil
+}
+
+// decryptDMCmd decrypts a DM in the background
+func decryptDMCmd(c *client.Client, evt nostr.Event) tea.Cmd {
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				// Catch panics from the signer
+				msg = dmDecryptedMsg{id: evt.ID, dmResult: dmResult{err: fmt.Errorf("decryption panicked: %v", r)}}
+			}
+		}()
+		text, err := decryptDM(c, evt)
+		return dmDecryptedMsg{id: evt.ID, dmResult: dmResult{text: text, err: err}}
+	}
+}
+
+// decryptDM decrypts a kind 4 (NIP-04) or kind 1059 (NIP-17 gift wrap) DM
+func decryptDM(c *client.Client, evt nostr.Event) (string, error) {
+	if evt.Kind == nostr.KindGiftWrap {
+		rumor, err := c.UnwrapDM(evt)
+		if err != nil {
+			return "", err
+		}
+		return rumor.Content, nil
+	}
+
+	// NIP-04 is decrypted with the other party's pubkey: the recipient's if
+	// we sent it, the sender's otherwise
+	otherPubkey := evt.PubKey
+	if evt.PubKey == c.PubKey {
+		otherPubkey = ""
+		for _, tag := range evt.Tags {
+			if len(tag) >= 2 && tag[0] == "p" {
+				otherPubkey = tag[1]
+				break
+			}
+		}
+	}
+	if otherPubkey == "" {
+		return "", fmt.Errorf("no recipient to decrypt with")
+	}
+	return c.DecryptDM(evt.Content, otherPubkey)
+}
@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip04"
+	"noscli/pkg/config"
+)
+
+// dmFrom builds a NIP-04 DM from u to goldenMe
+func (h *uiHarness) dmFrom(u testUser, text string, ago time.Duration) nostr.Event {
+	h.t.Helper()
+	shared, _ := nip04.ComputeSharedSecret(goldenMe.pk, u.sk)
+	ciphertext, err := nip04.Encrypt(text, shared)
+	if err != nil {
+		h.t.Fatalf("Encrypt: %v", err)
+	}
+	return h.event(u, nostr.KindEncryptedDirectMessage, ciphertext, ago, nostr.Tag{"p", goldenMe.pk})
+}
+
+// update feeds msg to Update and returns its command
+func (h *uiHarness) update(msg tea.Msg) tea.Cmd {
+	h.t.Helper()
+	model, cmd := h.m.Update(msg)
+	h.m = model.(Model)
+	return cmd
+}
+
+func TestDMsDecryptOnceInBackground(t *testing.T) {
+	h := newUIHarness(t, &config.Config{AuthMethod: "nsec"})
+	h.loggedIn()
+	h.keys("tab")
+
+	dms := []nostr.Event{
+		h.dmFrom(goldenAlice, "hi from alice", time.Minute),
+		h.dmFrom(goldenBob, "hi from bob", time.Hour),
+	}
+	cmd := h.update(dmsMsg{events: dms})
+	if got := strings.Count(h.m.View(), "Decrypting…"); got != 2 {
+		t.Errorf("%d placeholders before decrypting", got)
+	}
+
+	// One DM at a time, newest first, each leading to the next
+	decrypted := 0
+	for cmd != nil {
+		msg, ok := cmd().(dmDecryptedMsg)
+		if !ok || msg.err != nil {
+			t.Fatalf("got %+v", msg)
+		}
+		decrypted++
+		cmd = h.update(msg)
+	}
+	view := h.m.View()
+	if decrypted != 2 || !strings.Contains(view, "hi from alice") || !strings.Contains(view, "hi from bob") {
+		t.Errorf("decrypted %d DMs:\n%s", decrypted, view)
+	}
+
+	// Moving around and the same DMs arriving again don't decrypt anything
+	h.keys("down", "up")
+	if cmd := h.update(dmsMsg{events: dms}); cmd != nil {
+		t.Error("DMs were queued to decrypt again")
+	}
+}
+
+func TestDMDecryptRetry(t *testing.T) {
+	// The hint and the key both come from the keymap
+	h := newUIHarness(t, &config.Config{AuthMethod: "nsec", Keys: map[string][]string{"refresh": {"u"}}})
+	h.loggedIn()
+	h.keys("tab")
+	retry := h.m.keys.keys(actRefresh)[0]
+
+	broken := h.event(goldenAlice, nostr.KindEncryptedDirectMessage, "not ciphertext", time.Minute, nostr.Tag{"p", goldenMe.pk})
+	h.update(h.update(dmsMsg{events: []nostr.Event{broken}})())
+	if view := h.m.View(); !strings.Contains(view, "Decrypt error") || !strings.Contains(view, "("+retry+" to retry)") {
+		t.Fatalf("no error shown:\n%s", h.m.View())
+	}
+
+	cmd := h.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(retry)})
+	if cmd == nil || !strings.Contains(h.m.View(), "Decrypting…") {
+		t.Fatalf("%s didn't retry:\n%s", retry, h.m.View())
+	}
+	if msg := cmd().(dmDecryptedMsg); msg.id != broken.ID || msg.err == nil {
+		t.Errorf("retry got %+v", msg)
+	}
+}
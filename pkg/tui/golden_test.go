@@ -0,0 +1,278 @@
+package tui
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/nbd-wtf/go-nostr"
+	"noscli/internal/testsigner"
+	"noscli/pkg/client"
+	"noscli/pkg/config"
+	"noscli/pkg/zap"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// goldenNow is the pinned clock for snapshots, so "5 mins ago" stays put
+var goldenNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// Fixed keys so names, npubs and event IDs are the same on every run
+var (
+	goldenMe    = goldenUser(1)
+	goldenAlice = goldenUser(2)
+	goldenBob   = goldenUser(3)
+)
+
+func goldenUser(n int) testUser {
+	sk := strings.Repeat("0", 63) + string(rune('0'+n))
+	pk, _ := nostr.GetPublicKey(sk)
+	return testUser{sk, pk}
+}
+
+// uiHarness drives a Model with messages and snapshots its View. Commands
+// returned by Update are dropped; tests feed the messages those commands
+// would have produced instead, so nothing touches the network.
+type uiHarness struct {
+	t         *testing.T
+	m         Model
+	configDir string
+}
+
+// newUIHarness returns a model on the landing screen in a 100x30 terminal,
+// with its config directory in a temp dir
+func newUIHarness(t *testing.T, cfg *config.Config) *uiHarness {
+	t.Helper()
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+
+	clock := timeNow
+	timeNow = func() time.Time { return goldenNow }
+	t.Cleanup(func() { timeNow = clock })
+
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	if cfg.Relays == nil {
+		cfg.Relays = []string{"wss://relay.example.com", "wss://nos.example.org"}
+	}
+	s, _ := testsigner.New()
+	h := &uiHarness{t: t, m: newModel(s, cfg, &config.SpendingLedger{}), configDir: configDir}
+	// Names come from the profilesMsg tests send, not from the relays
+	h.m.profiles.fetch = func(*client.Client, context.Context, []string) (map[string]*client.Profile, error) {
+		return nil, nil
+	}
+	h.send(tea.WindowSizeMsg{Width: 100, Height: 30})
+	return h
+}
+
+// send feeds messages to Update in order
+func (h *uiHarness) send(msgs ...tea.Msg) {
+	h.t.Helper()
+	for _, msg := range msgs {
+		model, _ := h.m.Update(msg)
+		h.m = model.(Model)
+	}
+}
+
+// keys presses named keys ("enter", "tab", "ctrl+s", ...) or single characters
+func (h *uiHarness) keys(keys ...string) {
+	h.t.Helper()
+	named := map[string]tea.KeyType{
+		"enter":     tea.KeyEnter,
+		"tab":       tea.KeyTab,
+		"shift+tab": tea.KeyShiftTab,
+		"esc":       tea.KeyEsc,
+		"up":        tea.KeyUp,
+		"down":      tea.KeyDown,
+		"backspace": tea.KeyBackspace,
+		"ctrl+o":    tea.KeyCtrlO,
+		"ctrl+s":    tea.KeyCtrlS,
+	}
+	for _, key := range keys {
+		if keyType, ok := named[key]; ok {
+			h.send(tea.KeyMsg{Type: keyType})
+		} else {
+			h.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		}
+	}
+}
+
+// typeText types text one character at a time
+func (h *uiHarness) typeText(text string) {
+	h.t.Helper()
+	for _, r := range text {
+		if r == ' ' {
+			h.send(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+		} else {
+			h.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+}
+
+// event builds an event by u created ago before the pinned clock
+func (h *uiHarness) event(u testUser, kind int, content string, ago time.Duration, tags ...nostr.Tag) nostr.Event {
+	h.t.Helper()
+	evt := nostr.Event{Kind: kind, Content: content, CreatedAt: nostr.Timestamp(goldenNow.Add(-ago).Unix()), Tags: tags}
+	if err := evt.Sign(u.sk); err != nil {
+		h.t.Fatalf("Sign: %v", err)
+	}
+	return evt
+}
+
+// loggedIn takes the model through nsec login to a timeline of events
+func (h *uiHarness) loggedIn(events ...nostr.Event) {
+	h.t.Helper()
+	h.send(
+		nsecAuthMsg{pubKey: goldenMe.pk, privKey: goldenMe.sk},
+		followingMsg{pubkeys: []string{goldenAlice.pk, goldenBob.pk}},
+		profilesMsg{profiles: map[string]*client.Profile{
+			goldenAlice.pk: {PubKey: goldenAlice.pk, Name: "alice"},
+			goldenBob.pk:   {PubKey: goldenBob.pk, Name: "bob"},
+		}},
+		eventsMsg{events: events},
+	)
+}
+
+// golden compares the view with testdata/<name>.golden, or rewrites it with -update
+func (h *uiHarness) golden(name string) {
+	h.t.Helper()
+	view := strings.ReplaceAll(h.m.View(), h.configDir, "$XDG_CONFIG_HOME")
+	// Trailing spaces from padding are invisible in diffs and editors strip them
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	view = strings.Join(lines, "\n") + "\n"
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			h.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(view), 0o644); err != nil {
+			h.t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		h.t.Fatalf("%v (run go test ./pkg/tui -update to create it)", err)
+	}
+	if view != string(want) {
+		h.t.Errorf("%s changed (run go test ./pkg/tui -update if that's intended)\n--- got ---\n%s\n--- want ---\n%s", path, view, want)
+	}
+}
+
+func TestLandingGolden(t *testing.T) {
+	h := newUIHarness(t, nil)
+	h.golden("landing")
+
+	h.keys("down")
+	h.golden("landing_settings_selected")
+}
+
+func TestSettingsGolden(t *testing.T) {
+	h := newUIHarness(t, &config.Config{
+		AuthMethod: "nsec",
+		Wallets: []config.Wallet{
+			{Name: "alby", NWC: "nostr+walletconnect://" + goldenAlice.pk + "?relay=wss://relay.example.com&secret=" + goldenMe.sk, MaxPerZap: 1000},
+			{Name: "spare", NWC: "nostr+walletconnect://" + goldenBob.pk + "?relay=wss://relay.example.com&secret=" + goldenMe.sk},
+		},
+		DefaultWallet: "alby",
+	})
+	h.keys("down", "enter")
+	h.golden("settings_auth")
+
+	h.keys("tab")
+	h.golden("settings_relays")
+
+	h.keys("a")
+	h.typeText("wss://new.example.net")
+	h.golden("settings_relays_adding")
+
+	h.keys("esc", "tab")
+	h.golden("settings_wallet")
+}
+
+func TestTimelineGolden(t *testing.T) {
+	h := newUIHarness(t, &config.Config{AuthMethod: "nsec"})
+	note := h.event(goldenAlice, 1, "gm nostr! https://example.com/cat.jpg", 5*time.Minute)
+	h.loggedIn(
+		note,
+		h.event(goldenBob, 1, "Building a TUI client is fun", 3*time.Hour),
+		h.event(goldenAlice, 1, "second post, mentioning bob", 2*24*time.Hour, nostr.Tag{"p", goldenBob.pk}),
+	)
+	h.golden("timeline")
+
+	h.keys("down")
+	h.golden("timeline_cursor")
+}
+
+func TestComposerGolden(t *testing.T) {
+	h := newUIHarness(t, &config.Config{AuthMethod: "nsec"})
+	h.loggedIn(h.event(goldenAlice, 1, "what are you building?", 10*time.Minute))
+
+	h.keys("c")
+	h.typeText("hello world")
+	h.golden("composer")
+
+	h.keys("ctrl+o")
+	h.typeText("me 1")
+	h.golden("composer_splits")
+
+	h.keys("esc", "esc", "R")
+	h.typeText("a terminal client")
+	h.golden("composer_reply")
+}
+
+func TestThreadGolden(t *testing.T) {
+	h := newUIHarness(t, &config.Config{AuthMethod: "nsec"})
+	root := h.event(goldenAlice, 1, "what's everyone reading?", time.Hour)
+	h.loggedIn(root)
+
+	h.keys("t")
+	h.golden("thread_loading")
+
+	h.send(threadEventsMsg{events: []nostr.Event{
+		h.event(goldenBob, 1, "the bitcoin standard", 50*time.Minute, nostr.Tag{"e", root.ID, "", "root"}),
+		h.event(goldenMe, 1, "mastering lightning", 20*time.Minute, nostr.Tag{"e", root.ID, "", "root"}),
+	}})
+	h.golden("thread")
+}
+
+func TestZapPromptGolden(t *testing.T) {
+	h := newUIHarness(t, &config.Config{AuthMethod: "nsec"})
+	h.loggedIn(h.event(goldenAlice, 1, "zap me", 15*time.Minute))
+
+	// Connect the wallet after login, which would otherwise subscribe on its relay
+	wallet := config.Wallet{Name: "alby", NWC: "nostr+walletconnect://" + goldenBob.pk + "?relay=wss://relay.example.com&secret=" + goldenMe.sk}
+	h.m.wallets, h.m.defaultWallet, h.m.nwcString = []config.Wallet{wallet}, wallet.Name, wallet.NWC
+	h.m.updateContent() // As leaving the settings screen does, making room for the zap commands
+
+	h.keys("z")
+	h.golden("zap_prompt_resolving")
+
+	h.send(zapTargetMsg{pubkey: goldenAlice.pk, target: &client.ZapTarget{
+		Pubkey:  goldenAlice.pk,
+		Address: "alice@example.com",
+		Info:    &zap.LNURLResponse{MinSendable: 1000, MaxSendable: 100_000_000, CommentAllowed: 140, AllowsNostr: true},
+	}})
+	h.keys("backspace", "backspace")
+	h.typeText("500")
+	h.golden("zap_prompt")
+
+	h.keys("enter")
+	h.typeText("great post")
+	h.golden("zap_prompt_comment")
+}
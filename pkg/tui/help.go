@@ -0,0 +1,161 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// helpOverlay lists every key binding and palette command over whatever
+// screen is showing
+type helpOverlay struct {
+	offset int // Lines scrolled past
+}
+
+// openHelp shows the help overlay
+func (m *Model) openHelp() {
+	m.help = &helpOverlay{}
+}
+
+// fixedHelp lists the keys of the screens and prompts that don't go through
+// the keymap, in help order
+var fixedHelp = []struct {
+	title    string
+	bindings []binding
+}{
+	{"Composer", []binding{
+		{keys: []string{"ctrl+s"}, help: "Send"},
+		{keys: []string{"ctrl+o"}, help: "Edit zap splits (posts, replies and quotes)"},
+		{keys: []string{"esc"}, help: "Cancel"},
+	}},
+	{"Zap prompt", []binding{
+		{keys: []string{"0-9"}, help: "Type the amount in sats"},
+		{keys: []string{"up", "down"}, help: "Step through the presets"},
+		{keys: []string{"a"}, help: "Zap anonymously"},
+		{keys: []string{"tab"}, help: "Switch wallet"},
+		{keys: []string{"enter"}, help: "Add a comment, then send"},
+		{keys: []string{"esc"}, help: "Cancel"},
+	}},
+	{"Zap dialog", []binding{
+		{keys: []string{"y", "enter"}, help: "Zap"},
+		{keys: []string{"n", "esc"}, help: "Cancel"},
+		{keys: []string{"esc"}, help: "Hide while the zap is sending"},
+		{keys: []string{"enter", "esc", "q"}, help: "Close the result"},
+	}},
+	{"Pay confirmation", []binding{
+		{keys: []string{"y"}, help: "Pay the invoice"},
+		{keys: []string{"tab"}, help: "Switch wallet"},
+		{keys: []string{"n", "esc"}, help: "Cancel"},
+	}},
+	{"Settings", []binding{
+		{keys: []string{"tab"}, help: "Switch between auth, relays and wallets"},
+		{keys: []string{"up", "k", "down", "j"}, help: "Move"},
+		{keys: []string{"enter"}, help: "Choose the auth method, or start the client"},
+		{keys: []string{"a"}, help: "Add a relay or wallet"},
+		{keys: []string{"d", "x"}, help: "Delete the selected relay or wallet"},
+		{keys: []string{"e"}, help: "Edit the wallet's NWC connection"},
+		{keys: []string{"s"}, help: "Make the wallet the default"},
+		{keys: []string{"l"}, help: "Edit the wallet's spending limits"},
+		{keys: []string{"n"}, help: "Show wallet payments in Notifications"},
+		{keys: []string{"esc", "q"}, help: "Back"},
+	}},
+	{"Command palette", []binding{
+		{keys: []string{"up", "ctrl+p"}, help: "Previous command"},
+		{keys: []string{"down", "ctrl+n"}, help: "Next command"},
+		{keys: []string{"tab"}, help: "Complete the selected command"},
+		{keys: []string{"enter"}, help: "Run it"},
+		{keys: []string{"esc"}, help: "Close the palette"},
+	}},
+}
+
+// helpLines lists the active bindings of each screen, the fixed keys of
+// the others, then the palette's commands
+func (m *Model) helpLines() []string {
+	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	lineStyle := lipgloss.NewStyle().MaxWidth(max(m.width, 1))
+
+	var lines []string
+	section := func(title string, bindings []binding) {
+		lines = append(lines, sectionStyle.Render(title))
+		for _, b := range bindings {
+			lines = append(lines, lineStyle.Render(fmt.Sprintf("  %s %s", keyStyle.Width(14).Render(keyList(b.keys)), b.help)))
+		}
+		lines = append(lines, "")
+	}
+
+	for _, screen := range []keyScreen{keysTimeline, keysThread} {
+		var active []binding
+		for _, b := range m.keys.bindings {
+			if len(b.keys) > 0 && slices.Contains(b.screens, screen) {
+				active = append(active, b)
+			}
+		}
+		name := keyScreenNames[screen]
+		section(strings.ToUpper(name[:1])+name[1:], active)
+	}
+	for _, fixed := range fixedHelp {
+		section(fixed.title, fixed.bindings)
+	}
+
+	title := "Commands"
+	if keys := m.keys.keys(actPalette); len(keys) > 0 {
+		title += " (" + keyList(keys) + ")"
+	}
+	lines = append(lines, sectionStyle.Render(title))
+	for _, c := range paletteCommands() {
+		lines = append(lines, lineStyle.Render(fmt.Sprintf("  %s %s", keyStyle.Width(40).Render(c.usage()), c.help)))
+	}
+	return lines
+}
+
+// helpHeight is how many lines of help fit between the header and footer
+func (m *Model) helpHeight() int {
+	return max(m.height-4, 1)
+}
+
+// handleHelpKey scrolls the help, or closes it
+func (m *Model) handleHelpKey(msg tea.KeyMsg) {
+	key := msg.String()
+	if key == "esc" || key == "q" || m.keys.action(keysTimeline, key) == actHelp || m.keys.action(keysThread, key) == actHelp {
+		m.help = nil
+		return
+	}
+
+	last := max(len(m.helpLines())-m.helpHeight(), 0)
+	switch m.keys.action(keysTimeline, key) {
+	case actUp:
+		m.help.offset--
+	case actDown:
+		m.help.offset++
+	case actPageUp:
+		m.help.offset -= m.helpHeight()
+	case actPageDown:
+		m.help.offset += m.helpHeight()
+	case actTop:
+		m.help.offset = 0
+	case actBottom:
+		m.help.offset = last
+	}
+	m.help.offset = min(max(m.help.offset, 0), last)
+}
+
+// renderHelp draws the help overlay full screen
+func (m *Model) renderHelp() string {
+	header := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("205")).
+		Render("Noscli - Keys and commands")
+
+	closeKeys := keyList(append([]string{"esc"}, m.keys.keys(actHelp)...))
+	footer := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Render(joinHints(m.keys.hint("scroll", actUp, actDown), closeKeys+" close"))
+
+	lines := m.helpLines()
+	end := min(m.help.offset+m.helpHeight(), len(lines))
+	return fmt.Sprintf("%s\n\n%s\n\n%s", header, strings.Join(lines[m.help.offset:end], "\n"), footer)
+}
@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"noscli/pkg/config"
+)
+
+func TestHelpGolden(t *testing.T) {
+	h := newUIHarness(t, &config.Config{AuthMethod: "nsec", Keys: map[string][]string{"compose": {"n"}}})
+	root := h.event(goldenAlice, 1, "gm", time.Minute)
+	h.loggedIn(root)
+
+	h.keys("?")
+	h.golden("help")
+
+	// It lists the active keys, and scrolls down to the thread's and the
+	// palette's commands
+	if view := h.m.View(); !strings.Contains(view, "n              Compose a new post") {
+		t.Errorf("help doesn't follow the keymap:\n%s", view)
+	}
+	h.keys("G")
+	view := h.m.View()
+	if !strings.Contains(view, "Commands (:)") || !strings.Contains(view, "search <query>") {
+		t.Errorf("bottom of the help:\n%s", view)
+	}
+
+	// Every screen and prompt has a section, including the palette's own keys
+	all := strings.Join(h.m.helpLines(), "\n")
+	for _, want := range []string{"Thread", "Composer", "ctrl+o", "Zap prompt", "Zap dialog", "Close the result", "Pay confirmation", "Settings", "Command palette", "↑/ctrl+p", "↓/ctrl+n"} {
+		if !strings.Contains(all, want) {
+			t.Errorf("help has no %q", want)
+		}
+	}
+	h.keys("esc")
+	if h.m.help != nil || h.m.state != stateTimeline {
+		t.Errorf("esc: help %v, state %d", h.m.help, h.m.state)
+	}
+
+	// The thread has it too, and closing it goes back there
+	h.keys("t", "?")
+	if h.m.help == nil {
+		t.Fatal("? didn't open the help in a thread")
+	}
+	h.keys("?")
+	if h.m.help != nil || h.m.state != stateThread {
+		t.Errorf("?: help %v, state %d", h.m.help, h.m.state)
+	}
+}
@@ -0,0 +1,256 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// action is something a key does. Actions are named in the config's "keys"
+// map, so their values are part of the config format.
+type action string
+
+const (
+	actNone       action = ""
+	actQuit       action = "quit"
+	actCompose    action = "compose"
+	actReply      action = "reply"
+	actZap        action = "zap"
+	actZapUser    action = "zap_user"
+	actPayInvoice action = "pay_invoice"
+	actSendSats   action = "send_sats"
+	actRepost     action = "repost"
+	actQuote      action = "quote"
+	actThread     action = "thread"
+	actNextColumn action = "next_column"
+	actPrevColumn action = "prev_column"
+	actMoveLeft   action = "move_column_left"
+	actMoveRight  action = "move_column_right"
+	actRefresh    action = "refresh"
+	actCompact    action = "compact"
+	actUp         action = "up"
+	actDown       action = "down"
+	actPageUp     action = "page_up"
+	actPageDown   action = "page_down"
+	actTop        action = "top"
+	actBottom     action = "bottom"
+	actOpen       action = "open"
+	actOpenLink   action = "open_link" // The nth key opens the nth link
+	actBack       action = "back"
+	actHelp       action = "help"
+	actPalette    action = "palette"
+)
+
+// keyScreen is where a binding applies. A key can do different things on
+// different screens, but only one thing on each.
+type keyScreen int
+
+const (
+	keysTimeline keyScreen = iota
+	keysThread
+)
+
+// keyScreenNames name the screens in errors and the help overlay
+var keyScreenNames = map[keyScreen]string{keysTimeline: "timeline", keysThread: "thread"}
+
+// binding is an action, the keys that trigger it and where
+type binding struct {
+	action  action
+	keys    []string
+	help    string // What it does, for the help overlay
+	screens []keyScreen
+}
+
+// defaultBindings are the keys noscli has always used, in help order
+func defaultBindings() []binding {
+	timeline := []keyScreen{keysTimeline}
+	both := []keyScreen{keysTimeline, keysThread}
+	return []binding{
+		{actCompose, []string{"c"}, "Compose a new post", timeline},
+		{actReply, []string{"R"}, "Reply to the selected post or DM", both},
+		{actZap, []string{"z"}, "Zap the selected post", timeline},
+		{actZapUser, []string{"Z"}, "Zap a user, event or lightning address", timeline},
+		{actPayInvoice, []string{"p"}, "Pay the invoice in the selected post", timeline},
+		{actSendSats, []string{"S"}, "Send sats to a lightning address", timeline},
+		{actRepost, []string{"x"}, "Repost the selected post", timeline},
+		{actQuote, []string{"X"}, "Quote the selected post", timeline},
+		{actThread, []string{"t"}, "Open the selected post's thread", timeline},
+		{actNextColumn, []string{"tab"}, "Focus the next column", timeline},
+		{actPrevColumn, []string{"shift+tab"}, "Focus the previous column", timeline},
+		{actMoveLeft, []string{"<"}, "Move the focused column left", timeline},
+		{actMoveRight, []string{">"}, "Move the focused column right", timeline},
+		{actUp, []string{"up", "k"}, "Move up", both},
+		{actDown, []string{"down", "j"}, "Move down", both},
+		{actPageUp, []string{"pgup", "b"}, "Page up", both},
+		{actPageDown, []string{"pgdown", "f", " "}, "Page down", both},
+		{actTop, []string{"g"}, "Go to the top", both},
+		{actBottom, []string{"G"}, "Go to the bottom", both},
+		{actRefresh, []string{"r"}, "Refresh, or retry decrypting the selected DM", timeline},
+		{actOpen, []string{"enter"}, "Open the first link in the selected post", timeline},
+		{actOpenLink, []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"}, "Open the selected post's nth link", timeline},
+		{actCompact, []string{"v"}, "Switch between cards and one line per note", timeline},
+		{actBack, []string{"esc", "q"}, "Back to the timeline", []keyScreen{keysThread}},
+		{actPalette, []string{":"}, "Open the command palette", timeline},
+		{actHelp, []string{"?"}, "Show every key and command", both},
+		{actQuit, []string{"q", "ctrl+c"}, "Quit", timeline},
+	}
+}
+
+// keymap resolves keys to actions on each screen
+type keymap struct {
+	bindings []binding
+	actions  map[keyScreen]map[string]action
+}
+
+// newKeymap returns the default bindings with the keys of the actions in
+// overrides replaced. An empty list unbinds an action. Unknown actions and
+// keys bound to two actions on one screen are errors.
+func newKeymap(overrides map[string][]string) (*keymap, error) {
+	bindings := defaultBindings()
+	known := make(map[action]*binding, len(bindings))
+	for i := range bindings {
+		known[bindings[i].action] = &bindings[i]
+	}
+
+	var errs []string
+	for name, keys := range overrides {
+		b, ok := known[action(name)]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("unknown action %q", name))
+			continue
+		}
+		b.keys = make([]string, len(keys))
+		for i, key := range keys {
+			b.keys[i] = normalizeKey(key)
+		}
+	}
+
+	k := &keymap{bindings: bindings, actions: make(map[keyScreen]map[string]action)}
+	for _, b := range bindings {
+		for _, screen := range b.screens {
+			if k.actions[screen] == nil {
+				k.actions[screen] = make(map[string]action)
+			}
+			for _, key := range b.keys {
+				if other, taken := k.actions[screen][key]; taken && other != b.action {
+					errs = append(errs, fmt.Sprintf("%s is bound to both %s and %s on the %s", keyName(key), other, b.action, keyScreenNames[screen]))
+					continue
+				}
+				k.actions[screen][key] = b.action
+			}
+		}
+	}
+	if len(errs) > 0 {
+		// Map order is random; keep the message stable
+		sort.Strings(errs)
+		return nil, fmt.Errorf("invalid keys: %s", strings.Join(errs, "; "))
+	}
+	return k, nil
+}
+
+// action returns what key does on screen, or actNone
+func (k *keymap) action(screen keyScreen, key string) action {
+	return k.actions[screen][key]
+}
+
+// keys returns the keys bound to a
+func (k *keymap) keys(a action) []string {
+	for _, b := range k.bindings {
+		if b.action == a {
+			return b.keys
+		}
+	}
+	return nil
+}
+
+// linkIndex returns which link (from 0) key opens, or -1
+func (k *keymap) linkIndex(key string) int {
+	for i, bound := range k.keys(actOpenLink) {
+		if bound == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// hint returns a footer hint for actions, e.g. "↑/k ↓/j nav". The keys of
+// each action are joined by "/", and the actions by "/" too when each has
+// a single key.
+func (k *keymap) hint(label string, actions ...action) string {
+	var parts []string
+	single := true
+	for _, a := range actions {
+		keys := k.keys(a)
+		if len(keys) == 0 {
+			continue
+		}
+		single = single && len(keys) == 1
+		parts = append(parts, keyList(keys))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	sep := " "
+	if single {
+		sep = "/"
+	}
+	return strings.Join(parts, sep) + " " + label
+}
+
+// joinHints joins footer hints, leaving out those of unbound actions
+func joinHints(hints ...string) string {
+	var shown []string
+	for _, hint := range hints {
+		if hint != "" {
+			shown = append(shown, hint)
+		}
+	}
+	return strings.Join(shown, " • ")
+}
+
+// keyList shows keys joined by "/", with runs of digits as ranges
+func keyList(keys []string) string {
+	if len(keys) > 2 {
+		digits := true
+		for i, key := range keys {
+			digits = digits && len(key) == 1 && key[0] >= '0' && key[0] <= '9' && (i == 0 || key[0] == keys[i-1][0]+1)
+		}
+		if digits {
+			return keys[0] + "-" + keys[len(keys)-1]
+		}
+	}
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = keyName(key)
+	}
+	return strings.Join(names, "/")
+}
+
+// keyNames are how keys with symbols or long names are shown
+var keyNames = map[string]string{
+	" ":      "space",
+	"up":     "↑",
+	"down":   "↓",
+	"pgup":   "pgup",
+	"pgdown": "pgdn",
+}
+
+// keyName returns how key is shown in hints
+func keyName(key string) string {
+	if name, ok := keyNames[key]; ok {
+		return name
+	}
+	return key
+}
+
+// normalizeKey turns a key as written in the config into how Bubble Tea
+// names it
+func normalizeKey(key string) string {
+	switch key {
+	case "space":
+		return " "
+	case "pgdn":
+		return "pgdown"
+	}
+	return key
+}
@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"noscli/pkg/config"
+)
+
+func TestDefaultKeymap(t *testing.T) {
+	k, err := newKeymap(nil)
+	if err != nil {
+		t.Fatalf("newKeymap: %v", err)
+	}
+	for _, tc := range []struct {
+		screen keyScreen
+		key    string
+		want   action
+	}{
+		{keysTimeline, "c", actCompose},
+		{keysTimeline, "q", actQuit},
+		{keysTimeline, " ", actPageDown},
+		{keysTimeline, "shift+tab", actPrevColumn},
+		{keysThread, "q", actBack},
+		{keysThread, "R", actReply},
+		{keysThread, "c", actNone},
+	} {
+		if got := k.action(tc.screen, tc.key); got != tc.want {
+			t.Errorf("%s on the %s: %q, want %q", tc.key, keyScreenNames[tc.screen], got, tc.want)
+		}
+	}
+	if got := k.linkIndex("3"); got != 2 {
+		t.Errorf("linkIndex(3) = %d", got)
+	}
+	if got := k.hint("nav", actUp, actDown); got != "↑/k ↓/j nav" {
+		t.Errorf("nav hint %q", got)
+	}
+	if got := k.hint("top/bot", actTop, actBottom); got != "g/G top/bot" {
+		t.Errorf("top/bot hint %q", got)
+	}
+}
+
+func TestKeymapOverrides(t *testing.T) {
+	k, err := newKeymap(map[string][]string{"compose": {"n"}, "page_down": {"space", "ctrl+d"}, "zap": {}})
+	if err != nil {
+		t.Fatalf("newKeymap: %v", err)
+	}
+	if k.action(keysTimeline, "n") != actCompose || k.action(keysTimeline, "c") != actNone {
+		t.Error("compose wasn't moved to n")
+	}
+	if k.action(keysTimeline, " ") != actPageDown || k.action(keysThread, "ctrl+d") != actPageDown || k.action(keysTimeline, "f") != actNone {
+		t.Error("page_down wasn't rebound")
+	}
+	if k.hint("zap", actZap) != "" {
+		t.Error("unbound zap still has a hint")
+	}
+
+	// q backs out of a thread, so it can't also be reply there, but it can on
+	// the timeline once quit moves
+	_, err = newKeymap(map[string][]string{"reply": {"q"}, "bogus": {"x"}})
+	if err == nil || !strings.Contains(err.Error(), `unknown action "bogus"`) || !strings.Contains(err.Error(), "q is bound to both") {
+		t.Errorf("conflicts: %v", err)
+	}
+	if _, err := newKeymap(map[string][]string{"compose": {"q"}, "quit": {"ctrl+c"}}); err != nil {
+		t.Errorf("q free on the timeline: %v", err)
+	}
+}
+
+func TestKeymapFromConfig(t *testing.T) {
+	h := newUIHarness(t, &config.Config{AuthMethod: "nsec", Keys: map[string][]string{"compose": {"n"}}})
+	h.loggedIn(h.event(goldenAlice, 1, "gm", time.Minute))
+	if footer := h.m.renderFooter(); !strings.Contains(footer, "n compose") || strings.Contains(footer, "c compose") {
+		t.Errorf("footer doesn't follow the keymap:\n%s", footer)
+	}
+	h.keys("n")
+	if h.m.state != stateComposing {
+		t.Errorf("n didn't open the composer (state %d)", h.m.state)
+	}
+
+	// Overrides are saved back as they were
+	h.m.saveConfig()
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := cfg.Keys["compose"]; len(got) != 1 || got[0] != "n" {
+		t.Errorf("saved keys %v", cfg.Keys)
+	}
+
+	// A broken keymap falls back to the defaults and says why
+	h = newUIHarness(t, &config.Config{AuthMethod: "nsec", Keys: map[string][]string{"compose": {"z"}}})
+	if h.m.keys.action(keysTimeline, "c") != actCompose || !strings.Contains(h.m.statusMsg, "z is bound to both") {
+		t.Errorf("no fallback: %q", h.m.statusMsg)
+	}
+}
@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// landingScreen is the start screen, before the client connects
+type landingScreen struct {
+	choice int // 0 = Open Client, 1 = Settings
+}
+
+// Update moves the menu selection and opens the client or Settings
+func (l *landingScreen) Update(app *appContext, msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return tea.Quit
+	case "up", "k":
+		l.choice = 0
+	case "down", "j":
+		l.choice = 1
+	case "enter":
+		if l.choice == 0 {
+			// Open Client - check if auth method is set
+			if app.authMethod == "" {
+				// Force user to settings to choose auth
+				app.state = stateSettings
+				app.statusMsg = "Please choose an authentication method first"
+			} else {
+				return app.connect()
+			}
+		} else {
+			// Go to Settings
+			app.state = stateSettings
+		}
+	}
+	return nil
+}
+
+// View draws the title, links and the Open Client / Settings menu
+func (l *landingScreen) View(app *appContext) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("205")).
+		Align(lipgloss.Center).
+		Width(app.width)
+
+	versionStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Align(lipgloss.Center).
+		Width(app.width)
+
+	authorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("214")).
+		Align(lipgloss.Center).
+		Width(app.width)
+
+	linkStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("63")).
+		Align(lipgloss.Center).
+		Width(app.width)
+
+	menuStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252")).
+		Align(lipgloss.Center).
+		Width(app.width)
+
+	selectedStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("205")).
+		Background(lipgloss.Color("236")).
+		Align(lipgloss.Center).
+		Width(app.width)
+
+	var content strings.Builder
+	content.WriteString("\n\n")
+	content.WriteString(titleStyle.Render("╔═══════════════════════════════════╗"))
+	content.WriteString("\n")
+	content.WriteString(titleStyle.Render("║                                   ║"))
+	content.WriteString("\n")
+	content.WriteString(titleStyle.Render("║            N O S C L I            ║"))
+	content.WriteString("\n")
+	content.WriteString(titleStyle.Render("║                                   ║"))
+	content.WriteString("\n")
+	content.WriteString(titleStyle.Render("╚═══════════════════════════════════╝"))
+	content.WriteString("\n\n")
+	content.WriteString(versionStyle.Render("Version " + Version))
+	content.WriteString("\n\n")
+	content.WriteString(authorStyle.Render("Made by Tim from Pleb.one"))
+	content.WriteString("\n\n")
+	content.WriteString(linkStyle.Render("🔗 Pleb Signer: https://github.com/PlebOne/Pleb_Signer"))
+	content.WriteString("\n")
+	content.WriteString(linkStyle.Render("🔗 Noscli: https://github.com/PlebOne/Noscli"))
+	content.WriteString("\n\n\n")
+
+	if l.choice == 0 {
+		content.WriteString(selectedStyle.Render("► Open Client"))
+		content.WriteString("\n")
+		content.WriteString(menuStyle.Render("  Settings"))
+	} else {
+		content.WriteString(menuStyle.Render("  Open Client"))
+		content.WriteString("\n")
+		content.WriteString(selectedStyle.Render("► Settings"))
+	}
+
+	content.WriteString("\n\n\n")
+	content.WriteString(versionStyle.Render("Use ↑/↓ or j/k to navigate • Enter to select • q to quit"))
+
+	return content.String()
+}
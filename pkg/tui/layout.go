@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Note cards and the composer follow the terminal's width within these
+// bounds (lipgloss widths: text and padding, without the border). Much
+// wider lines are hard to read.
+const (
+	minCardWidth = 30
+	maxCardWidth = 80
+)
+
+// compactWidth is the terminal width below which the timeline switches to
+// one line per note
+const compactWidth = 60
+
+// cardWidth returns the width of note cards and the composer for the
+// terminal
+func (a *appContext) cardWidth() int {
+	return cardWidthFor(a.width)
+}
+
+// cardWidthFor returns the width of note cards in a width-column space.
+// Spaces too narrow for minCardWidth get cards that fill them instead of
+// cards that spill over.
+func cardWidthFor(width int) int {
+	if width-2 < minCardWidth {
+		return max(width-2, 1)
+	}
+	return min(width-2, maxCardWidth)
+}
+
+// truncateRunes cuts text to at most n characters, marking the cut with
+// "...". It counts runes so a cut never splits a character.
+func truncateRunes(text string, n int) string {
+	if runes := []rune(text); len(runes) > n {
+		return string(runes[:n]) + "..."
+	}
+	return text
+}
+
+// resize lays the screens out for a width x height terminal. The timeline
+// goes compact when the terminal becomes narrower than compactWidth and
+// back when it widens again; 'v' switches in between.
+func (m *Model) resize(width, height int) {
+	if !m.ready || (width < compactWidth) != (m.width < compactWidth) {
+		m.compact = width < compactWidth
+	}
+	m.width, m.height = width, height
+	m.composer.textarea.SetWidth(m.cardWidth())
+
+	if !m.ready {
+		m.viewport = viewport.New(width, height)
+		m.viewport.YPosition = 3
+		m.thread.viewport = viewport.New(width, height)
+		m.ready = true
+	}
+	m.fitBody()
+}
+
+// fitBody sizes the viewports to what the status line, tabs and footer
+// leave free. The footer grows and shrinks with the commands available, so
+// this runs whenever the content is updated.
+func (m *Model) fitBody() {
+	// Status line, tabs and a blank line above the body; a blank line and
+	// the footer below it
+	bodyHeight := max(m.height-4-lipgloss.Height(m.renderFooter()), 1)
+	width := m.columnWidth()
+	m.viewport.Width, m.viewport.Height = width, bodyHeight
+	for i := range m.columns {
+		if i != m.focus {
+			m.columns[i].state.viewport.Width, m.columns[i].state.viewport.Height = width, bodyHeight
+		}
+	}
+	m.thread.viewport.Width, m.thread.viewport.Height = m.width, bodyHeight
+}
+
+// renderCompact renders evt as a single line: its header and as much of its
+// text as fits
+func (m *Model) renderCompact(evt nostr.Event, selected bool) string {
+	text := strings.Join(strings.Fields(m.processContent(m.eventText(evt))), " ")
+	line := m.eventHeader(evt, selected) + "  " + text
+	if width := m.viewport.Width; lipgloss.Width(line) > width {
+		line = lipgloss.NewStyle().MaxWidth(max(width-1, 1)).Render(line) + "…"
+	}
+	if selected {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Render(line)
+	}
+	return line
+}
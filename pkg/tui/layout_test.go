@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"noscli/pkg/config"
+)
+
+// widest returns the width of the widest line of s
+func widest(s string) int {
+	w := 0
+	for _, line := range strings.Split(s, "\n") {
+		w = max(w, lipgloss.Width(line))
+	}
+	return w
+}
+
+func TestLayoutFollowsWidth(t *testing.T) {
+	h := newUIHarness(t, &config.Config{AuthMethod: "nsec"})
+	h.loggedIn(
+		h.event(goldenAlice, 1, "see https://example.com/"+strings.Repeat("long-path/", 20)+" for details", time.Minute),
+		h.event(goldenBob, 1, strings.Repeat("日本語のテキストと絵文字🎉", 12), time.Hour),
+	)
+
+	for _, width := range []int{60, 100} {
+		h.send(tea.WindowSizeMsg{Width: width, Height: 30})
+		view := h.m.View()
+		if got := widest(view); got > width {
+			t.Errorf("%d columns: the view is %d wide:\n%s", width, got, view)
+		}
+		border := strings.TrimRight(strings.Split(h.m.viewport.View(), "\n")[0], " ")
+		if got, want := lipgloss.Width(border), min(width, maxCardWidth+2); !strings.HasPrefix(border, "╭") || got != want {
+			t.Errorf("%d columns: cards are %d wide, want %d", width, got, want)
+		}
+	}
+
+	// The composer follows along too
+	h.send(tea.WindowSizeMsg{Width: 50, Height: 30})
+	h.keys("c")
+	if got := h.m.composer.textarea.Width(); got > 50 {
+		t.Errorf("composer is %d wide in 50 columns", got)
+	}
+}
+
+func TestCardWidthFor(t *testing.T) {
+	for width, want := range map[int]int{200: maxCardWidth, 82: 80, 60: 58, 32: 30, 20: 18, 2: 1, 0: 1} {
+		if got := cardWidthFor(width); got != want {
+			t.Errorf("cardWidthFor(%d) = %d, want %d", width, got, want)
+		}
+	}
+}
+
+func TestCompactLayout(t *testing.T) {
+	h := newUIHarness(t, &config.Config{AuthMethod: "nsec"})
+	h.loggedIn(
+		h.event(goldenAlice, 1, "gm\nfrens", time.Minute),
+		h.event(goldenBob, 1, strings.Repeat("a rather long note ", 10), time.Hour),
+	)
+
+	// Narrow terminals get one line per note
+	h.send(tea.WindowSizeMsg{Width: 50, Height: 20})
+	if !h.m.compact {
+		t.Fatal("not compact at 50 columns")
+	}
+	lines := strings.Split(strings.TrimRight(h.m.viewport.View(), " \n"), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "@alice") || !strings.HasSuffix(strings.TrimSpace(lines[0]), "gm frens") ||
+		!strings.Contains(lines[1], "@bob") || !strings.HasSuffix(strings.TrimSpace(lines[1]), "…") {
+		t.Errorf("compact timeline:\n%s", strings.Join(lines, "\n"))
+	}
+	if got := widest(h.m.View()); got > 50 {
+		t.Errorf("compact view is %d wide", got)
+	}
+	h.keys("down")
+	if !strings.Contains(h.m.viewport.View(), "> 🔵 @bob") {
+		t.Errorf("cursor isn't on bob:\n%s", h.m.viewport.View())
+	}
+
+	// v switches to cards even when narrow, and widening goes back to cards
+	h.keys("v")
+	if h.m.compact || !strings.Contains(h.m.viewport.View(), "╭") {
+		t.Error("v didn't switch to cards")
+	}
+	h.keys("v")
+	h.send(tea.WindowSizeMsg{Width: 100, Height: 20})
+	if h.m.compact {
+		t.Error("still compact at 100 columns")
+	}
+}
@@ -10,19 +10,15 @@ import (
 	"os"
 	"os/exec"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/nbd-wtf/go-nostr"
-	"github.com/nbd-wtf/go-nostr/nip04"
 	"github.com/nbd-wtf/go-nostr/nip19"
-	"github.com/nbd-wtf/go-nostr/nip44"
-	"github.com/nbd-wtf/go-nostr/nip59"
+	"noscli/pkg/client"
 	"noscli/pkg/config"
 	"noscli/pkg/nwc"
 	"noscli/pkg/signer"
@@ -59,15 +55,14 @@ const (
 	viewFollowing viewMode = iota
 	viewDMs
 	viewNotifications
+	viewFeed // A custom feed column
 )
 
 type Model struct {
-	state         sessionState
-	currentView   viewMode
-	signer        *signer.PlebSigner
-	pubKey        string
-	privKey       string             // Private key if using nsec auth
-	npub          string
+	*appContext
+	currentView   viewMode           // What the focused column shows
+	columns       []column           // Timeline columns, as tabs or side by side
+	focus         int                // Index of the focused column
 	events        []nostr.Event      // Timeline events
 	dms           []nostr.Event      // DM events
 	notifications []nostr.Event      // Notification events
@@ -76,50 +71,43 @@ type Model struct {
 	viewport      viewport.Model
 	ready         bool
 	err           error
-	width         int
-	height        int
-	statusMsg     string
-	pool          *nostr.SimplePool
-	relays        []string
-	eventLines    []int              // Track which line each event starts at
-	userCache     map[string]string  // pubkey -> display name
+	rendered      map[renderKey]renderedEvent // Events rendered since the last updateContent
+	top           int                // Index of the event at the top of the viewport
+	topLine       int                // Lines of the top event scrolled out of view
 	readDMs       map[string]bool    // DM event IDs that have been read
 	readNotifs    map[string]bool    // Notification event IDs that have been read
+	compact       bool               // Whether the timeline shows one line per note
+	help          *helpOverlay       // Keys and commands, shown over every screen
+	// DM decryption, one DM at a time in the background
+	dmTexts       map[string]dmResult // DM event ID -> plaintext or why decrypting failed
+	dmQueued      map[string]bool    // DMs waiting to be decrypted
+	decryptQueue  []nostr.Event      // DMs to decrypt, in order
+	decrypting    bool               // Whether a DM is being decrypted
 	lastEventTime nostr.Timestamp    // Latest event timestamp for refresh
 	lastDMTime    nostr.Timestamp    // Latest DM timestamp for refresh
 	lastNotifTime nostr.Timestamp    // Latest notification timestamp for refresh
-	// Compose mode
-	textarea      textarea.Model
-	composing     composeMode
-	replyingTo    *nostr.Event       // Event we're replying to
-	// Thread view
-	threadRoot    *nostr.Event       // Root event of thread being viewed
-	threadEvents  []nostr.Event      // All events in thread
-	// Landing/Settings
-	landingChoice int                // 0 = Open Client, 1 = Settings
-	settingsMenu  int                // 0 = Auth, 1 = Relays, 2 = Wallet
-	settingsCursor int               // Which item is selected in settings
-	editingRelay  bool               // Whether we're editing a relay
-	newRelayInput string             // Input for new relay
-	// Auth settings
-	authMethod    string             // "pleb_signer" or "nsec" or ""
-	nsecKey       string             // User's nsec key if authMethod is "nsec"
-	editingNsec   bool               // Whether we're editing nsec input
-	// Wallet settings
-	nwcString     string             // Nostr Wallet Connect connection string
-	editingNWC    bool               // Whether we're editing NWC input
-	// Zapping
-	zapAmount     string             // Amount input for zapping
-	zappingEvent  *nostr.Event       // Event being zapped
-	editingZapAmt bool               // Whether we're entering zap amount
+	// Screens
+	landing       landingScreen
+	settings      settingsScreen
+	composer      composerScreen
+	thread        threadScreen
+	// Prompts and dialogs over the timeline; app.overlay says which is open
+	palette       paletteScreen
+	zapPrompt     zapPromptScreen
+	zapDialog     zapDialogScreen
+	payConfirm    payConfirmScreen
+	sendSats      sendSatsScreen
 	// Subscription management
 	activeSubCtx  context.Context    // Context for active subscriptions
 	cancelSubs    context.CancelFunc  // Function to cancel all active subscriptions
-	// NWC persistent subscription
-	nwcActive       bool                             // Whether NWC subscription is active
-	nwcResponseChan chan nostr.RelayEvent            // Channel for NWC responses
-	nwcCancelFunc   context.CancelFunc               // Cancel function for NWC subscription
-	pendingPayments map[string]chan *nostr.Event     // Map of request ID -> response channel
+	// Wallet notifications (NIP-47 kinds 23196/23197)
+	walletNotifText    map[string]string   // Notification event ID -> rendered summary
+	// Zap receipts (NIP-57 kind 9735)
+	zapReceipts       map[string]*zapReceiptInfo // Receipt event ID -> parsed receipt and validity
+	zapTotals         map[string]zapTotal        // Note ID -> sum of valid zaps
+	zapperKeys        map[string]string          // Recipient pubkey -> their LNURL server's nostrPubkey
+	zapperKeysPending map[string]bool            // Recipients whose LNURL key is being fetched
+	awaitingReceipt   string                     // Note we zapped and are waiting for a receipt on
 }
 
 // nwcResponseMsg wraps an NWC response event
@@ -130,14 +118,6 @@ type nwcResponseMsg struct {
 func NewModel() Model {
 	s, err := signer.NewPlebSigner()
 	
-	// Initialize textarea for composing
-	ta := textarea.New()
-	ta.Placeholder = "What's on your mind?"
-	ta.Focus()
-	ta.CharLimit = 5000
-	ta.SetWidth(60)
-	ta.SetHeight(5)
-	
 	// Load saved config
 	cfg, cfgErr := config.Load()
 	if cfgErr != nil {
@@ -147,31 +127,31 @@ func NewModel() Model {
 		}
 	}
 	
-	m := Model{
-		state:       stateLanding,
-		currentView: viewFollowing,
-		signer:      s,
-		pool:        nostr.NewSimplePool(context.Background()),
-		relays:      cfg.Relays,
-		cursor:      0,
-		userCache:   make(map[string]string),
-		readDMs:     make(map[string]bool),
-		readNotifs:  make(map[string]bool),
-		textarea:    ta,
-		landingChoice: 0,
-		authMethod:  cfg.AuthMethod,
-		nsecKey:     cfg.Nsec,
-		nwcString:   cfg.NWC,
-		nwcResponseChan: make(chan nostr.RelayEvent, 10),
-		pendingPayments: make(map[string]chan *nostr.Event),
+	spending, spendErr := config.LoadSpending()
+	if spendErr != nil {
+		// Non-fatal, budgets just start from zero
+		log.Printf("⚠️  Failed to load spending ledger: %v", spendErr)
+		spending = &config.SpendingLedger{}
 	}
+	
+	m := newModel(s, cfg, spending)
 	if err != nil {
 		m.state = stateError
 		m.err = err
 	}
 	
+	// Cached names show up before the relays answer
+	if path, pathErr := config.GetProfilesPath(); pathErr != nil {
+		log.Printf("⚠️  No profile cache: %v", pathErr)
+	} else if profiles, loadErr := client.LoadProfileCache(path); loadErr != nil {
+		// Non-fatal, profiles are fetched again
+		log.Printf("⚠️  Failed to load profile cache: %v", loadErr)
+	} else {
+		m.profiles.useCache(profiles, path)
+	}
+	
 	// Start NWC subscription if wallet is configured
-	if cfg.NWC != "" {
+	if m.nwcString != "" {
 		log.Printf("🏁 [NWC] NWC configured in config, starting subscription...")
 		m.startNWCSubscription()
 		log.Printf("🏁 [NWC] Back from startNWCSubscription, nwcActive=%v", m.nwcActive)
@@ -180,125 +160,36 @@ func NewModel() Model {
 	return m
 }
 
+// newModel builds the landing screen model from loaded settings, without
+// touching D-Bus, the config directory or the network
+func newModel(s *signer.PlebSigner, cfg *config.Config, spending *config.SpendingLedger) Model {
+	columns := newColumns(cfg.Columns)
+	return Model{
+		appContext:  newAppContext(s, cfg, spending),
+		currentView: columns[0].view,
+		columns:     columns,
+		cursor:      0,
+		readDMs:     make(map[string]bool),
+		readNotifs:  make(map[string]bool),
+		rendered:    make(map[renderKey]renderedEvent),
+		dmTexts:     make(map[string]dmResult),
+		dmQueued:    make(map[string]bool),
+		composer:    newComposerScreen(),
+		walletNotifText: make(map[string]string),
+		zapReceipts: make(map[string]*zapReceiptInfo),
+		zapTotals:   make(map[string]zapTotal),
+		zapperKeys:  make(map[string]string),
+		zapperKeysPending: make(map[string]bool),
+	}
+}
+
 func (m Model) Init() tea.Cmd {
 	if m.state == stateError {
 		return nil
 	}
 	// Start at landing screen, don't connect yet
 	// NWC subscription was already started in NewModel if configured
-	return waitForNWCResponse(m.nwcResponseChan)
-}
-
-// startNWCSubscription starts listening for NWC responses on the wallet relay
-func (m *Model) startNWCSubscription() {
-	if m.nwcString == "" {
-		return
-	}
-	
-	// Stop existing subscription if any
-	m.stopNWCSubscription()
-	
-	log.Printf("🔌 [NWC] Starting persistent NWC subscription...")
-	
-	// Parse NWC string
-	walletPubkey, relay, _, err := nwc.ParseNWCString(m.nwcString)
-	if err != nil {
-		log.Printf("❌ [NWC] Failed to parse NWC string: %v", err)
-		return
-	}
-	
-	log.Printf("✅ [NWC] Wallet: %s, Relay: %s", walletPubkey[:8], relay)
-	log.Printf("🔑 [NWC] Full wallet pubkey: %s", walletPubkey)
-	if m.pubKey != "" {
-		log.Printf("🔑 [NWC] Our pubkey: %s", m.pubKey)
-	} else {
-		log.Printf("⚠️  [NWC] Our pubkey not set yet (will accept all events)")
-	}
-	
-	// Create context for this subscription
-	ctx, cancel := context.WithCancel(context.Background())
-	m.nwcCancelFunc = cancel
-	
-	// Subscribe to kind 23195 events from wallet (no 'p' filter for compatibility)
-	filters := []nostr.Filter{{
-		Kinds:   []int{23195},
-		Authors: []string{walletPubkey},
-	}}
-	
-	log.Printf("📡 [NWC] Subscribing to relay: %s", relay)
-	if m.pubKey != "" {
-		log.Printf("🔍 [NWC] Filter: kinds=[23195], authors=[%s] (will check p=%s in code)", walletPubkey[:8], m.pubKey[:8])
-	} else {
-		log.Printf("🔍 [NWC] Filter: kinds=[23195], authors=[%s] (no p-tag check yet)", walletPubkey[:8])
-	}
-	responseChan := m.pool.SubMany(ctx, []string{relay}, filters)
-	m.nwcActive = true
-	log.Printf("✅ [NWC] SubMany returned, nwcActive set to true")
-	
-	// Start goroutine to forward events to our channel
-	go func() {
-		defer log.Printf("🛑 [NWC] Goroutine exiting")
-		log.Printf("👂 [NWC] Listening goroutine started, waiting for events...")
-		eventCount := 0
-		for {
-			select {
-			case event, ok := <-responseChan:
-				eventCount++
-				log.Printf("📬 [NWC] Received something from channel (count=%d, ok=%v)", eventCount, ok)
-				if !ok {
-					log.Printf("❌ [NWC] Response channel closed")
-					return
-				}
-				if event.Event != nil {
-					log.Printf("📨 [NWC] Event details: ID=%s, kind=%d, author=%s, created=%d", 
-						event.Event.ID[:8], event.Event.Kind, event.Event.PubKey[:8], event.Event.CreatedAt)
-					log.Printf("📋 [NWC] Event tags: %v", event.Event.Tags)
-					
-					// Check if it's for us (only if we have a pubkey)
-					isForUs := (m.pubKey == "") // Accept all if we don't have pubkey yet
-					if m.pubKey != "" {
-						for _, tag := range event.Event.Tags {
-							if len(tag) >= 2 && tag[0] == "p" && tag[1] == m.pubKey {
-								isForUs = true
-								break
-							}
-						}
-					}
-					log.Printf("🎯 [NWC] Event is for us: %v (our pubkey=%s)", isForUs, func() string {
-						if m.pubKey != "" {
-							return m.pubKey[:8]
-						}
-						return "(not set)"
-					}())
-					
-					// Forward to main channel regardless (we'll filter in handler)
-					select {
-					case m.nwcResponseChan <- event:
-						log.Printf("✅ [NWC] Forwarded to main channel")
-					default:
-						log.Printf("⚠️  [NWC] Response channel full, dropping event")
-					}
-				} else {
-					log.Printf("⚠️  [NWC] Received event with nil Event field")
-				}
-			case <-ctx.Done():
-				log.Printf("🛑 [NWC] Subscription stopped")
-				return
-			}
-		}
-	}()
-	
-	log.Printf("✅ [NWC] Persistent subscription active")
-}
-
-// stopNWCSubscription stops the NWC subscription
-func (m *Model) stopNWCSubscription() {
-	if m.nwcCancelFunc != nil {
-		log.Printf("🛑 [NWC] Stopping NWC subscription...")
-		m.nwcCancelFunc()
-		m.nwcCancelFunc = nil
-	}
-	m.nwcActive = false
+	return tea.Batch(waitForNWCResponse(m.nwcResponseChan), waitForProfiles(m.profiles.updates))
 }
 
 // waitForNWCResponse returns a Cmd that waits for NWC responses
@@ -309,70 +200,25 @@ func waitForNWCResponse(responseChan chan nostr.RelayEvent) tea.Cmd {
 	}
 }
 
+// updateContent re-renders the visible events after something they're
+// rendered from changed
 func (m *Model) updateContent() {
-	var content strings.Builder
-	
-	// Thread view rendering
-	if m.state == stateThread && m.threadRoot != nil {
-		// Render root post
-		content.WriteString(lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("214")).
-			Render("━━━ Thread Root ━━━"))
-		content.WriteString("\n\n")
-		content.WriteString(m.renderEvent(*m.threadRoot, false))
-		content.WriteString("\n\n")
-		
-		if len(m.threadEvents) > 0 {
-			content.WriteString(lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("214")).
-				Render(fmt.Sprintf("━━━ Replies (%d) ━━━", len(m.threadEvents))))
-			content.WriteString("\n\n")
-			
-			for _, evt := range m.threadEvents {
-				content.WriteString(m.renderEvent(evt, false))
-				content.WriteString("\n")
-			}
-		} else {
-			content.WriteString(lipgloss.NewStyle().
-				Foreground(lipgloss.Color("241")).
-				Render("No replies yet"))
-			content.WriteString("\n")
-		}
-		
-		m.viewport.SetContent(content.String())
-		return
-	}
+	clear(m.rendered)
+	m.fitBody()
 	
-	// Get the current event list based on view
-	var currentEvents []nostr.Event
-	switch m.currentView {
-	case viewFollowing:
-		currentEvents = m.events
-	case viewDMs:
-		currentEvents = m.dms
-	case viewNotifications:
-		currentEvents = m.notifications
+	if m.state == stateThread && m.thread.root != nil {
+		m.thread.render(func(evt nostr.Event) string { return m.renderEvent(evt, false, m.width) })
+		return
 	}
 	
-	m.eventLines = make([]int, len(currentEvents))
-	currentLine := 0
-	
-	if len(currentEvents) == 0 {
-		content.WriteString("No posts yet. Press 'r' to refresh.\n")
-	} else {
-		for i, evt := range currentEvents {
-			m.eventLines[i] = currentLine
-			rendered := m.renderEvent(evt, i == m.cursor)
-			content.WriteString(rendered)
-			content.WriteString("\n")
-			// Count lines in rendered event
-			currentLine += strings.Count(rendered, "\n") + 2 // +2 for the newline after
+	m.layoutTimeline()
+	if m.dashboard() {
+		for i := range m.columns {
+			if i != m.focus {
+				m.inColumn(i, m.layoutTimeline)
+			}
 		}
 	}
-	
-	m.viewport.SetContent(content.String())
 }
 
 func (m *Model) getCurrentEvents() []nostr.Event {
@@ -382,639 +228,61 @@ func (m *Model) getCurrentEvents() []nostr.Event {
 	case viewDMs:
 		return m.dms
 	case viewNotifications:
-		return m.notifications
+		return m.visibleNotifications()
+	case viewFeed:
+		return m.columns[m.focus].events
 	default:
 		return m.events
 	}
 }
 
-func (m *Model) scrollToCursor() {
-	if m.cursor >= len(m.eventLines) || len(m.eventLines) == 0 {
-		return
-	}
-	
-	targetLine := m.eventLines[m.cursor]
-	
-	// Calculate viewport boundaries
-	viewportTop := m.viewport.YOffset
-	viewportBottom := viewportTop + m.viewport.Height
-	
-	// If cursor is above viewport, scroll up
-	if targetLine < viewportTop {
-		m.viewport.SetYOffset(targetLine)
-	}
-	
-	// If cursor is below viewport, scroll down
-	// Account for event height (approximate 4 lines per event)
-	eventHeight := 4
-	if targetLine + eventHeight > viewportBottom {
-		m.viewport.SetYOffset(targetLine - m.viewport.Height + eventHeight)
-	}
-}
-
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
-		
-		headerHeight := 5  // Header + tabs + spacing
-		// Footer height depends on width - estimate based on commands
-		footerHeight := m.estimateFooterHeight()
-		verticalMarginHeight := headerHeight + footerHeight
-
-		if !m.ready {
-			m.viewport = viewport.New(msg.Width, msg.Height-verticalMarginHeight)
-			m.viewport.YPosition = headerHeight
-			m.ready = true
-			m.updateContent()
-		} else {
-			m.viewport.Width = msg.Width
-			m.viewport.Height = msg.Height - verticalMarginHeight
-		}
+		m.resize(msg.Width, msg.Height)
 		m.updateContent()
+		if m.state == stateTimeline {
+			// Widening into a dashboard shows columns that haven't loaded
+			return m, m.loadColumns()
+		}
 
 	case tea.KeyMsg:
-		// Handle landing screen
-		if m.state == stateLanding {
-			switch msg.String() {
-			case "q", "ctrl+c":
-				return m, tea.Quit
-			case "up", "k":
-				m.landingChoice = 0
-			case "down", "j":
-				m.landingChoice = 1
-			case "enter":
-				if m.landingChoice == 0 {
-					// Open Client - check if auth method is set
-					if m.authMethod == "" {
-						// Force user to settings to choose auth
-						m.state = stateSettings
-						m.statusMsg = "Please choose an authentication method first"
-					} else {
-						m.state = stateConnecting
-						if m.authMethod == "pleb_signer" {
-							return m, connectToPlebSignerCmd(m.signer)
-						} else {
-							return m, connectWithNsecCmd(m.nsecKey)
-						}
-					}
-				} else {
-					// Go to Settings
-					m.state = stateSettings
-				}
-			}
+		// The help covers every screen until it's closed
+		if m.help != nil {
+			m.handleHelpKey(msg)
 			return m, nil
 		}
-		
-		// Handle settings screen
-		if m.state == stateSettings {
-			if m.editingNsec {
-				switch msg.String() {
-				case "esc":
-					m.editingNsec = false
-					m.nsecKey = ""
-				case "enter":
-					// Debug: Show raw input before any processing
-					debugInfo := fmt.Sprintf("Raw input (len=%d): ", len(m.nsecKey))
-					for i, r := range m.nsecKey {
-						if i < 30 {
-							debugInfo += fmt.Sprintf("%c(%d) ", r, r)
-						}
-					}
-					m.statusMsg = debugInfo
-					
-					if strings.TrimSpace(m.nsecKey) != "" {
-						// Find and extract nsec from the input (handles bracket paste and other junk)
-						input := strings.TrimSpace(m.nsecKey)
-						
-						// Look for "nsec1" anywhere in the input
-						nsecIndex := strings.Index(input, "nsec1")
-						if nsecIndex >= 0 {
-							// Extract from "nsec1" to the end, removing any trailing junk
-							cleanKey := input[nsecIndex:]
-							// Remove any non-alphanumeric characters from the end
-							cleanKey = strings.TrimRightFunc(cleanKey, func(r rune) bool {
-								return r < '0' || r > 'z' || (r > '9' && r < 'A') || (r > 'Z' && r < 'a')
-							})
-							
-							// Validate length (nsec should be 63 characters)
-							if len(cleanKey) >= 60 && len(cleanKey) <= 66 {
-								m.editingNsec = false
-								m.statusMsg = "Decoding nsec key..."
-								m.nsecKey = "" // Clear for next time
-								// Decode and save the nsec key
-								return m, connectWithNsecCmd(cleanKey)
-							} else {
-								m.statusMsg = fmt.Sprintf("❌ Invalid nsec length: %d chars (expected ~63)", len(cleanKey))
-								m.nsecKey = ""
-								m.editingNsec = false
-							}
-						} else {
-							m.statusMsg = fmt.Sprintf("❌ No 'nsec1' found in: '%s'", input[:min(50, len(input))])
-							m.nsecKey = ""
-							m.editingNsec = false
-						}
-					} else {
-						m.statusMsg = "❌ Please enter an nsec key (field is empty)"
-						m.editingNsec = false
-					}
-				case "backspace":
-					if len(m.nsecKey) > 0 {
-						m.nsecKey = m.nsecKey[:len(m.nsecKey)-1]
-					}
-				default:
-					// Handle paste events and single character input
-					input := msg.String()
-					if len(input) > 0 {
-						// Accept both single chars and multi-char paste
-						m.nsecKey += input
-					}
-				}
-				return m, nil
-			}
-			
-			if m.editingNWC {
-				switch msg.String() {
-				case "esc":
-					m.editingNWC = false
-					m.nwcString = ""
-				case "enter":
-					if strings.TrimSpace(m.nwcString) != "" {
-						// Find and extract nostr+walletconnect:// URL
-						input := strings.TrimSpace(m.nwcString)
-						
-						// Look for "nostr+walletconnect://" anywhere in the input
-						nwcIndex := strings.Index(input, "nostr+walletconnect://")
-						if nwcIndex >= 0 {
-							// Extract from "nostr+walletconnect://" to the end
-							cleanNWC := input[nwcIndex:]
-							// Remove any trailing junk
-							cleanNWC = strings.TrimSpace(cleanNWC)
-							
-							// Stop old subscription if active
-							m.stopNWCSubscription()
-							
-							m.nwcString = cleanNWC
-							m.editingNWC = false
-							m.saveConfig()
-							
-							// Start new subscription
-							m.startNWCSubscription()
-							
-							m.statusMsg = "✓ NWC connection saved and subscription started!"
-						} else {
-							m.statusMsg = "❌ Invalid NWC format - must start with nostr+walletconnect://"
-							m.nwcString = ""
-							m.editingNWC = false
-						}
-					} else {
-						m.statusMsg = "❌ Please enter a NWC connection string"
-						m.editingNWC = false
-					}
-				case "backspace":
-					if len(m.nwcString) > 0 {
-						m.nwcString = m.nwcString[:len(m.nwcString)-1]
-					}
-				default:
-					// Handle paste events and single character input
-					input := msg.String()
-					if len(input) > 0 {
-						m.nwcString += input
-					}
-				}
-				return m, nil
-			}
-			
-			if m.editingRelay {
-				switch msg.String() {
-				case "esc":
-					m.editingRelay = false
-					m.newRelayInput = ""
-				case "enter":
-					if strings.TrimSpace(m.newRelayInput) != "" {
-						// Add new relay
-						m.relays = append(m.relays, strings.TrimSpace(m.newRelayInput))
-						m.newRelayInput = ""
-						m.editingRelay = false
-						m.saveConfig()
-					}
-				case "backspace":
-					if len(m.newRelayInput) > 0 {
-						m.newRelayInput = m.newRelayInput[:len(m.newRelayInput)-1]
-					}
-				default:
-					// Handle paste events and single character input
-					input := msg.String()
-					if len(input) > 0 {
-						// Accept both single chars and multi-char paste
-						m.newRelayInput += input
-					}
-				}
-				return m, nil
-			}
-			
-			// Settings menu navigation
-			switch msg.String() {
-			case "q", "esc":
-				// Back to landing
-				m.state = stateLanding
-			case "tab":
-				// Cycle through auth, relays, and wallet menus
-				m.settingsMenu = (m.settingsMenu + 1) % 3
-				m.settingsCursor = 0
-			case "up", "k":
-				if m.settingsCursor > 0 {
-					m.settingsCursor--
-				}
-			case "down", "j":
-				if m.settingsMenu == 1 && m.settingsCursor < len(m.relays)-1 {
-					m.settingsCursor++
-				} else if m.settingsMenu == 0 && m.settingsCursor < 1 {
-					m.settingsCursor++
-				}
-			case "enter":
-				if m.settingsMenu == 0 {
-					// Auth method selection
-					if m.settingsCursor == 0 {
-						m.authMethod = "pleb_signer"
-						m.saveConfig()
-					} else {
-						m.editingNsec = true
-						m.nsecKey = ""
-					}
-				} else if m.settingsMenu == 1 && m.authMethod != "" {
-					// Start client if auth method is set
-					m.state = stateConnecting
-					if m.authMethod == "pleb_signer" {
-						return m, connectToPlebSignerCmd(m.signer)
-					} else {
-						return m, connectWithNsecCmd(m.nsecKey)
-					}
-				}
-			case "a":
-				// Add new relay (only in relays menu)
-				if m.settingsMenu == 1 {
-					m.editingRelay = true
-					m.newRelayInput = ""
-				}
-			case "d", "x":
-				// Delete selected relay (only in relays menu)
-				if m.settingsMenu == 1 && m.settingsCursor < len(m.relays) && len(m.relays) > 1 {
-					m.relays = append(m.relays[:m.settingsCursor], m.relays[m.settingsCursor+1:]...)
-					if m.settingsCursor >= len(m.relays) {
-						m.settingsCursor = len(m.relays) - 1
-					}
-					m.saveConfig()
-				}
-				// Delete NWC connection (only in wallet menu)
-				if m.settingsMenu == 2 {
-					// Stop NWC subscription before clearing
-					m.stopNWCSubscription()
-					
-					m.nwcString = ""
-					m.saveConfig()
-					m.statusMsg = "NWC connection removed and subscription stopped"
-				}
-			case "e":
-				// Edit NWC connection (only in wallet menu)
-				if m.settingsMenu == 2 {
-					m.editingNWC = true
-					m.nwcString = ""
-				}
-			}
+		if m.state == stateThread && m.keys.action(keysThread, msg.String()) == actHelp {
+			m.openHelp()
 			return m, nil
 		}
 		
-		// Handle thread view mode
-		if m.state == stateThread {
-			switch msg.String() {
-			case "esc", "q":
-				// Exit thread view
-				m.state = stateTimeline
-				m.threadRoot = nil
-				m.threadEvents = nil
-				m.statusMsg = "Back to timeline"
+		// Screens handle their own keys
+		if screen := m.activeScreen(); screen != nil {
+			state := m.state
+			cmd := screen.Update(m.appContext, msg)
+			if m.state != state && m.ready {
 				m.updateContent()
-				return m, nil
-			case "R":
-				// Reply to thread root
-				m.state = stateComposing
-				m.composing = composeReply
-				m.replyingTo = m.threadRoot
-				m.textarea.Placeholder = "Write your reply... (Ctrl+S to send, Esc to cancel)"
-				username := m.userCache[m.threadRoot.PubKey]
-				if username == "" {
-					username = m.threadRoot.PubKey[:8] + "..."
-				}
-				m.statusMsg = "Replying to @" + username
-				m.textarea.Focus()
-				return m, nil
-			case "up", "k":
-				m.viewport.LineUp(1)
-			case "down", "j":
-				m.viewport.LineDown(1)
-			case "pgup", "b":
-				m.viewport.ViewUp()
-			case "pgdown", "f", " ":
-				m.viewport.ViewDown()
-			case "g":
-				m.viewport.GotoTop()
-			case "G":
-				m.viewport.GotoBottom()
 			}
-			return m, nil
+			return m, cmd
 		}
 		
-		// Handle composing mode separately
-		if m.state == stateComposing {
-			switch msg.String() {
-			case "esc":
-				// Cancel composing
-				m.state = stateTimeline
-				m.textarea.Reset()
-				m.replyingTo = nil
-				m.statusMsg = "Cancelled"
-				return m, nil
-			case "ctrl+s":
-				// Submit post
-				content := m.textarea.Value()
-				if strings.TrimSpace(content) == "" {
-					m.statusMsg = "Cannot send empty message"
-					return m, nil
-				}
-				
-				m.state = stateTimeline
-				m.textarea.Reset()
-				
-				switch m.composing {
-				case composePost:
-					m.statusMsg = "Publishing post..."
-					return m, publishPostCmd(m.signer, m.pool, m.relays, m.pubKey, content, nil, m.authMethod, m.privKey)
-				case composeReply:
-					m.statusMsg = "Publishing reply..."
-					return m, publishPostCmd(m.signer, m.pool, m.relays, m.pubKey, content, m.replyingTo, m.authMethod, m.privKey)
-				case composeQuote:
-					m.statusMsg = "Publishing quote..."
-					return m, publishQuoteCmd(m.signer, m.pool, m.relays, m.pubKey, content, m.replyingTo)
-				case composeDM:
-					if m.replyingTo == nil {
-						m.statusMsg = "Error: No DM recipient"
-						return m, nil
-					}
-					m.statusMsg = "Sending DM..."
-					return m, publishDMCmd(m.signer, m.pool, m.relays, m.pubKey, m.replyingTo.PubKey, content, m.authMethod, m.privKey)
-				}
-			default:
-				var cmd tea.Cmd
-				m.textarea, cmd = m.textarea.Update(msg)
-				return m, cmd
-			}
+		// So does whichever prompt or dialog is open over the timeline
+		if overlay := m.activeOverlay(); overlay != nil {
+			return m, overlay.Update(m.appContext, msg)
 		}
-		
-		// Zap amount input handling
-		if m.editingZapAmt {
-			switch msg.String() {
-			case "esc":
-				m.editingZapAmt = false
-				m.zapAmount = ""
-				m.zappingEvent = nil
-				m.statusMsg = ""
-				return m, nil
-			case "enter":
-				// Parse amount and initiate zap
-				amount, err := strconv.ParseInt(m.zapAmount, 10, 64)
-				if err != nil || amount <= 0 {
-					m.statusMsg = "⚠️ Invalid amount"
-					return m, nil
-				}
-				m.editingZapAmt = false
-				m.statusMsg = "⚡ Zapping..."
-				return m, m.performZap(m.zappingEvent, amount)
-			case "backspace":
-				if len(m.zapAmount) > 0 {
-					m.zapAmount = m.zapAmount[:len(m.zapAmount)-1]
-				}
-				return m, nil
-			default:
-				// Only accept digits
-				if len(msg.String()) == 1 {
-					ch := msg.String()[0]
-					if ch >= '0' && ch <= '9' {
-						m.zapAmount += msg.String()
-					}
-				}
-				return m, nil
+		if m.state == stateTimeline {
+			cmd := m.updateTimeline(msg)
+			if m.state != stateTimeline && m.ready {
+				m.updateContent()
 			}
+			return m, cmd
 		}
 		
-		// Normal mode key handling
-		switch msg.String() {
-		case "q", "ctrl+c":
+		// Connecting, loading and errors can only be quit
+		if m.keys.action(keysTimeline, msg.String()) == actQuit {
 			return m, tea.Quit
-		case "c":
-			// Start composing new post
-			m.state = stateComposing
-			m.composing = composePost
-			m.replyingTo = nil
-			m.textarea.Placeholder = "What's on your mind? (Ctrl+S to send, Esc to cancel)"
-			m.textarea.Focus()
-			m.statusMsg = "Composing new post"
-			return m, nil
-		case "z":
-			// Zap selected post
-			if m.nwcString == "" {
-				m.statusMsg = "⚠️ No wallet connected. Add NWC in Settings → Wallet"
-				return m, nil
-			}
-			currentEvents := m.getCurrentEvents()
-			if len(currentEvents) > 0 && m.cursor < len(currentEvents) {
-				evt := currentEvents[m.cursor]
-				m.zappingEvent = &evt
-				m.editingZapAmt = true
-				m.zapAmount = "21" // Default 21 sats
-				m.statusMsg = "Enter zap amount (sats): "
-			}
-			return m, nil
-		case "R":
-			// Reply to selected post or DM
-			currentEvents := m.getCurrentEvents()
-			if len(currentEvents) > 0 && m.cursor < len(currentEvents) {
-				evt := currentEvents[m.cursor]
-				m.state = stateComposing
-				m.replyingTo = &evt
-				
-				if m.currentView == viewDMs {
-					m.composing = composeDM
-					m.textarea.Placeholder = "Reply to DM... (Ctrl+S to send, Esc to cancel)"
-					username := m.userCache[evt.PubKey]
-					if username == "" {
-						username = evt.PubKey[:8] + "..."
-					}
-					m.statusMsg = "Replying to @" + username
-				} else {
-					m.composing = composeReply
-					m.textarea.Placeholder = "Write your reply... (Ctrl+S to send, Esc to cancel)"
-					username := m.userCache[evt.PubKey]
-					if username == "" {
-						username = evt.PubKey[:8] + "..."
-					}
-					m.statusMsg = "Replying to @" + username
-				}
-				
-				m.textarea.Focus()
-				return m, nil
-			}
-		case "tab":
-			// Switch to next view
-			m.cursor = 0 // Reset cursor when switching views
-			switch m.currentView {
-			case viewFollowing:
-				m.currentView = viewDMs
-				if len(m.dms) == 0 {
-					m.statusMsg = "Loading DMs..."
-					m.updateContent()
-					return m, fetchDMsCmd(m.pool, m.relays, m.pubKey)
-				}
-				// Mark currently visible DMs as read
-				m.markVisibleAsRead(viewDMs)
-			case viewDMs:
-				m.currentView = viewNotifications
-				if len(m.notifications) == 0 {
-					m.statusMsg = "Loading notifications..."
-					m.updateContent()
-					return m, fetchNotificationsCmd(m.pool, m.relays, m.pubKey)
-				}
-				// Mark currently visible notifications as read
-				m.markVisibleAsRead(viewNotifications)
-			case viewNotifications:
-				m.currentView = viewFollowing
-			}
-			m.updateContent()
-			m.viewport.GotoTop()
-		case "r":
-			// Refresh (unless in DMs or Notifications where 'r' might be confused)
-			if m.currentView == viewFollowing {
-				m.statusMsg = "Refreshing..."
-				return m, fetchEventsCmd(m.pool, m.relays, m.following)
-			}
-		case "x":
-			// Simple repost (kind 6)
-			currentEvents := m.getCurrentEvents()
-			if len(currentEvents) > 0 && m.cursor < len(currentEvents) {
-				evt := currentEvents[m.cursor]
-				if m.currentView != viewDMs { // Can't repost DMs
-					m.statusMsg = "Reposting..."
-					return m, repostCmd(m.signer, m.pool, m.relays, m.pubKey, &evt)
-				}
-			}
-		case "X":
-			// Quote repost (kind 1 with quote)
-			currentEvents := m.getCurrentEvents()
-			if len(currentEvents) > 0 && m.cursor < len(currentEvents) {
-				evt := currentEvents[m.cursor]
-				if m.currentView != viewDMs { // Can't quote DMs
-					m.state = stateComposing
-					m.composing = composeQuote
-					m.replyingTo = &evt
-					m.textarea.Placeholder = "Add your thoughts... (Ctrl+S to send, Esc to cancel)"
-					username := m.userCache[evt.PubKey]
-					if username == "" {
-						username = evt.PubKey[:8] + "..."
-					}
-					m.statusMsg = "Quote reposting @" + username
-					m.textarea.Focus()
-					return m, nil
-				}
-			}
-		case "g":
-			// Go to top
-			m.cursor = 0
-			m.updateContent()
-			m.viewport.GotoTop()
-		case "G":
-			// Go to bottom
-			currentEvents := m.getCurrentEvents()
-			if len(currentEvents) > 0 {
-				m.cursor = len(currentEvents) - 1
-			}
-			m.updateContent()
-			m.viewport.GotoBottom()
-		case "t":
-			// View thread
-			currentEvents := m.getCurrentEvents()
-			if len(currentEvents) > 0 && m.cursor < len(currentEvents) {
-				evt := currentEvents[m.cursor]
-				if m.currentView != viewDMs { // Don't show threads for DMs
-					m.state = stateThread
-					m.threadRoot = &evt
-					m.statusMsg = "Loading thread..."
-					return m, fetchThreadCmd(m.pool, m.relays, &evt)
-				}
-			}
-		case "up", "k":
-			if m.cursor > 0 {
-				m.cursor--
-				m.updateContent()
-				m.scrollToCursor()
-			}
-		case "down", "j":
-			currentEvents := m.getCurrentEvents()
-			if m.cursor < len(currentEvents)-1 {
-				m.cursor++
-				m.updateContent()
-				m.scrollToCursor()
-			}
-		case "pgup", "b":
-			// Move cursor up by viewport height worth of events
-			step := m.viewport.Height / 4 // Approximate 4 lines per event
-			if step < 1 {
-				step = 1
-			}
-			m.cursor -= step
-			if m.cursor < 0 {
-				m.cursor = 0
-			}
-			m.updateContent()
-			m.scrollToCursor()
-		case "pgdown", "f", " ":
-			// Move cursor down by viewport height worth of events
-			step := m.viewport.Height / 4 // Approximate 4 lines per event
-			if step < 1 {
-				step = 1
-			}
-			currentEvents := m.getCurrentEvents()
-			m.cursor += step
-			if m.cursor >= len(currentEvents) {
-				m.cursor = len(currentEvents) - 1
-			}
-			m.updateContent()
-			m.scrollToCursor()
-		case "enter":
-			currentEvents := m.getCurrentEvents()
-			if len(currentEvents) > 0 && m.cursor < len(currentEvents) {
-				evt := currentEvents[m.cursor]
-				url := extractFirstURL(evt.Content)
-				if url != "" {
-					go OpenMedia(url)
-					m.statusMsg = "Opening: " + url
-				}
-			}
-		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
-			// Open specific URL by number
-			currentEvents := m.getCurrentEvents()
-			if len(currentEvents) > 0 && m.cursor < len(currentEvents) {
-				evt := currentEvents[m.cursor]
-				urls := extractAllURLs(evt.Content)
-				index := int(msg.String()[0] - '1') // Convert '1'-'9' to 0-8
-				if index < len(urls) {
-					go OpenMedia(urls[index])
-					m.statusMsg = fmt.Sprintf("Opening [%d]: %s", index+1, urls[index])
-				}
-			}
 		}
-		// Don't update content again here since we already did it above
-		// Basic scrolling could be added here if we had a viewport
 
 	case nsecAuthMsg:
 		m.authMethod = "nsec"
@@ -1043,7 +311,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.npub = npub
 		m.statusMsg = "Authenticated with nsec! Loading..."
 		m.state = stateLoadingFollows
-		return m, fetchFollowingCmd(m.pool, m.relays, m.pubKey)
+		return m, fetchFollowingCmd(m.client())
 	
 	case pubKeyMsg:
 		m.pubKey = msg.pubKey
@@ -1061,7 +329,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.npub = npub
 		m.state = stateLoadingFollows
 		m.statusMsg = "Loading following list..."
-		return m, fetchFollowingCmd(m.pool, m.relays, m.pubKey)
+		return m, fetchFollowingCmd(m.client())
 	
 	case signerReadyMsg:
 		m.pubKey = msg.pubkey
@@ -1086,7 +354,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.npub = npub
 		m.state = stateLoadingFollows
 		m.statusMsg = "Loading following list..."
-		return m, fetchFollowingCmd(m.pool, m.relays, m.pubKey)
+		return m, fetchFollowingCmd(m.client())
 
 	case followingMsg:
 		m.following = msg.pubkeys
@@ -1096,15 +364,36 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			m.statusMsg = fmt.Sprintf("Connected. Following %d people.", len(m.following))
 		}
-		return m, tea.Batch(
-			fetchEventsCmd(m.pool, m.relays, m.following),
-			fetchProfilesCmd(m.pool, m.relays, m.following),
-		)
+		m.requestProfiles(m.following...)
+		return m, tea.Batch(fetchEventsCmd(m.client(), m.following), m.loadColumns())
 
 	case errMsg:
 		m.state = stateError
 		m.err = msg.err
 
+	case noteMsg:
+		if msg.err != nil {
+			m.statusMsg = fmt.Sprintf("⚠️ Can't open note: %v", msg.err)
+			return m, nil
+		}
+		if m.state != stateTimeline {
+			return m, nil
+		}
+		m.thread.open(m.appContext, msg.event)
+		m.statusMsg = "Loading thread..."
+		m.updateContent()
+		return m, fetchThreadCmd(m.client(), msg.event)
+
+	case followMsg:
+		if msg.err != nil {
+			m.statusMsg = fmt.Sprintf("⚠️ Failed to follow: %v", msg.err)
+			return m, nil
+		}
+		m.following = msg.following
+		m.statusMsg = fmt.Sprintf("✓ Following @%s", m.displayName(msg.pubkey))
+		m.requestProfiles(msg.pubkey)
+		return m, fetchEventsCmd(m.client(), m.following)
+
 	case eventsMsg:
 		// Merge new events with existing ones
 		newCount := 0
@@ -1121,6 +410,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				newCount++
 			}
 			eventMap[evt.ID] = evt
+			// The global feed has authors we don't follow
+			m.requestProfiles(evt.PubKey)
 			
 			// Track latest timestamp
 			if evt.CreatedAt > m.lastEventTime {
@@ -1145,6 +436,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.statusMsg = fmt.Sprintf("No new posts (%d total)", len(m.events))
 		}
 		m.updateContent()
+		return m, fetchZapReceiptsCmd(m.pool, m.relays, eventIDs(m.events))
 	
 	case dmsMsg:
 		// Merge new DMs with existing ones
@@ -1156,9 +448,6 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			dmMap[evt.ID] = evt
 		}
 		
-		// Collect pubkeys that need profile fetching
-		pubkeysToFetch := make(map[string]bool)
-		
 		// Add new DMs to map (and count them)
 		for _, evt := range msg.events {
 			if _, exists := dmMap[evt.ID]; !exists {
@@ -1166,19 +455,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			dmMap[evt.ID] = evt
 			
-			// Collect pubkeys for profile fetching
-			// Add the sender
-			if _, cached := m.userCache[evt.PubKey]; !cached {
-				pubkeysToFetch[evt.PubKey] = true
-			}
-			
-			// Add the recipient (from 'p' tag)
+			// Fetch the names of the sender and the recipient (from 'p' tag)
+			m.requestProfiles(evt.PubKey)
 			for _, tag := range evt.Tags {
 				if len(tag) >= 2 && tag[0] == "p" {
-					recipientPubkey := tag[1]
-					if _, cached := m.userCache[recipientPubkey]; !cached {
-						pubkeysToFetch[recipientPubkey] = true
-					}
+					m.requestProfiles(tag[1])
 					break
 				}
 			}
@@ -1204,15 +485,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			m.statusMsg = fmt.Sprintf("No new DMs (%d total)", len(m.dms))
 		}
+		m.updateContent()
+		return m, m.queueDecrypt(m.dms...)
+	
+	case feedMsg:
+		if c := m.storeFeed(msg); c != nil {
+			m.updateContent()
+			return m, fetchZapReceiptsCmd(m.pool, m.relays, eventIDs(c.events))
+		}
+		return m, nil
+	
+	case dmDecryptedMsg:
+		cmd := m.storeDecrypted(msg)
+		m.updateContent()
+		return m, cmd
 		
-		// Fetch profiles for DM participants
-if len(pubkeysToFetch) > 0 {
-pubkeys := make([]string, 0, len(pubkeysToFetch))
-for pk := range pubkeysToFetch {
-pubkeys = append(pubkeys, pk)
-}
-return m, fetchProfilesCmd(m.pool, m.relays, pubkeys)
-}
 
 case notificationsMsg:
 		// Merge new notifications with existing ones
@@ -1252,35 +539,66 @@ case notificationsMsg:
 		} else {
 			m.statusMsg = fmt.Sprintf("No new notifications (%d total)", len(m.notifications))
 		}
-		m.updateContent()
 		
-		// Extract unique pubkeys from notifications and fetch their profiles
-		pubkeys := make(map[string]bool)
-		for _, evt := range m.notifications {
-			pubkeys[evt.PubKey] = true
+		// Zap receipts are signed by the recipient's LNURL server; the
+		// sender's profile is fetched from the embedded zap request instead
+		var receipts []nostr.Event
+		for _, evt := range msg.events {
+			if evt.Kind == zap.KindZapReceipt {
+				receipts = append(receipts, evt)
+			}
 		}
+		receiptCmd := m.addZapReceipts(receipts)
+		m.updateContent()
 		
-		var pubkeyList []string
-		for pk := range pubkeys {
-			if _, cached := m.userCache[pk]; !cached {
-				pubkeyList = append(pubkeyList, pk)
+		// Fetch the names of whoever mentioned or reacted to us
+		for _, evt := range m.notifications {
+			if evt.Kind != zap.KindZapReceipt {
+				m.requestProfiles(evt.PubKey)
 			}
 		}
-		
-		if len(pubkeyList) > 0 {
-			return m, fetchProfilesCmd(m.pool, m.relays, pubkeyList)
+		return m, receiptCmd
+	
+	case zapReceiptsMsg:
+		cmd := m.addZapReceipts(msg.events)
+		m.updateContent()
+		return m, cmd
+	
+	case zapperKeysMsg:
+		for _, pk := range msg.requested {
+			delete(m.zapperKeysPending, pk)
 		}
+		for pk, key := range msg.keys {
+			m.zapperKeys[pk] = key
+		}
+		m.validateZapReceipts()
+		m.updateContent()
 	
 	case profilesMsg:
-		for pubkey, name := range msg.profiles {
-			m.userCache[pubkey] = name
-		}
+		m.profiles.store(msg)
 		m.updateContent() // Refresh to show new names
+		return m, tea.Batch(
+			waitForProfiles(m.profiles.updates),
+			verifyNIP05Cmd(m.client(), append(m.profiles.nip05Due(msg.requested), msg.recheck...)),
+		)
+	
+	case nip05Msg:
+		m.profiles.storeNIP05(msg)
+		m.updateContent()
 		
 	case threadEventsMsg:
-		m.threadEvents = msg.events
+		m.thread.events = msg.events
+		for _, evt := range msg.events {
+			m.requestProfiles(evt.PubKey)
+		}
 		m.statusMsg = fmt.Sprintf("Thread loaded (%d replies)", len(msg.events))
 		m.updateContent()
+		if m.thread.root != nil {
+			return m, fetchZapReceiptsCmd(m.pool, m.relays, append(eventIDs(m.thread.events), m.thread.root.ID))
+		}
+		
+	case composeMsg:
+		m.composer.open(m.appContext, msg.mode, msg.replyTo)
 		
 	case publishSuccessMsg:
 		// Use custom status if provided, otherwise determine from context
@@ -1303,64 +621,91 @@ case notificationsMsg:
 			}
 			m.statusMsg = action + " successfully! ✓"
 		}
-		m.replyingTo = nil
 		// Refresh current view to show new post
 		switch m.currentView {
 		case viewFollowing:
-			return m, fetchEventsCmd(m.pool, m.relays, m.following)
+			return m, fetchEventsCmd(m.client(), m.following)
 		case viewDMs:
-			return m, fetchDMsCmd(m.pool, m.relays, m.pubKey)
+			return m, fetchDMsCmd(m.client())
 		case viewNotifications:
-			return m, fetchNotificationsCmd(m.pool, m.relays, m.pubKey)
+			return m, fetchNotificationsCmd(m.client())
 		}
 	
 	case nwcResponseMsg:
-		// Handle NWC response - match to pending payment
-		log.Printf("📬 [NWC] Processing response: %s", msg.event.ID[:8])
-		
-		// Find which request this is responding to by checking 'e' tag
-		var requestID string
-		for _, tag := range msg.event.Tags {
-			if len(tag) >= 2 && tag[0] == "e" {
-				requestID = tag[1]
-				break
-			}
+		return m, m.handleNWCResponse(msg.event)
+	
+	case invoiceReadyMsg:
+		m.payConfirm.open(m.appContext, msg.invoice, msg.decoded, msg.target)
+		return m, nil
+	
+	case sendSatsMsg:
+		m.sendSats.openAmount(m.appContext, msg.address)
+		return m, nil
+	
+	case confirmZapMsg:
+		return m, m.zapDialog.open(m.appContext, msg)
+	
+	case paletteCommandMsg:
+		cmd := msg.command.run(&m, msg.arg)
+		if m.ready {
+			m.updateContent()
 		}
-		
-		if requestID != "" {
-			log.Printf("🔗 [NWC] Response for request: %s", requestID[:8])
-			if respChan, ok := m.pendingPayments[requestID]; ok {
-				log.Printf("✅ [NWC] Found pending payment, forwarding response")
-				// Send response to waiting channel
-				select {
-				case respChan <- msg.event:
-					log.Printf("📨 [NWC] Response delivered")
-				default:
-					log.Printf("⚠️  [NWC] Response channel full or closed")
-				}
-				// Clean up
-				delete(m.pendingPayments, requestID)
-			} else {
-				log.Printf("⚠️  [NWC] No pending payment for request %s", requestID[:8])
-			}
-		} else {
-			log.Printf("⚠️  [NWC] Response has no 'e' tag")
+		return m, cmd
+	
+	case paymentResultMsg:
+		if msg.err != nil {
+			m.statusMsg = fmt.Sprintf("⚠️ Payment failed: %v", msg.err)
+			return m, nil
 		}
-		
-		// Continue waiting for more responses
-		return m, waitForNWCResponse(m.nwcResponseChan)
+		// Payments count against the same budgets as zaps
+		m.spending.Record(msg.wallet, msg.amountSats, time.Now())
+		if err := config.SaveSpending(m.spending); err != nil {
+			log.Printf("⚠️  Failed to save spending ledger: %v", err)
+		}
+		m.statusMsg = fmt.Sprintf("⚡ Paid %s successfully!", formatSats(msg.amountSats*1000))
+		return m, nil
 	
 	case zapSuccessMsg:
-		m.statusMsg = "⚡ Zap sent successfully!"
-		m.zapAmount = ""
-		m.zappingEvent = nil
-		return m, nil
+		// Count the zap against the wallet's budgets
+		if msg.wallet != "" {
+			m.spending.Record(msg.wallet, msg.amountSats, time.Now())
+			if err := config.SaveSpending(m.spending); err != nil {
+				log.Printf("⚠️  Failed to save spending ledger: %v", err)
+			}
+		}
+		m.statusMsg = m.zapResultStatus(msg)
+		if m.zapDialog.sending {
+			m.zapDialog.result = &msg
+		}
+		if msg.eventID == "" || zap.IsAddress(msg.eventID) {
+			return m, nil
+		}
+		// Look for the receipt once the recipient's LNURL server has published it
+		m.awaitingReceipt = msg.eventID
+		pool, relays, ids := m.pool, m.relays, []string{msg.eventID}
+		return m, tea.Tick(receiptFetchDelay, func(time.Time) tea.Msg {
+			return fetchZapReceiptsCmd(pool, relays, ids)()
+		})
 	
 	case zapErrorMsg:
 		m.statusMsg = fmt.Sprintf("⚠️ Zap failed: %v", msg.err)
-		m.zapAmount = ""
-		m.zappingEvent = nil
-		m.editingZapAmt = false
+		if m.zapDialog.sending {
+			m.zapDialog.err = msg.err
+		}
+		return m, nil
+	
+	case fiatRateMsg:
+		m.fiatRatePending = false
+		if msg.err != nil {
+			log.Printf("⚠️  Failed to fetch %s rate: %v", m.fiatCurrency, msg.err)
+			return m, nil
+		}
+		m.fiatRate = msg.rate
+		m.fiatRateTime = time.Now()
+		return m, nil
+	
+	case zapTargetMsg:
+		m.zapPrompt.addTarget(msg)
 		return m, nil
 	}
 
@@ -1368,12 +713,17 @@ case notificationsMsg:
 }
 
 func (m Model) View() string {
-	if m.state == stateLanding {
-		return m.renderLanding()
+	if m.help != nil {
+		return m.renderHelp()
 	}
-	
-	if m.state == stateSettings {
-		return m.renderSettings()
+	if screen := m.activeScreen(); screen != nil {
+		return screen.View(m.appContext)
+	}
+	if overlay := m.activeOverlay(); overlay != nil {
+		return m.renderTimeline(overlay.Status(m.appContext), overlay.View(m.appContext))
+	}
+	if m.state == stateTimeline {
+		return m.renderTimeline(m.statusMsg, "")
 	}
 	
 	if m.state == stateError {
@@ -1407,170 +757,57 @@ func (m Model) View() string {
 		return "Connecting to Pleb Signer..."
 	}
 
-	if m.state == stateLoadingFollows {
-		return "Loading your following list..."
-	}
-	
-	if m.state == stateThread {
-		// Show thread view
-		header := lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("205")).
-			Render(fmt.Sprintf("Noscli - %s", m.statusMsg))
-		
-		footer := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			Render("R reply • ↑↓/k/j scroll • Esc/q back")
-		
-		return fmt.Sprintf("%s\n%s\n\n%s", header, m.viewport.View(), footer)
-	}
-	
-	if m.state == stateComposing {
-		// Show compose view
-		header := lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("205")).
-			Render(fmt.Sprintf("Noscli - %s", m.statusMsg))
-		
-		footer := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			Render("Ctrl+S send • Esc cancel")
-		
-		// Show reply context if replying or quoting
-		var contextView string
-		if m.replyingTo != nil {
-			username := m.userCache[m.replyingTo.PubKey]
-			if username == "" {
-				username = m.replyingTo.PubKey[:8] + "..."
-			}
-			
-			// Process and truncate content for display
-			content := m.processContent(m.replyingTo.Content)
-			if len(content) > 200 {
-				content = content[:200] + "..."
-			}
-			
-			contextStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("241")).
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color("63")).
-				Padding(0, 1).
-				Width(60)
-			
-			var contextLabel string
-			if m.composing == composeQuote {
-				contextLabel = "Quoting @"
-			} else {
-				contextLabel = "Replying to @"
-			}
-			
-			contextView = contextStyle.Render(fmt.Sprintf("%s%s:\n%s", contextLabel, username, content)) + "\n\n"
-		}
-		
-		return fmt.Sprintf("%s\n\n%s%s\n\n%s", header, contextView, m.textarea.View(), footer)
-	}
-
-	if !m.ready {
-		return "Initializing..."
-	}
-
-	// Tab bar
-	tabStyle := lipgloss.NewStyle().Padding(0, 2)
-	activeTabStyle := tabStyle.Copy().Bold(true).Foreground(lipgloss.Color("205"))
-	inactiveTabStyle := tabStyle.Copy().Foreground(lipgloss.Color("241"))
-	unreadBadgeStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214"))
-
-	// Count unread DMs and notifications
-	unreadDMs := m.countUnread(viewDMs)
-	unreadNotifs := m.countUnread(viewNotifications)
-
-	followingTab := inactiveTabStyle.Render("Following")
-	dmsTab := inactiveTabStyle.Render("DMs")
-	if unreadDMs > 0 {
-		dmsTab = inactiveTabStyle.Render(fmt.Sprintf("DMs %s", unreadBadgeStyle.Render(fmt.Sprintf("(%d)", unreadDMs))))
-	}
-	notificationsTab := inactiveTabStyle.Render("Notifications")
-	if unreadNotifs > 0 {
-		notificationsTab = inactiveTabStyle.Render(fmt.Sprintf("Notifications %s", unreadBadgeStyle.Render(fmt.Sprintf("(%d)", unreadNotifs))))
-	}
-
-	switch m.currentView {
-	case viewFollowing:
-		followingTab = activeTabStyle.Render("Following")
-	case viewDMs:
-		dmsTab = activeTabStyle.Render("DMs")
-		if unreadDMs > 0 {
-			dmsTab = activeTabStyle.Render(fmt.Sprintf("DMs %s", unreadBadgeStyle.Render(fmt.Sprintf("(%d)", unreadDMs))))
-		}
-	case viewNotifications:
-		notificationsTab = activeTabStyle.Render("Notifications")
-		if unreadNotifs > 0 {
-			notificationsTab = activeTabStyle.Render(fmt.Sprintf("Notifications %s", unreadBadgeStyle.Render(fmt.Sprintf("(%d)", unreadNotifs))))
-		}
-	}
-
-	tabs := lipgloss.JoinHorizontal(lipgloss.Top, followingTab, dmsTab, notificationsTab)
-
-	// Show zap amount input if active
-	statusDisplay := m.statusMsg
-	if m.editingZapAmt {
-		statusDisplay = fmt.Sprintf("⚡ Zap amount (sats): %s_ (Enter to confirm, Esc to cancel)", m.zapAmount)
-	}
-
-	header := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("205")).
-		Render(fmt.Sprintf("Noscli - %s", statusDisplay))
-
-	// Create responsive footer that wraps based on width
-	footer := m.renderFooter()
-
-	return fmt.Sprintf("%s\n%s\n\n%s\n\n%s", header, tabs, m.viewport.View(), footer)
+	// Still loading who we follow
+	return "Loading your following list..."
 }
 
 func (m *Model) renderFooter() string {
+	k := m.keys
 	commands := []string{
-		"c compose",
-		"R reply",
+		k.hint("compose", actCompose),
+		k.hint("reply", actReply),
 	}
 	
-	// Add zap command if NWC is connected
+	// Add zap and payment commands if NWC is connected
 	if m.nwcString != "" {
-		commands = append(commands, "z zap")
+		commands = append(commands, k.hint("zap", actZap), k.hint("zap user", actZapUser), k.hint("pay invoice", actPayInvoice), k.hint("send sats", actSendSats))
 	}
 	
 	commands = append(commands,
-		"x repost",
-		"X quote",
-		"t thread",
-		"tab switch",
-		"↑/k ↓/j nav",
-		"space/f/b page",
-		"g/G top/bot",
-		"r refresh",
-		"enter/1-9 open",
-		"q quit",
+		k.hint("repost", actRepost),
+		k.hint("quote", actQuote),
+		k.hint("thread", actThread),
+		k.hint("switch", actNextColumn),
+		k.hint("move tab", actMoveLeft, actMoveRight),
+		k.hint("nav", actUp, actDown),
+		k.hint("page", actPageDown, actPageUp),
+		k.hint("top/bot", actTop, actBottom),
+		k.hint("refresh", actRefresh),
+		k.hint("open", actOpen, actOpenLink),
+		k.hint("compact", actCompact),
+		k.hint("commands", actPalette),
+		k.hint("help", actHelp),
+		k.hint("quit", actQuit),
 	)
 	
 	// Calculate available width (leave some margin)
-	availableWidth := m.width - 4
-	if availableWidth < 40 {
-		availableWidth = 40
-	}
+	availableWidth := max(m.width-4, 20)
 	
 	// Build lines that fit within width
 	var lines []string
 	currentLine := ""
 	
-	for i, cmd := range commands {
+	for _, cmd := range commands {
+		if cmd == "" {
+			continue // Unbound
+		}
 		separator := " • "
-		if i == 0 {
+		if currentLine == "" {
 			separator = ""
 		}
 		
 		testLine := currentLine + separator + cmd
-		// Rough estimate: each character is ~1 width
-		if len(testLine) > availableWidth && currentLine != "" {
+		if lipgloss.Width(testLine) > availableWidth && currentLine != "" {
 			// Start new line
 			lines = append(lines, currentLine)
 			currentLine = cmd
@@ -1591,27 +828,7 @@ func (m *Model) renderFooter() string {
 		Render(footerText)
 }
 
-func (m *Model) estimateFooterHeight() int {
-	// Estimate how many lines the footer will take
-	// Each command is roughly 10-15 chars, plus separators
-	totalChars := 80 // Approximate total length of all commands with separators
-	availableWidth := m.width - 4
-	if availableWidth < 40 {
-		availableWidth = 40
-	}
-	
-	lines := (totalChars / availableWidth) + 1
-	if lines < 1 {
-		lines = 1
-	}
-	if lines > 4 {
-		lines = 4 // Cap at 4 lines
-	}
-	
-	return lines + 1 // Add 1 for padding
-}
-
-func (m *Model) renderEvent(evt nostr.Event, selected bool) string {
+func (m *Model) renderEvent(evt nostr.Event, selected bool, width int) string {
 	borderColor := lipgloss.Color("63") // Default purple
 	if selected {
 		borderColor = lipgloss.Color("205") // Pinkish for selected
@@ -1621,45 +838,9 @@ func (m *Model) renderEvent(evt nostr.Event, selected bool) string {
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(borderColor).
 		Padding(0, 1).
-		Width(60)
+		Width(cardWidthFor(width))
 
-	// Decrypt DMs if this is a kind 4 (NIP-04) or kind 1059 (NIP-17 gift wrap)
-	displayContent := evt.Content
-	if evt.Kind == 4 {
-		// NIP-04: Old encrypted DMs
-		// Determine the other party's pubkey for decryption
-		otherPubkey := ""
-		if evt.PubKey == m.pubKey {
-			// We sent this, decrypt with recipient's key
-			for _, tag := range evt.Tags {
-				if len(tag) >= 2 && tag[0] == "p" {
-					otherPubkey = tag[1]
-					break
-				}
-			}
-		} else {
-			// We received this, decrypt with sender's key
-			otherPubkey = evt.PubKey
-		}
-		
-		if otherPubkey != "" {
-			decrypted, err := m.decryptDM(evt.Content, otherPubkey)
-			if err == nil {
-				displayContent = decrypted
-			} else {
-				displayContent = fmt.Sprintf("[🔒 NIP-04 Encrypted - Decrypt error: %v]", err)
-			}
-		}
-	} else if evt.Kind == 1059 {
-		// NIP-17: Gift-wrapped DM
-		decrypted, err := m.unwrapGiftWrapDM(evt)
-		if err == nil {
-			displayContent = decrypted
-		} else {
-			displayContent = fmt.Sprintf("[🎁 NIP-17 Gift-wrap - Unwrap error: %v]", err)
-		}
-	}
-	
+	displayContent := m.eventText(evt)
 	content := m.processContent(displayContent)
 	
 	// Extract and annotate all URLs with numbers
@@ -1691,8 +872,41 @@ func (m *Model) renderEvent(evt nostr.Event, selected bool) string {
 		}
 	}
 
+	// Summarize lightning invoices (decrypted DMs can carry them too)
+	for _, invoice := range extractInvoices(displayContent) {
+		content += "\n" + renderInvoiceLine(invoice)
+	}
+
+	if line := m.zapSplitLine(evt); line != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(line)
+	}
+	if line := m.zapTotalLine(evt.ID); line != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(line)
+	}
+
+	return style.Render(fmt.Sprintf("%s\n%s", m.eventHeader(evt, selected), content))
+}
+
+// eventText returns what an event says: decrypted DMs, summarized wallet
+// notifications and zap receipts, and the content of everything else
+func (m *Model) eventText(evt nostr.Event) string {
+	// Decrypt DMs if this is a kind 4 (NIP-04) or kind 1059 (NIP-17 gift wrap)
+	if isDM(evt) {
+		return m.dmPlaintext(evt)
+	} else if nwc.IsNotificationKind(evt.Kind) {
+		// NIP-47 wallet notification, already decrypted when it arrived
+		return m.walletNotifText[evt.ID]
+	} else if evt.Kind == zap.KindZapReceipt {
+		return m.zapReceiptText(evt)
+	}
+	return evt.Content
+}
+
+// eventHeader returns the line above an event's content: who it's from (or
+// to), when, and whether it's unread
+func (m *Model) eventHeader(evt nostr.Event, selected bool) string {
 	// Get display name - for DMs, show conversation partner
-	displayName := m.userCache[evt.PubKey]
+	displayName := m.profiles.name(evt.PubKey)
 	if displayName == "" {
 		displayName = evt.PubKey[:8] + "..."
 	}
@@ -1704,7 +918,7 @@ func (m *Model) renderEvent(evt nostr.Event, selected bool) string {
 			// We sent this DM - show recipient
 			for _, tag := range evt.Tags {
 				if len(tag) >= 2 && tag[0] == "p" {
-					recipientName := m.userCache[tag[1]]
+					recipientName := m.profiles.name(tag[1])
 					if recipientName == "" {
 						recipientName = tag[1][:8] + "..."
 					}
@@ -1716,6 +930,10 @@ func (m *Model) renderEvent(evt nostr.Event, selected bool) string {
 			// We received this DM - show sender
 			dmPrefix = "💬 From @" + displayName + ": "
 		}
+	} else if nwc.IsNotificationKind(evt.Kind) {
+		dmPrefix = "⚡ Wallet"
+	} else if evt.Kind == zap.KindZapReceipt {
+		dmPrefix = m.zapReceiptHeader(evt)
 	}
 	
 	// Format timestamp
@@ -1734,6 +952,8 @@ func (m *Model) renderEvent(evt nostr.Event, selected bool) string {
 	} else if evt.Kind == 1 && evt.PubKey != m.pubKey && !m.readNotifs[evt.ID] {
 		// Notification (reply/mention) that's unread
 		unreadIndicator = "🔵 "
+	} else if (nwc.IsNotificationKind(evt.Kind) || evt.Kind == zap.KindZapReceipt) && !m.readNotifs[evt.ID] {
+		unreadIndicator = "🔵 "
 	}
 	
 	if dmPrefix != "" {
@@ -1744,7 +964,7 @@ func (m *Model) renderEvent(evt nostr.Event, selected bool) string {
 	} else {
 		header = fmt.Sprintf("%s%s  %s", 
 			unreadIndicator,
-			headerStyle.Render("@"+displayName),
+			headerStyle.Render("@"+displayName+m.nip05Badge(evt.PubKey)),
 			timestampStyle.Render(timestamp))
 	}
 	
@@ -1752,11 +972,14 @@ func (m *Model) renderEvent(evt nostr.Event, selected bool) string {
 		header = "> " + header
 	}
 	
-	return style.Render(fmt.Sprintf("%s\n%s", header, content))
+	return header
 }
 
+// timeNow is the clock relative timestamps are shown against; tests pin it
+var timeNow = time.Now
+
 func formatTimestamp(t time.Time) string {
-	now := time.Now()
+	now := timeNow()
 	diff := now.Sub(t)
 	
 	// Less than a minute
@@ -1801,7 +1024,7 @@ func formatTimestamp(t time.Time) string {
 	return t.Format("Jan 2, 2006")
 }
 
-func (m *Model) processContent(content string) string {
+func (a *appContext) processContent(content string) string {
 	// Replace nostr: URIs with readable text
 	words := strings.Fields(content)
 	var processed []string
@@ -1814,24 +1037,25 @@ func (m *Model) processContent(content string) string {
 			// Extract the NIP-19 identifier
 			nip19Str := strings.TrimPrefix(cleanWord, "nostr:")
 			
-			if pubkey := m.extractPubkeyFromNip19(nip19Str); pubkey != "" {
-				username := m.userCache[pubkey]
+			if pubkey := a.extractPubkeyFromNip19(nip19Str); pubkey != "" {
+				username := a.profiles.name(pubkey)
 				if username == "" {
 					username = pubkey[:8] + "..."
+					// Fetched with the other unknown names of this render
+					a.requestProfiles(pubkey)
 				}
 				// Replace with @username
 				processed = append(processed, "@"+username+trailingPunct)
-				// Queue fetching this profile if not cached
-				if _, exists := m.userCache[pubkey]; !exists {
-					go m.fetchSingleProfile(pubkey)
-				}
 			} else {
 				processed = append(processed, word)
 			}
+		} else if zap.IsInvoice(cleanWord) {
+			// Invoices are long and unreadable, they're summarized below the note
+			processed = append(processed, "⚡[Invoice]"+trailingPunct)
 		} else if strings.HasPrefix(cleanWord, "nostr:nevent") || strings.HasPrefix(cleanWord, "nostr:note") {
 			// Handle event references
 			nip19Str := strings.TrimPrefix(cleanWord, "nostr:")
-			eventID := m.extractEventIDFromNip19(nip19Str)
+			eventID := a.extractEventIDFromNip19(nip19Str)
 			if eventID != "" {
 				// Show as a link indicator
 				processed = append(processed, "🔗[Event:"+eventID[:8]+"...]"+trailingPunct)
@@ -1846,7 +1070,7 @@ func (m *Model) processContent(content string) string {
 	return strings.Join(processed, " ")
 }
 
-func (m *Model) extractEventIDFromNip19(nip19Str string) string {
+func (a *appContext) extractEventIDFromNip19(nip19Str string) string {
 	if strings.HasPrefix(nip19Str, "note") {
 		_, data, err := nip19.Decode(nip19Str)
 		if err == nil {
@@ -1865,7 +1089,7 @@ func (m *Model) extractEventIDFromNip19(nip19Str string) string {
 	return ""
 }
 
-func (m *Model) extractPubkeyFromNip19(nip19Str string) string {
+func (a *appContext) extractPubkeyFromNip19(nip19Str string) string {
 	if strings.HasPrefix(nip19Str, "npub") {
 		_, data, err := nip19.Decode(nip19Str)
 		if err == nil {
@@ -1884,50 +1108,6 @@ func (m *Model) extractPubkeyFromNip19(nip19Str string) string {
 	return ""
 }
 
-func (m *Model) fetchSingleProfile(pubkey string) {
-	defer func() {
-		if r := recover(); r != nil {
-			// Catch panics from relay operations
-		}
-	}()
-	
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	filter := nostr.Filter{
-		Kinds:   []int{0},
-		Authors: []string{pubkey},
-		Limit:   1,
-	}
-
-	eventsChannel := m.pool.SubManyEose(ctx, m.relays, []nostr.Filter{filter})
-	for event := range eventsChannel {
-		if event.Event == nil {
-			continue
-		}
-		
-		var metadata struct {
-			Name        string `json:"name"`
-			DisplayName string `json:"display_name"`
-			Username    string `json:"username"`
-		}
-		
-		if err := json.Unmarshal([]byte(event.Event.Content), &metadata); err == nil {
-			displayName := metadata.DisplayName
-			if displayName == "" {
-				displayName = metadata.Name
-			}
-			if displayName == "" {
-				displayName = metadata.Username
-			}
-			if displayName != "" {
-				m.userCache[pubkey] = displayName
-			}
-		}
-		break
-	}
-}
-
 // Commands and Msg types
 
 type signerReadyMsg struct {
@@ -1938,10 +1118,6 @@ type followingMsg struct {
 	pubkeys []string
 }
 
-type profilesMsg struct {
-	profiles map[string]string // pubkey -> display name
-}
-
 type errMsg struct {
 	err error
 }
@@ -1984,45 +1160,7 @@ func checkSignerCmd(s *signer.PlebSigner) tea.Cmd {
 	}
 }
 
-func fetchFollowingCmd(pool *nostr.SimplePool, relays []string, pubKey string) tea.Cmd {
-	return func() tea.Msg {
-		defer func() {
-			if r := recover(); r != nil {
-				// Catch panics from relay operations
-			}
-		}()
-		
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-
-		// Fetch kind 3 (contact list)
-		filter := nostr.Filter{
-			Kinds:   []int{3}, // Kind 3 = Contact List
-			Authors: []string{pubKey},
-			Limit:   1,
-		}
-
-		var following []string
-		events := pool.SubManyEose(ctx, relays, []nostr.Filter{filter})
-		
-		for event := range events {
-			if event.Event == nil {
-				continue
-			}
-			// Extract pubkeys from p tags
-			for _, tag := range event.Event.Tags {
-				if len(tag) >= 2 && tag[0] == "p" {
-					following = append(following, tag[1])
-				}
-			}
-			break // Only need the first/latest contact list
-		}
-
-		return followingMsg{following}
-	}
-}
-
-func fetchProfilesCmd(pool *nostr.SimplePool, relays []string, pubkeys []string) tea.Cmd {
+func fetchFollowingCmd(c *client.Client) tea.Cmd {
 	return func() tea.Msg {
 		defer func() {
 			if r := recover(); r != nil {
@@ -2030,56 +1168,14 @@ func fetchProfilesCmd(pool *nostr.SimplePool, relays []string, pubkeys []string)
 			}
 		}()
 		
-		if len(pubkeys) == 0 {
-			return profilesMsg{profiles: make(map[string]string)}
-		}
-
-		// Limit batch size to avoid overwhelming relays
-		const maxBatchSize = 10
-		if len(pubkeys) > maxBatchSize {
-			pubkeys = pubkeys[:maxBatchSize]
-		}
-
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		// Fetch kind 0 (metadata) events
-		filter := nostr.Filter{
-			Kinds:   []int{0}, // Kind 0 = Metadata
-			Authors: pubkeys,
-		}
-
-		profiles := make(map[string]string)
-		eventsChannel := pool.SubManyEose(ctx, relays, []nostr.Filter{filter})
-		
-		for event := range eventsChannel {
-			if event.Event == nil {
-				continue
-			}
-			
-			// Parse metadata JSON
-			var metadata struct {
-				Name        string `json:"name"`
-				DisplayName string `json:"display_name"`
-				Username    string `json:"username"`
-			}
-			
-			if err := json.Unmarshal([]byte(event.Event.Content), &metadata); err == nil {
-				// Prefer display_name, then name, then username
-				displayName := metadata.DisplayName
-				if displayName == "" {
-					displayName = metadata.Name
-				}
-				if displayName == "" {
-					displayName = metadata.Username
-				}
-				if displayName != "" {
-					profiles[event.Event.PubKey] = displayName
-				}
-			}
+		following, err := c.FetchFollowing(ctx)
+		if err != nil {
+			log.Printf("⚠️  Failed to fetch following list: %v", err)
 		}
-
-		return profilesMsg{profiles}
+		return followingMsg{following}
 	}
 }
 
@@ -2099,7 +1195,7 @@ type threadEventsMsg struct {
 	events []nostr.Event
 }
 
-func fetchThreadCmd(pool *nostr.SimplePool, relays []string, rootEvent *nostr.Event) tea.Cmd {
+func fetchThreadCmd(c *client.Client, rootEvent *nostr.Event) tea.Cmd {
 	return func() tea.Msg {
 		defer func() {
 			if r := recover(); r != nil {
@@ -2110,35 +1206,15 @@ func fetchThreadCmd(pool *nostr.SimplePool, relays []string, rootEvent *nostr.Ev
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		// Fetch all replies to this event (events with 'e' tag pointing to rootEvent.ID)
-		filter := nostr.Filter{
-			Kinds: []int{nostr.KindTextNote}, // Kind 1 (notes/posts)
-			Tags: nostr.TagMap{
-				"e": []string{rootEvent.ID}, // Events that reference this ID
-			},
-			Limit: 100,
-		}
-
-		var events []nostr.Event
-		eventsChannel := pool.SubManyEose(ctx, relays, []nostr.Filter{filter})
-		
-		for event := range eventsChannel {
-			if event.Event == nil {
-				continue
-			}
-			events = append(events, *event.Event)
+		events, err := c.FetchThread(ctx, rootEvent)
+		if err != nil {
+			log.Printf("⚠️  Failed to fetch thread: %v", err)
 		}
-		
-		// Sort by timestamp (oldest first for thread reading)
-		sort.Slice(events, func(i, j int) bool {
-			return events[i].CreatedAt < events[j].CreatedAt
-		})
-		
 		return threadEventsMsg{events: events}
 	}
 }
 
-func fetchEventsCmd(pool *nostr.SimplePool, relays []string, following []string) tea.Cmd {
+func fetchEventsCmd(c *client.Client, following []string) tea.Cmd {
 	return func() tea.Msg {
 		defer func() {
 			if r := recover(); r != nil {
@@ -2149,31 +1225,15 @@ func fetchEventsCmd(pool *nostr.SimplePool, relays []string, following []string)
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		filter := nostr.Filter{
-			Kinds: []int{nostr.KindTextNote}, // Kind 1 (notes/posts)
-			Limit: 50,
-		}
-
-		// If we have a following list, filter by those authors
-		if len(following) > 0 {
-			filter.Authors = following
-		}
-
-		var events []nostr.Event
-		eventsChannel := pool.SubManyEose(ctx, relays, []nostr.Filter{filter})
-		
-		for event := range eventsChannel {
-			if event.Event == nil {
-				continue
-			}
-			events = append(events, *event.Event)
+		events, err := c.FetchFeed(ctx, following)
+		if err != nil {
+			log.Printf("⚠️  Failed to fetch timeline: %v", err)
 		}
-
 		return eventsMsg{events}
 	}
 }
 
-func fetchDMsCmd(pool *nostr.SimplePool, relays []string, pubKey string) tea.Cmd {
+func fetchDMsCmd(c *client.Client) tea.Cmd {
 	return func() tea.Msg {
 		defer func() {
 			if r := recover(); r != nil {
@@ -2184,45 +1244,15 @@ func fetchDMsCmd(pool *nostr.SimplePool, relays []string, pubKey string) tea.Cmd
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		// Fetch kind 4 (NIP-04) and kind 1059 (NIP-17 gift wrap) DMs where we are recipient
-		filter := nostr.Filter{
-			Kinds: []int{4, 1059}, // Kind 4 = NIP-04, Kind 1059 = NIP-17 gift wrap
-			Limit: 50,
-		}
-		
-		// We want DMs where we're either the author OR in the 'p' tag (recipient)
-		filter.Tags = nostr.TagMap{"p": []string{pubKey}}
-
-		var dms []nostr.Event
-		eventsChannel := pool.SubManyEose(ctx, relays, []nostr.Filter{filter})
-		
-		for event := range eventsChannel {
-			if event.Event == nil {
-				continue
-			}
-			dms = append(dms, *event.Event)
-		}
-
-		// Also get DMs we sent
-		filter2 := nostr.Filter{
-			Kinds:   []int{4},
-			Authors: []string{pubKey},
-			Limit:   50,
-		}
-		
-		eventsChannel2 := pool.SubManyEose(ctx, relays, []nostr.Filter{filter2})
-		for event := range eventsChannel2 {
-			if event.Event == nil {
-				continue
-			}
-			dms = append(dms, *event.Event)
+		dms, err := c.FetchDMs(ctx)
+		if err != nil {
+			log.Printf("⚠️  Failed to fetch DMs: %v", err)
 		}
-
 		return dmsMsg{dms}
 	}
 }
 
-func fetchNotificationsCmd(pool *nostr.SimplePool, relays []string, pubKey string) tea.Cmd {
+func fetchNotificationsCmd(c *client.Client) tea.Cmd {
 	return func() tea.Msg {
 		defer func() {
 			if r := recover(); r != nil {
@@ -2233,39 +1263,10 @@ func fetchNotificationsCmd(pool *nostr.SimplePool, relays []string, pubKey strin
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		// Fetch notifications: mentions (kind 1 with 'p' tag), replies, reactions (kind 7)
-		var notifications []nostr.Event
-		
-		// Get mentions and replies (kind 1 events that tag us)
-		filter1 := nostr.Filter{
-			Kinds: []int{1}, // Kind 1 = Text note
-			Tags:  nostr.TagMap{"p": []string{pubKey}},
-			Limit: 30,
-		}
-		
-		eventsChannel := pool.SubManyEose(ctx, relays, []nostr.Filter{filter1})
-		for event := range eventsChannel {
-			if event.Event == nil {
-				continue
-			}
-			notifications = append(notifications, *event.Event)
-		}
-		
-		// Get reactions to our posts (kind 7)
-		filter2 := nostr.Filter{
-			Kinds: []int{7}, // Kind 7 = Reaction
-			Tags:  nostr.TagMap{"p": []string{pubKey}},
-			Limit: 20,
-		}
-		
-		eventsChannel2 := pool.SubManyEose(ctx, relays, []nostr.Filter{filter2})
-		for event := range eventsChannel2 {
-			if event.Event == nil {
-				continue
-			}
-			notifications = append(notifications, *event.Event)
+		notifications, err := c.FetchNotifications(ctx)
+		if err != nil {
+			log.Printf("⚠️  Failed to fetch notifications: %v", err)
 		}
-
 		return notificationsMsg{notifications}
 	}
 }
@@ -2422,578 +1423,64 @@ type publishSuccessMsg struct {
 	status  string // Optional custom status message
 }
 
-func publishPostCmd(signer *signer.PlebSigner, pool *nostr.SimplePool, relays []string, pubKey string, content string, replyTo *nostr.Event, authMethod string, privKey string) tea.Cmd {
+func publishPostCmd(c *client.Client, content string, replyTo *nostr.Event, zapSplits []zap.Split) tea.Cmd {
 	return func() tea.Msg {
-		// Create event WITHOUT pubkey - let Pleb Signer set everything
-		evt := nostr.Event{
-			Kind:      nostr.KindTextNote,
-			Content:   content,
-			CreatedAt: nostr.Now(),
-			Tags:      nostr.Tags{},
-		}
-		
-		// Add reply tags if this is a reply
-		if replyTo != nil {
-			// Add 'e' tag for event being replied to
-			evt.Tags = append(evt.Tags, nostr.Tag{"e", replyTo.ID, "", "reply"})
-			// Add 'p' tag for author being replied to
-			evt.Tags = append(evt.Tags, nostr.Tag{"p", replyTo.PubKey})
-			
-			// If original event has 'e' tags, add root tag
-			for _, tag := range replyTo.Tags {
-				if len(tag) >= 2 && tag[0] == "e" {
-					evt.Tags = append(evt.Tags, nostr.Tag{"e", tag[1], "", "root"})
-					break
-				}
-			}
-		}
-		
-		// Sign event 
-		var err error
-		if authMethod == "nsec" && privKey != "" {
-			err = evt.Sign(privKey)
-		} else {
-			err = signer.SignEvent(&evt)
-		}
-		if err != nil {
-			return errMsg{fmt.Errorf("failed to sign event: %w", err)}
-		}
-		
-		// Publish to relays
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		
-		results := pool.PublishMany(ctx, relays, evt)
-		
-		// Collect results and track successes/failures
-		successCount := 0
-		var lastErr error
-		for result := range results {
-			if result.Error == nil {
-				successCount++
-			} else {
-				lastErr = result.Error
-			}
-		}
-		
-		if successCount == 0 {
-			if lastErr != nil {
-				return errMsg{fmt.Errorf("failed to publish: %w", lastErr)}
-			}
-			return errMsg{fmt.Errorf("failed to publish to any relay (no results)")}
+		evt, err := c.Post(ctx, content, replyTo, zapSplits)
+		if err != nil {
+			return errMsg{err}
 		}
-		
 		return publishSuccessMsg{eventID: evt.ID}
 	}
 }
 
-func publishDMCmd(signer *signer.PlebSigner, pool *nostr.SimplePool, relays []string, pubKey string, recipientPubKey string, content string, authMethod string, privKey string) tea.Cmd {
+// publishDMCmd sends a NIP-17 DM when we hold the key, or a NIP-04 DM
+// through Pleb Signer
+func publishDMCmd(c *client.Client, recipientPubKey string, content string) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		
-		// Use NIP-17 (gift wrap) if using nsec, NIP-04 if using Pleb Signer
-		if authMethod == "nsec" && privKey != "" {
-			// Send NIP-17 gift-wrapped DM with NIP-44 encryption
-			// Create the rumor (unsigned event with actual DM content)
-			rumor := nostr.Event{
-				Kind:      nostr.KindDirectMessage, // Kind 14
-				Content:   content,
-				CreatedAt: nostr.Now(),
-				Tags:      nostr.Tags{nostr.Tag{"p", recipientPubKey}},
-				PubKey:    pubKey,
-			}
-			rumor.ID = rumor.GetID()
-			
-			// Create encrypt function for gift wrapping
-			encrypt := func(plaintext string, targetPubkey string) (string, error) {
-				conversationKey, err := nip44.GenerateConversationKey(targetPubkey, privKey)
-				if err != nil {
-					return "", err
-				}
-				return nip44.Encrypt(plaintext, conversationKey)
-			}
-			
-			// Create sign function
-			sign := func(evt *nostr.Event) error {
-				return evt.Sign(privKey)
-			}
-			
-			// Gift wrap to recipient
-			giftWrapToRecipient, err := nip59.GiftWrap(
-				rumor,
-				recipientPubKey,
-				func(plaintext string) (string, error) {
-					return encrypt(plaintext, recipientPubKey)
-				},
-				sign,
-				nil,
-			)
-			if err != nil {
-				return errMsg{fmt.Errorf("failed to create gift wrap for recipient: %w", err)}
-			}
-			
-			// Gift wrap to ourselves (so we can see it in our DM list)
-			giftWrapToUs, err := nip59.GiftWrap(
-				rumor,
-				pubKey,
-				func(plaintext string) (string, error) {
-					return encrypt(plaintext, pubKey)
-				},
-				sign,
-				nil,
-			)
-			if err != nil {
-				return errMsg{fmt.Errorf("failed to create gift wrap for self: %w", err)}
-			}
-			
-			// Publish both gift wraps
-			successCount := 0
-			var lastErr error
-			
-			// Publish to recipient
-			results := pool.PublishMany(ctx, relays, giftWrapToRecipient)
-			for result := range results {
-				if result.Error == nil {
-					successCount++
-				} else {
-					lastErr = result.Error
-				}
-			}
-			
-			// Publish to ourselves
-			results = pool.PublishMany(ctx, relays, giftWrapToUs)
-			for result := range results {
-				if result.Error == nil {
-					successCount++
-				} else {
-					lastErr = result.Error
-				}
-			}
-			
-			if successCount == 0 {
-				if lastErr != nil {
-					return errMsg{fmt.Errorf("failed to publish NIP-17 DM: %w", lastErr)}
-				}
-				return errMsg{fmt.Errorf("failed to publish NIP-17 DM to any relay")}
-			}
-			
-			return publishSuccessMsg{eventID: giftWrapToRecipient.ID, status: "DM sent (NIP-17) ✓"}
-		} else {
-			// Send NIP-04 encrypted DM (for Pleb Signer)
-			var encrypted string
-			var err error
-			
-			// Use Pleb Signer
-			encrypted, err = signer.Nip04Encrypt(recipientPubKey, content)
-			if err != nil {
-				return errMsg{fmt.Errorf("failed to encrypt DM: %w", err)}
-			}
-			
-			// Create DM event
-			evt := nostr.Event{
-				Kind:      nostr.KindEncryptedDirectMessage,
-				Content:   encrypted,
-				CreatedAt: nostr.Now(),
-				Tags:      nostr.Tags{nostr.Tag{"p", recipientPubKey}},
-			}
-			
-			// Sign event with Pleb Signer
-			err = signer.SignEvent(&evt)
-			if err != nil {
-				return errMsg{fmt.Errorf("failed to sign DM: %w", err)}
-			}
-			
-			// Publish to relays
-			results := pool.PublishMany(ctx, relays, evt)
-			
-			// Collect results and track successes/failures
-			successCount := 0
-			var lastErr error
-			for result := range results {
-				if result.Error == nil {
-					successCount++
-				} else {
-					lastErr = result.Error
-				}
-			}
-			
-			if successCount == 0 {
-				if lastErr != nil {
-					return errMsg{fmt.Errorf("failed to publish NIP-04 DM: %w", lastErr)}
-				}
-				return errMsg{fmt.Errorf("failed to publish DM to any relay (no results)")}
-			}
-			
-			return publishSuccessMsg{eventID: evt.ID, status: "DM sent (NIP-04) ✓"}
+		evt, err := c.SendDM(ctx, recipientPubKey, content)
+		if err != nil {
+			return errMsg{err}
 		}
+		if evt.Kind == nostr.KindGiftWrap {
+			return publishSuccessMsg{eventID: evt.ID, status: "DM sent (NIP-17) ✓"}
+		}
+		return publishSuccessMsg{eventID: evt.ID, status: "DM sent (NIP-04) ✓"}
 	}
 }
 
-func repostCmd(signer *signer.PlebSigner, pool *nostr.SimplePool, relays []string, pubKey string, originalEvent *nostr.Event) tea.Cmd {
+func repostCmd(c *client.Client, originalEvent *nostr.Event) tea.Cmd {
 return func() tea.Msg {
-// Create kind 6 repost event
-evt := nostr.Event{
-Kind:      nostr.KindRepost,
-Content:   "",
-CreatedAt: nostr.Now(),
-Tags: nostr.Tags{
-nostr.Tag{"e", originalEvent.ID},
-nostr.Tag{"p", originalEvent.PubKey},
-},
-}
-
-// Sign event
-err := signer.SignEvent(&evt)
-if err != nil {
-return errMsg{fmt.Errorf("failed to sign repost: %w", err)}
-}
-
-// Publish to relays
 ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 defer cancel()
 
-results := pool.PublishMany(ctx, relays, evt)
-
-successCount := 0
-var lastErr error
-for result := range results {
-if result.Error == nil {
-successCount++
-} else {
-lastErr = result.Error
-}
-}
-
-if successCount == 0 {
-if lastErr != nil {
-return errMsg{fmt.Errorf("failed to repost: %w", lastErr)}
-}
-return errMsg{fmt.Errorf("failed to repost to any relay")}
+evt, err := c.Repost(ctx, originalEvent)
+if err != nil {
+return errMsg{err}
 }
-
 return publishSuccessMsg{eventID: evt.ID}
 }
 }
 
 
-func publishQuoteCmd(signer *signer.PlebSigner, pool *nostr.SimplePool, relays []string, pubKey string, content string, quotedEvent *nostr.Event) tea.Cmd {
+func publishQuoteCmd(c *client.Client, content string, quotedEvent *nostr.Event, zapSplits []zap.Split) tea.Cmd {
 return func() tea.Msg {
-// Create kind 1 quote post with nostr: reference
-nevent, _ := nip19.EncodeEvent(quotedEvent.ID, []string{}, quotedEvent.PubKey)
-
-// Add the quote reference to content
-quoteContent := content + "\nnostr:" + nevent
-
-evt := nostr.Event{
-Kind:      nostr.KindTextNote,
-Content:   quoteContent,
-CreatedAt: nostr.Now(),
-Tags: nostr.Tags{
-nostr.Tag{"e", quotedEvent.ID, "", "mention"},
-nostr.Tag{"p", quotedEvent.PubKey},
-},
-}
-
-// Sign event
-err := signer.SignEvent(&evt)
-if err != nil {
-return errMsg{fmt.Errorf("failed to sign quote: %w", err)}
-}
-
-// Publish to relays
 ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 defer cancel()
 
-results := pool.PublishMany(ctx, relays, evt)
-
-successCount := 0
-var lastErr error
-for result := range results {
-if result.Error == nil {
-successCount++
-} else {
-lastErr = result.Error
-}
-}
-
-if successCount == 0 {
-if lastErr != nil {
-return errMsg{fmt.Errorf("failed to publish quote: %w", lastErr)}
-}
-return errMsg{fmt.Errorf("failed to publish quote to any relay")}
+evt, err := c.Quote(ctx, content, quotedEvent, zapSplits)
+if err != nil {
+return errMsg{err}
 }
-
 return publishSuccessMsg{eventID: evt.ID}
 }
 }
 
-func (m Model) renderLanding() string {
-titleStyle := lipgloss.NewStyle().
-Bold(true).
-Foreground(lipgloss.Color("205")).
-Align(lipgloss.Center).
-Width(m.width)
-
-versionStyle := lipgloss.NewStyle().
-Foreground(lipgloss.Color("241")).
-Align(lipgloss.Center).
-Width(m.width)
-
-authorStyle := lipgloss.NewStyle().
-Foreground(lipgloss.Color("214")).
-Align(lipgloss.Center).
-Width(m.width)
-
-linkStyle := lipgloss.NewStyle().
-Foreground(lipgloss.Color("63")).
-Align(lipgloss.Center).
-Width(m.width)
-
-menuStyle := lipgloss.NewStyle().
-Foreground(lipgloss.Color("252")).
-Align(lipgloss.Center).
-Width(m.width)
-
-selectedStyle := lipgloss.NewStyle().
-Bold(true).
-Foreground(lipgloss.Color("205")).
-Background(lipgloss.Color("236")).
-Align(lipgloss.Center).
-Width(m.width)
-
-var content strings.Builder
-content.WriteString("\n\n")
-content.WriteString(titleStyle.Render("╔═══════════════════════════════════╗"))
-content.WriteString("\n")
-content.WriteString(titleStyle.Render("║                                   ║"))
-content.WriteString("\n")
-content.WriteString(titleStyle.Render("║            N O S C L I            ║"))
-content.WriteString("\n")
-content.WriteString(titleStyle.Render("║                                   ║"))
-content.WriteString("\n")
-content.WriteString(titleStyle.Render("╚═══════════════════════════════════╝"))
-content.WriteString("\n\n")
-content.WriteString(versionStyle.Render("Version " + Version))
-content.WriteString("\n\n")
-content.WriteString(authorStyle.Render("Made by Tim from Pleb.one"))
-content.WriteString("\n\n")
-content.WriteString(linkStyle.Render("🔗 Pleb Signer: https://github.com/PlebOne/Pleb_Signer"))
-content.WriteString("\n")
-content.WriteString(linkStyle.Render("🔗 Noscli: https://github.com/PlebOne/Noscli"))
-content.WriteString("\n\n\n")
-
-if m.landingChoice == 0 {
-content.WriteString(selectedStyle.Render("► Open Client"))
-content.WriteString("\n")
-content.WriteString(menuStyle.Render("  Settings"))
-} else {
-content.WriteString(menuStyle.Render("  Open Client"))
-content.WriteString("\n")
-content.WriteString(selectedStyle.Render("► Settings"))
-}
-
-content.WriteString("\n\n\n")
-content.WriteString(versionStyle.Render("Use ↑/↓ or j/k to navigate • Enter to select • q to quit"))
-
-return content.String()
-}
-
-
-func (m Model) renderSettings() string {
-titleStyle := lipgloss.NewStyle().
-Bold(true).
-Foreground(lipgloss.Color("205")).
-Padding(1, 0)
-
-headerStyle := lipgloss.NewStyle().
-Foreground(lipgloss.Color("214")).
-Bold(true).
-Padding(1, 0)
-
-itemStyle := lipgloss.NewStyle().
-Foreground(lipgloss.Color("252")).
-Padding(0, 2)
-
-selectedStyle := lipgloss.NewStyle().
-Foreground(lipgloss.Color("205")).
-Bold(true).
-Background(lipgloss.Color("236")).
-Padding(0, 2)
-
-activeStyle := lipgloss.NewStyle().
-Foreground(lipgloss.Color("46")).
-Bold(true).
-Padding(0, 2)
-
-tabStyle := lipgloss.NewStyle().
-Foreground(lipgloss.Color("241")).
-Padding(0, 2)
-
-activeTabStyle := lipgloss.NewStyle().
-Foreground(lipgloss.Color("205")).
-Bold(true).
-Padding(0, 2)
-
-footerStyle := lipgloss.NewStyle().
-Foreground(lipgloss.Color("241")).
-Padding(1, 0)
-
-var content strings.Builder
-content.WriteString(titleStyle.Render("⚙️  SETTINGS"))
-content.WriteString("\n\n")
-
-// Tab indicators
-if m.settingsMenu == 0 {
-content.WriteString(activeTabStyle.Render("[ Authentication ]"))
-content.WriteString(tabStyle.Render("  Relays  "))
-content.WriteString(tabStyle.Render("  Wallet  "))
-} else if m.settingsMenu == 1 {
-content.WriteString(tabStyle.Render("  Authentication  "))
-content.WriteString(activeTabStyle.Render("[ Relays ]"))
-content.WriteString(tabStyle.Render("  Wallet  "))
-} else {
-content.WriteString(tabStyle.Render("  Authentication  "))
-content.WriteString(tabStyle.Render("  Relays  "))
-content.WriteString(activeTabStyle.Render("[ Wallet ]"))
-}
-content.WriteString(footerStyle.Render("  (Tab to switch)"))
-content.WriteString("\n\n")
-
-if m.settingsMenu == 0 {
-// Authentication menu
-content.WriteString(headerStyle.Render("Choose Authentication Method:"))
-content.WriteString("\n\n")
-
-// Pleb Signer option
-authIndicator := ""
-if m.authMethod == "pleb_signer" {
-authIndicator = " ✓"
-}
-if m.settingsCursor == 0 {
-content.WriteString(selectedStyle.Render(fmt.Sprintf("► Pleb Signer (DBus)%s", authIndicator)))
-} else {
-if m.authMethod == "pleb_signer" {
-content.WriteString(activeStyle.Render(fmt.Sprintf("  Pleb Signer (DBus)%s", authIndicator)))
-} else {
-content.WriteString(itemStyle.Render("  Pleb Signer (DBus)"))
-}
-}
-content.WriteString("\n")
-
-// Nsec option
-nsecIndicator := ""
-if m.authMethod == "nsec" {
-nsecIndicator = " ✓"
-}
-if m.settingsCursor == 1 {
-content.WriteString(selectedStyle.Render(fmt.Sprintf("► Private Key (nsec)%s", nsecIndicator)))
-} else {
-if m.authMethod == "nsec" {
-content.WriteString(activeStyle.Render(fmt.Sprintf("  Private Key (nsec)%s", nsecIndicator)))
-} else {
-content.WriteString(itemStyle.Render("  Private Key (nsec)"))
-}
-}
-content.WriteString("\n")
-
-if m.editingNsec {
-content.WriteString("\n")
-content.WriteString(headerStyle.Render("Enter your nsec key (paste supported):"))
-content.WriteString("\n")
-maskedKey := strings.Repeat("*", len(m.nsecKey))
-content.WriteString(itemStyle.Render(fmt.Sprintf("> %s_", maskedKey)))
-content.WriteString("\n")
-content.WriteString(footerStyle.Render("Enter to save • Esc to cancel • Ctrl+Shift+V to paste"))
-} else {
-content.WriteString("\n\n")
-
-// Show status messages
-if m.statusMsg != "" {
-statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Padding(0, 2)
-content.WriteString(statusStyle.Render(m.statusMsg))
-content.WriteString("\n")
-}
-
-if m.authMethod == "" {
-content.WriteString(footerStyle.Render("⚠️  Please select an authentication method before starting client"))
-content.WriteString("\n")
-}
-if m.authMethod != "" {
-content.WriteString(footerStyle.Render("✓ Auth set! Press Tab to view/edit Relays"))
-content.WriteString("\n")
-}
-content.WriteString(footerStyle.Render("↑/↓ nav • Enter select • Tab → Relays • Esc back"))
-}
-
-} else if m.settingsMenu == 1 {
-// Relays menu
-content.WriteString(headerStyle.Render("Nostr Relays:"))
-content.WriteString("\n\n")
-
-for i, relay := range m.relays {
-if i == m.settingsCursor {
-content.WriteString(selectedStyle.Render(fmt.Sprintf("► %s", relay)))
-} else {
-content.WriteString(itemStyle.Render(fmt.Sprintf("  %s", relay)))
-}
-content.WriteString("\n")
-}
-
-if m.editingRelay {
-content.WriteString("\n")
-content.WriteString(headerStyle.Render("Add New Relay:"))
-content.WriteString("\n")
-content.WriteString(itemStyle.Render(fmt.Sprintf("> %s_", m.newRelayInput)))
-content.WriteString("\n")
-content.WriteString(footerStyle.Render("Enter to add • Esc to cancel"))
-} else {
-content.WriteString("\n")
-if m.authMethod == "" {
-content.WriteString(footerStyle.Render("⚠️  Set authentication method first (Tab to switch)"))
-content.WriteString("\n")
-content.WriteString(footerStyle.Render("↑/↓ navigate • a add relay • d/x delete • Esc/q back"))
-} else {
-content.WriteString(footerStyle.Render("↑/↓ navigate • a add relay • d/x delete • Enter start client • Esc/q back"))
-}
-}
-
-} else {
-// Wallet menu
-content.WriteString(headerStyle.Render("Nostr Wallet Connect (NWC):"))
-content.WriteString("\n\n")
-
-if m.editingNWC {
-content.WriteString(headerStyle.Render("Enter NWC connection string:"))
-content.WriteString("\n")
-// Show masked connection string
-maskedNWC := strings.Repeat("*", len(m.nwcString))
-content.WriteString(itemStyle.Render(fmt.Sprintf("> %s_", maskedNWC)))
-content.WriteString("\n")
-content.WriteString(footerStyle.Render("Enter to save • Esc to cancel • Paste nostr+walletconnect://..."))
-} else {
-if m.nwcString != "" {
-content.WriteString(activeStyle.Render("✓ NWC Connected"))
-content.WriteString("\n\n")
-content.WriteString(itemStyle.Render("Press 'e' to edit or 'd' to delete"))
-} else {
-content.WriteString(itemStyle.Render("Press 'e' to add NWC connection string"))
-content.WriteString("\n\n")
-content.WriteString(footerStyle.Render("💡 Get your NWC string from Alby, Mutiny, or your wallet"))
-}
-content.WriteString("\n\n")
-content.WriteString(footerStyle.Render("Tab to switch • Esc/q back"))
-
-// Add config file location hint at the bottom
-configPath, _ := config.GetConfigPath()
-content.WriteString("\n")
-content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(fmt.Sprintf("Config: %s", configPath)))
-}
-}
-
-return content.String()
-}
-
 func connectToPlebSignerCmd(signer *signer.PlebSigner) tea.Cmd {
 return func() tea.Msg {
 pubKey, err := signer.GetPublicKey()
@@ -3036,211 +1523,82 @@ pubKey  string
 privKey string
 }
 
-type zapSuccessMsg struct{}
+type zapSuccessMsg struct {
+	wallet     string // Name of the wallet that paid
+	amountSats int64  // Total paid across all recipients
+	eventID    string // Zapped note
+	recipients int    // How many recipients the zap was split between
+	failures   []zapFailure // Split recipients that couldn't be paid
+	paid       []zapPaid    // Recipients that were paid, with the wallet's result
+}
 
 type zapErrorMsg struct {
 err error
 }
 
-// signEvent signs an event using either Pleb Signer or direct nsec key
-
-// signEvent signs an event using either Pleb Signer or direct nsec key
-func (m *Model) signEvent(evt *nostr.Event) error {
-if m.authMethod == "nsec" && m.privKey != "" {
-// Sign directly with private key
-return evt.Sign(m.privKey)
-} else {
-// Use Pleb Signer
-return m.signer.SignEvent(evt)
-}
-}
-
-// unwrapGiftWrapDM unwraps a NIP-17 gift-wrapped DM (kind 1059)
-func (m *Model) unwrapGiftWrapDM(giftWrapEvent nostr.Event) (string, error) {
-	if m.authMethod == "nsec" && m.privKey != "" {
-		// Decrypt directly with private key using NIP-44
-		// Create decrypt function that NIP-59 expects
-		decrypt := func(otherPubkey, ciphertext string) (string, error) {
-			// Generate conversation key for NIP-44
-			conversationKey, err := nip44.GenerateConversationKey(otherPubkey, m.privKey)
-			if err != nil {
-				return "", fmt.Errorf("failed to generate conversation key: %w", err)
-			}
-			// Decrypt with NIP-44
-			plaintext, err := nip44.Decrypt(ciphertext, conversationKey)
-			if err != nil {
-				return "", fmt.Errorf("failed to decrypt: %w", err)
-			}
-			return plaintext, nil
-		}
-		
-		// Use nip59.GiftUnwrap to unwrap the gift-wrapped DM
-		rumor, err := nip59.GiftUnwrap(giftWrapEvent, decrypt)
-		if err != nil {
-			return "", fmt.Errorf("failed to unwrap gift: %w", err)
-		}
-		
-		// The rumor contains the actual DM content
-		return rumor.Content, nil
-	} else {
-		// Pleb Signer doesn't support NIP-44 yet
-		return "", fmt.Errorf("NIP-17 DMs require nsec authentication (Pleb Signer NIP-44 support coming soon)")
-	}
-}
-
-// decryptDM decrypts a DM using either Pleb Signer or direct nsec key
-func (m *Model) decryptDM(ciphertext, otherPubkey string) (string, error) {
-	if m.authMethod == "nsec" && m.privKey != "" {
-		// Decrypt directly with private key using NIP-04
-		sharedSecret, err := nip04.ComputeSharedSecret(otherPubkey, m.privKey)
-		if err != nil {
-			return "", err
-		}
-		plaintext, err := nip04.Decrypt(ciphertext, sharedSecret)
-		if err != nil {
-			return "", err
-		}
-		return plaintext, nil
-	} else {
-		// Use Pleb Signer (note: signature is senderPubKey first, then ciphertext)
-		return m.signer.Nip04Decrypt(otherPubkey, ciphertext)
-	}
-}
-
-// performZapCmd executes a zap payment
 // performZap creates a zap payment using persistent NWC subscription
-func (m *Model) performZap(event *nostr.Event, amountSats int64) tea.Cmd {
+func (a *appContext) performZap(subject zapSubject, payments []zapPayment, opts zapOptions) tea.Cmd {
 	return func() tea.Msg {
-		log.Printf("🚀 Starting zap flow: amount=%d sats, auth=%s", amountSats, m.authMethod)
+		log.Printf("🚀 Starting zap flow: amount=%d sats, recipients=%d, auth=%s, anon=%v", opts.amountSats, len(payments), a.authMethod, opts.anon)
 		ctx := context.Background()
 
-		// Get the recipient's profile metadata to find lightning address
-		var lightningAddress string
-		
-		log.Printf("🔍 Fetching recipient profile for lightning address...")
-		filters := []nostr.Filter{{
-			Kinds:   []int{0},
-			Authors: []string{event.PubKey},
-			Limit:   1,
-		}}
-
-		pool := nostr.NewSimplePool(ctx)
-		eventChan := pool.SubManyEose(ctx, m.relays, filters)
-		
-		timeout := time.After(5 * time.Second)
-		select {
-		case evt := <-eventChan:
-			if evt.Event != nil {
-				lightningAddress = zap.GetLightningAddress(evt.Content)
-				log.Printf("✅ Found lightning address: %s", lightningAddress)
+		// One zap request and invoice per recipient; keep going if one fails
+		var paid int64
+		var settled []zapPaid
+		var failures []zapFailure
+		for _, payment := range payments {
+			err := payment.err
+			var result *zapPaid
+			if err == nil {
+				result, err = a.zapRecipient(ctx, subject, payment, opts)
 			}
-		case <-timeout:
-			log.Printf("⏱️  Profile fetch timeout")
-		}
-
-		if lightningAddress == "" {
-			log.Printf("❌ No lightning address found")
-			return zapErrorMsg{err: fmt.Errorf("recipient has no lightning address in profile")}
-		}
-
-		// Get LNURL endpoint
-		log.Printf("🔗 Getting LNURL endpoint...")
-		lnurlEndpoint, err := zap.GetLNURL(lightningAddress)
-		if err != nil {
-			log.Printf("❌ Invalid lightning address: %v", err)
-			return zapErrorMsg{err: fmt.Errorf("invalid lightning address: %w", err)}
-		}
-		log.Printf("✅ LNURL endpoint: %s", lnurlEndpoint)
-
-		// Fetch LNURL info
-		log.Printf("📡 Fetching LNURL info...")
-		lnurlInfo, err := zap.FetchLNURLPayInfo(lnurlEndpoint)
-		if err != nil {
-			log.Printf("❌ Failed to fetch LNURL info: %v", err)
-			return zapErrorMsg{err: fmt.Errorf("failed to fetch LNURL info: %w", err)}
-		}
-		log.Printf("✅ LNURL callback: %s", lnurlInfo.Callback)
-
-		// Create zap request
-		log.Printf("📝 Creating zap request (auth: %s)...", m.authMethod)
-		var zapRequest *nostr.Event
-		if m.authMethod == "nsec" && m.privKey != "" {
-			zapRequest, err = zap.CreateZapRequest(event.PubKey, event.ID, "⚡", amountSats, m.relays, m.privKey)
 			if err != nil {
-				log.Printf("❌ Failed to create zap request: %v", err)
-				return zapErrorMsg{err: fmt.Errorf("failed to create zap request: %w", err)}
-			}
-		} else {
-			amountMsats := amountSats * 1000
-			zapRequest = &nostr.Event{
-				PubKey:    m.pubKey,
-				CreatedAt: nostr.Now(),
-				Kind:      9734,
-				Tags: nostr.Tags{
-					nostr.Tag{"p", event.PubKey},
-					nostr.Tag{"amount", fmt.Sprintf("%d", amountMsats)},
-					nostr.Tag{"e", event.ID},
-				},
-				Content: "⚡",
-			}
-			for _, relay := range m.relays {
-				zapRequest.Tags = append(zapRequest.Tags, nostr.Tag{"relays", relay})
-			}
-			if err := m.signer.SignEvent(zapRequest); err != nil {
-				log.Printf("❌ Failed to sign zap request: %v", err)
-				return zapErrorMsg{err: fmt.Errorf("failed to sign zap request: %w", err)}
+				failures = append(failures, zapFailure{pubkey: payment.pubkey, err: err})
+				continue
 			}
+			paid += payment.amountSats
+			settled = append(settled, *result)
 		}
-		log.Printf("✅ Zap request created and signed")
-
-		// Get invoice
-		log.Printf("💰 Requesting invoice...")
-		invoice, err := zap.GetInvoice(lnurlInfo.Callback, amountSats, zapRequest)
-		if err != nil {
-			log.Printf("❌ Failed to get invoice: %v", err)
-			return zapErrorMsg{err: fmt.Errorf("failed to get invoice: %w", err)}
-		}
-		log.Printf("✅ Invoice received: %s...", invoice[:20])
 
-		// Pay invoice via NWC
-		log.Printf("💸 Paying invoice via NWC (auth: %s)...", m.authMethod)
-		if m.authMethod == "nsec" && m.privKey != "" {
-			// Use NWC client with private key (old method)
-			log.Printf("🔑 Creating NWC client with nsec...")
-			nwcClient, err := nwc.NewNWCClient(m.nwcString)
-			if err != nil {
-				log.Printf("❌ Failed to create NWC client: %v", err)
-				return zapErrorMsg{err: fmt.Errorf("failed to create NWC client: %w", err)}
-			}
-			log.Printf("✅ NWC client created, calling PayInvoice...")
-			if err := nwcClient.PayInvoice(ctx, invoice); err != nil {
-				log.Printf("❌ PayInvoice failed: %v", err)
-				return zapErrorMsg{err: fmt.Errorf("failed to pay invoice: %w", err)}
-			}
-		} else {
-			// Use persistent NWC subscription with Pleb Signer
-			log.Printf("🔏 Using persistent NWC subscription with Pleb Signer...")
-			if err := m.payInvoiceWithPersistentSub(ctx, invoice); err != nil {
-				log.Printf("❌ Payment failed: %v", err)
-				return zapErrorMsg{err: fmt.Errorf("failed to pay invoice: %w", err)}
+		if paid == 0 {
+			if len(failures) == 1 {
+				return zapErrorMsg{err: failures[0].err}
 			}
+			return zapErrorMsg{err: fmt.Errorf("all %d recipients failed, first: %w", len(failures), failures[0].err)}
 		}
 
-		log.Printf("🎉 Zap successful!")
-		return zapSuccessMsg{}
+		log.Printf("🎉 Zap successful! (%d/%d recipients)", len(payments)-len(failures), len(payments))
+		return zapSuccessMsg{wallet: opts.wallet.Name, amountSats: paid, eventID: subject.eventID, recipients: len(payments), failures: failures, paid: settled}
+	}
+}
+
+// zapRecipient zaps one recipient's share: zap request, invoice, payment
+func (a *appContext) zapRecipient(ctx context.Context, subject zapSubject, payment zapPayment, opts zapOptions) (*zapPaid, error) {
+	pay := client.PayerFunc(func(ctx context.Context, invoice string) (*nwc.PayInvoiceResult, error) {
+		return a.payInvoice(ctx, invoice, opts.wallet)
+	})
+	result, err := a.client().Zap(ctx, payment.target, client.ZapOptions{
+		EventID:    subject.eventID,
+		AmountSats: payment.amountSats,
+		Comment:    opts.comment,
+		Anon:       opts.anon,
+	}, pay)
+	if err != nil {
+		return nil, err
 	}
+	return &zapPaid{pubkey: payment.pubkey, amountSats: payment.amountSats, invoice: result.Invoice, preimage: result.Preimage, feesMsats: result.FeesMsats}, nil
 }
 
 // payInvoiceWithPersistentSub uses the persistent NWC subscription to pay an invoice
-func (m *Model) payInvoiceWithPersistentSub(ctx context.Context, invoice string) error {
-	if !m.nwcActive {
-		return fmt.Errorf("NWC subscription not active")
+func (a *appContext) payInvoiceWithPersistentSub(ctx context.Context, invoice string) (*nwc.PayInvoiceResult, error) {
+	if !a.nwcActive {
+		return nil, fmt.Errorf("NWC subscription not active")
 	}
 	
 	log.Printf("📋 [Persistent NWC] Parsing NWC string...")
-	walletPubkey, relay, _, err := nwc.ParseNWCString(m.nwcString)
+	walletPubkey, relay, _, err := nwc.ParseNWCString(a.nwcString)
 	if err != nil {
-		return fmt.Errorf("failed to parse NWC string: %w", err)
+		return nil, fmt.Errorf("failed to parse NWC string: %w", err)
 	}
 	log.Printf("✅ [Persistent NWC] Wallet: %s, Relay: %s", walletPubkey[:8], relay)
 
@@ -3254,20 +1612,20 @@ func (m *Model) payInvoiceWithPersistentSub(ctx context.Context, invoice string)
 
 	contentBytes, err := json.Marshal(requestContent)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 	log.Printf("📦 [Persistent NWC] Request: %s", string(contentBytes))
 
 	// Encrypt with Pleb Signer
 	log.Printf("🔐 [Persistent NWC] Encrypting...")
-	encryptedContent, err := m.signer.Nip04Encrypt(walletPubkey, string(contentBytes))
+	encryptedContent, err := a.signer.Nip04Encrypt(walletPubkey, string(contentBytes))
 	if err != nil {
-		return fmt.Errorf("failed to encrypt: %w", err)
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
 	}
 
 	// Create and sign event
 	evt := nostr.Event{
-		PubKey:    m.pubKey,
+		PubKey:    a.pubKey,
 		CreatedAt: nostr.Now(),
 		Kind:      23194,
 		Tags:      nostr.Tags{nostr.Tag{"p", walletPubkey}},
@@ -3275,14 +1633,14 @@ func (m *Model) payInvoiceWithPersistentSub(ctx context.Context, invoice string)
 	}
 
 	log.Printf("✍️  [Persistent NWC] Signing...")
-	if err := m.signer.SignEvent(&evt); err != nil {
-		return fmt.Errorf("failed to sign: %w", err)
+	if err := a.signer.SignEvent(&evt); err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
 	}
 	log.Printf("✅ [Persistent NWC] Event signed: %s", evt.ID[:8])
 
 	// Register pending payment BEFORE publishing
 	responseChan := make(chan *nostr.Event, 1)
-	m.pendingPayments[evt.ID] = responseChan
+	a.addPendingPayment(evt.ID, responseChan)
 	log.Printf("📝 [Persistent NWC] Registered pending payment: %s", evt.ID[:8])
 
 	// Publish request
@@ -3290,7 +1648,7 @@ func (m *Model) payInvoiceWithPersistentSub(ctx context.Context, invoice string)
 	publishCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 	
-	results := m.pool.PublishMany(publishCtx, []string{relay}, evt)
+	results := a.pool.PublishMany(publishCtx, []string{relay}, evt)
 	published := false
 	for result := range results {
 		if result.Error == nil {
@@ -3302,8 +1660,8 @@ func (m *Model) payInvoiceWithPersistentSub(ctx context.Context, invoice string)
 	}
 
 	if !published {
-		delete(m.pendingPayments, evt.ID)
-		return fmt.Errorf("failed to publish to wallet relay")
+		a.takePendingPayment(evt.ID)
+		return nil, fmt.Errorf("failed to publish to wallet relay")
 	}
 
 	// Wait for response with timeout
@@ -3313,174 +1671,49 @@ func (m *Model) payInvoiceWithPersistentSub(ctx context.Context, invoice string)
 		log.Printf("📨 [Persistent NWC] Received response!")
 		
 		// Decrypt response
-		decrypted, err := m.signer.Nip04Decrypt(walletPubkey, response.Content)
+		decrypted, err := a.signer.Nip04Decrypt(walletPubkey, response.Content)
 		if err != nil {
-			return fmt.Errorf("failed to decrypt response: %w", err)
+			return nil, fmt.Errorf("failed to decrypt response: %w", err)
 		}
 		log.Printf("📄 [Persistent NWC] Decrypted: %s", decrypted)
 
 		// Parse response
 		var resp nwc.PayInvoiceResponse
 		if err := json.Unmarshal([]byte(decrypted), &resp); err != nil {
-			return fmt.Errorf("failed to parse response: %w", err)
+			return nil, fmt.Errorf("failed to parse response: %w", err)
 		}
 
 		if resp.Error != nil {
-			return fmt.Errorf("wallet error: %s - %s", resp.Error.Code, resp.Error.Message)
+			return nil, fmt.Errorf("wallet error: %s - %s", resp.Error.Code, resp.Error.Message)
 		}
 
 		if resp.Result == nil {
-			return fmt.Errorf("no result in response")
+			return nil, fmt.Errorf("no result in response")
 		}
 
 		log.Printf("✅ [Persistent NWC] Payment successful!")
-		return nil
+		return resp.Result, nil
 
 	case <-time.After(60 * time.Second):
-		delete(m.pendingPayments, evt.ID)
+		a.takePendingPayment(evt.ID)
 		log.Printf("⏱️  [Persistent NWC] Timeout waiting for response")
-		return fmt.Errorf("timeout waiting for wallet response (60s)")
-	}
-}
-
-func performZapCmd(event *nostr.Event, amountSats int64, nwcString string, relays []string, pubKey string, privKey string, authMethod string, signer *signer.PlebSigner) tea.Cmd {
-	return func() tea.Msg {
-		log.Printf("🚀 Starting zap flow: amount=%d sats, auth=%s", amountSats, authMethod)
-		ctx := context.Background()
-
-		// Get the recipient's profile metadata to find lightning address
-		var lightningAddress string
-		
-		log.Printf("🔍 Fetching recipient profile for lightning address...")
-		// Try to fetch profile from our relays
-		filters := []nostr.Filter{{
-			Kinds:   []int{0}, // Profile metadata
-			Authors: []string{event.PubKey},
-			Limit:   1,
-		}}
-
-		pool := nostr.NewSimplePool(ctx)
-		eventChan := pool.SubManyEose(ctx, relays, filters)
-		
-		timeout := time.After(5 * time.Second)
-		select {
-		case evt := <-eventChan:
-			if evt.Event != nil {
-				lightningAddress = zap.GetLightningAddress(evt.Content)
-				log.Printf("✅ Found lightning address: %s", lightningAddress)
-			}
-		case <-timeout:
-			log.Printf("⏱️  Profile fetch timeout")
-		}
-
-		if lightningAddress == "" {
-			log.Printf("❌ No lightning address found")
-			return zapErrorMsg{err: fmt.Errorf("recipient has no lightning address in profile")}
-		}
-
-		// Get LNURL endpoint
-		log.Printf("🔗 Getting LNURL endpoint...")
-		lnurlEndpoint, err := zap.GetLNURL(lightningAddress)
-		if err != nil {
-			log.Printf("❌ Invalid lightning address: %v", err)
-			return zapErrorMsg{err: fmt.Errorf("invalid lightning address: %w", err)}
-		}
-		log.Printf("✅ LNURL endpoint: %s", lnurlEndpoint)
-
-		// Fetch LNURL info
-		log.Printf("📡 Fetching LNURL info...")
-		lnurlInfo, err := zap.FetchLNURLPayInfo(lnurlEndpoint)
-		if err != nil {
-			log.Printf("❌ Failed to fetch LNURL info: %v", err)
-			return zapErrorMsg{err: fmt.Errorf("failed to fetch LNURL info: %w", err)}
-		}
-		log.Printf("✅ LNURL callback: %s", lnurlInfo.Callback)
-
-		// Create zap request - different approach based on auth method
-		log.Printf("📝 Creating zap request (auth: %s)...", authMethod)
-		var zapRequest *nostr.Event
-		if authMethod == "nsec" && privKey != "" {
-			// Use private key directly
-			zapRequest, err = zap.CreateZapRequest(event.PubKey, event.ID, "⚡", amountSats, relays, privKey)
-			if err != nil {
-				log.Printf("❌ Failed to create zap request: %v", err)
-				return zapErrorMsg{err: fmt.Errorf("failed to create zap request: %w", err)}
-			}
-		} else {
-			// Use Pleb Signer
-			amountMsats := amountSats * 1000
-			zapRequest = &nostr.Event{
-				PubKey:    pubKey,
-				CreatedAt: nostr.Now(),
-				Kind:      9734, // Zap request kind
-				Tags: nostr.Tags{
-					nostr.Tag{"p", event.PubKey},
-					nostr.Tag{"amount", fmt.Sprintf("%d", amountMsats)},
-					nostr.Tag{"e", event.ID},
-				},
-				Content: "⚡",
-			}
-			// Add relay tags
-			for _, relay := range relays {
-				zapRequest.Tags = append(zapRequest.Tags, nostr.Tag{"relays", relay})
-			}
-			// Sign with Pleb Signer
-			if err := signer.SignEvent(zapRequest); err != nil {
-				log.Printf("❌ Failed to sign zap request: %v", err)
-				return zapErrorMsg{err: fmt.Errorf("failed to sign zap request: %w", err)}
-			}
-		}
-		log.Printf("✅ Zap request created and signed")
-
-		// Get invoice
-		log.Printf("💰 Requesting invoice...")
-		invoice, err := zap.GetInvoice(lnurlInfo.Callback, amountSats, zapRequest)
-		if err != nil {
-			log.Printf("❌ Failed to get invoice: %v", err)
-			return zapErrorMsg{err: fmt.Errorf("failed to get invoice: %w", err)}
-		}
-		log.Printf("✅ Invoice received: %s...", invoice[:20])
-
-		// Pay invoice via NWC - different approach based on auth method
-		log.Printf("💸 Paying invoice via NWC (auth: %s)...", authMethod)
-		if authMethod == "nsec" && privKey != "" {
-			// Use NWC client with private key
-			log.Printf("🔑 Creating NWC client with nsec...")
-			nwcClient, err := nwc.NewNWCClient(nwcString)
-			if err != nil {
-				log.Printf("❌ Failed to create NWC client: %v", err)
-				return zapErrorMsg{err: fmt.Errorf("failed to create NWC client: %w", err)}
-			}
-			log.Printf("✅ NWC client created, calling PayInvoice...")
-			if err := nwcClient.PayInvoice(ctx, invoice); err != nil {
-				log.Printf("❌ PayInvoice failed: %v", err)
-				return zapErrorMsg{err: fmt.Errorf("failed to pay invoice: %w", err)}
-			}
-		} else {
-			// Use NWC with Pleb Signer
-			log.Printf("🔏 Using Pleb Signer for NWC...")
-			// Note: We can't access the Model's pool from here since we're in a tea.Cmd
-			// For now, we'll still create a new pool per request
-			// TODO: Refactor to use persistent NWC subscription like Amethyst
-			if err := payInvoiceWithSigner(ctx, nwcString, invoice, pubKey, signer); err != nil {
-				log.Printf("❌ payInvoiceWithSigner failed: %v", err)
-				return zapErrorMsg{err: fmt.Errorf("failed to pay invoice: %w", err)}
-			}
-		}
+		return nil, fmt.Errorf("timeout waiting for wallet response (60s)")
 
-		log.Printf("🎉 Zap successful!")
-		return zapSuccessMsg{}
+	case <-ctx.Done():
+		a.takePendingPayment(evt.ID)
+		log.Printf("🛑 [Persistent NWC] Canceled while waiting for response")
+		return nil, fmt.Errorf("payment canceled: %w", ctx.Err())
 	}
 }
 
 // payInvoiceWithSigner pays an invoice via NWC using Pleb Signer for encryption/signing
-func payInvoiceWithSigner(ctx context.Context, nwcString string, invoice string, pubKey string, signer *signer.PlebSigner) error {
+func payInvoiceWithSigner(ctx context.Context, nwcString string, invoice string, pubKey string, signer *signer.PlebSigner) (*nwc.PayInvoiceResult, error) {
 	log.Printf("📋 [Signer NWC] Parsing NWC string...")
 	// Parse NWC string
 	walletPubkey, relay, _, err := nwc.ParseNWCString(nwcString)
 	if err != nil {
 		log.Printf("❌ [Signer NWC] Failed to parse: %v", err)
-		return fmt.Errorf("failed to parse NWC string: %w", err)
+		return nil, fmt.Errorf("failed to parse NWC string: %w", err)
 	}
 	log.Printf("✅ [Signer NWC] Wallet pubkey: %s, relay: %s", walletPubkey[:8], relay)
 
@@ -3495,7 +1728,7 @@ func payInvoiceWithSigner(ctx context.Context, nwcString string, invoice string,
 	contentBytes, err := json.Marshal(requestContent)
 	if err != nil {
 		log.Printf("❌ [Signer NWC] Marshal failed: %v", err)
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 	log.Printf("📦 [Signer NWC] Request content: %s", string(contentBytes))
 
@@ -3504,7 +1737,7 @@ func payInvoiceWithSigner(ctx context.Context, nwcString string, invoice string,
 	encryptedContent, err := signer.Nip04Encrypt(walletPubkey, string(contentBytes))
 	if err != nil {
 		log.Printf("❌ [Signer NWC] Encryption failed: %v", err)
-		return fmt.Errorf("failed to encrypt content: %w", err)
+		return nil, fmt.Errorf("failed to encrypt content: %w", err)
 	}
 	log.Printf("✅ [Signer NWC] Content encrypted")
 
@@ -3523,7 +1756,7 @@ func payInvoiceWithSigner(ctx context.Context, nwcString string, invoice string,
 	log.Printf("✍️  [Signer NWC] Signing event with Pleb Signer...")
 	if err := signer.SignEvent(&evt); err != nil {
 		log.Printf("❌ [Signer NWC] Signing failed: %v", err)
-		return fmt.Errorf("failed to sign event: %w", err)
+		return nil, fmt.Errorf("failed to sign event: %w", err)
 	}
 	log.Printf("✅ [Signer NWC] Event signed, ID: %s", evt.ID)
 
@@ -3571,7 +1804,7 @@ func payInvoiceWithSigner(ctx context.Context, nwcString string, invoice string,
 	
 	if !published {
 		log.Printf("❌ [Signer NWC] Failed to publish to wallet relay")
-		return fmt.Errorf("failed to publish request to wallet relay")
+		return nil, fmt.Errorf("failed to publish request to wallet relay")
 	}
 	
 	// Add another delay after publishing to give wallet time to process
@@ -3595,7 +1828,7 @@ func payInvoiceWithSigner(ctx context.Context, nwcString string, invoice string,
 		case respEvent, ok := <-responseChan:
 			if !ok {
 				log.Printf("❌ [Signer NWC] Response channel closed after %d events", eventCount)
-				return fmt.Errorf("response channel closed - wallet relay may have disconnected")
+				return nil, fmt.Errorf("response channel closed - wallet relay may have disconnected")
 			}
 			
 			eventCount++
@@ -3640,7 +1873,7 @@ func payInvoiceWithSigner(ctx context.Context, nwcString string, invoice string,
 			decrypted, err := signer.Nip04Decrypt(walletPubkey, respEvent.Content)
 			if err != nil {
 				log.Printf("❌ [Signer NWC] Decryption failed: %v", err)
-				return fmt.Errorf("failed to decrypt response: %w", err)
+				return nil, fmt.Errorf("failed to decrypt response: %w", err)
 			}
 			log.Printf("📄 [Signer NWC] Decrypted: %s", decrypted)
 
@@ -3648,26 +1881,26 @@ func payInvoiceWithSigner(ctx context.Context, nwcString string, invoice string,
 			var response nwc.PayInvoiceResponse
 			if err := json.Unmarshal([]byte(decrypted), &response); err != nil {
 				log.Printf("❌ [Signer NWC] JSON parse failed: %v", err)
-				return fmt.Errorf("failed to parse response: %w", err)
+				return nil, fmt.Errorf("failed to parse response: %w", err)
 			}
 
 			// Check for errors
 			if response.Error != nil {
 				log.Printf("❌ [Signer NWC] Wallet error: %s - %s", response.Error.Code, response.Error.Message)
-				return fmt.Errorf("wallet error: %s - %s", response.Error.Code, response.Error.Message)
+				return nil, fmt.Errorf("wallet error: %s - %s", response.Error.Code, response.Error.Message)
 			}
 
 			if response.Result == nil {
 				log.Printf("❌ [Signer NWC] No result in response")
-				return fmt.Errorf("no result in response")
+				return nil, fmt.Errorf("no result in response")
 			}
 
 			log.Printf("✅ [Signer NWC] Payment successful!")
-			return nil
+			return response.Result, nil
 
 		case <-ctx.Done():
 			log.Printf("⏱️  [Signer NWC] Timeout after receiving %d events (60s timeout)", eventCount)
-			return fmt.Errorf("timeout waiting for wallet response (60s) - received %d events but none matched", eventCount)
+			return nil, fmt.Errorf("timeout waiting for wallet response (60s) - received %d events but none matched", eventCount)
 		}
 	}
 }
@@ -3683,19 +1916,6 @@ func (m *Model) resetSubscriptions() {
 	m.activeSubCtx, m.cancelSubs = context.WithCancel(context.Background())
 }
 
-// saveConfig persists the current settings to disk
-func (m *Model) saveConfig() {
-	cfg := &config.Config{
-		AuthMethod: m.authMethod,
-		Nsec:       m.nsecKey,
-		Relays:     m.relays,
-		NWC:        m.nwcString,
-	}
-	
-	// Don't block UI if save fails
-	_ = config.Save(cfg)
-}
-
 func min(a, b int) int {
 if a < b {
 return a
@@ -3714,7 +1934,7 @@ count++
 }
 }
 case viewNotifications:
-for _, evt := range m.notifications {
+for _, evt := range m.visibleNotifications() {
 if !m.readNotifs[evt.ID] {
 count++
 }
@@ -0,0 +1,460 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"noscli/pkg/client"
+	"noscli/pkg/config"
+)
+
+// paletteCommand is something the command palette runs
+type paletteCommand struct {
+	name     string // What's typed; its first word is enough when arguments follow
+	args     string // What it takes, for the list and the help
+	help     string
+	needsArg bool
+	secret   bool // Its argument is masked as it's typed
+	run      func(m *Model, arg string) tea.Cmd
+}
+
+// usage returns the command with its arguments, e.g. "dm [npub]"
+func (c paletteCommand) usage() string {
+	return strings.TrimSpace(c.name + " " + c.args)
+}
+
+// paletteCommands are the palette's commands, in the order it lists them.
+// Those that take a pubkey act on the selected note's author without one.
+func paletteCommands() []paletteCommand {
+	return []paletteCommand{
+		{"post", "[text]", "Compose a new post", false, false, (*Model).palettePost},
+		{"dm", "[npub]", "Send someone a DM", false, false, (*Model).paletteDM},
+		{"zap", "[npub|naddr|nevent|address]", "Zap someone, or the selected note", false, false, (*Model).paletteZap},
+		{"follow", "[npub]", "Follow someone", false, false, (*Model).paletteFollow},
+		{"open profile", "[npub|note|nevent]", "Open someone's notes, or a note's thread", false, false, (*Model).paletteOpen},
+		{"switch account", "[nsec]", "Log in as someone else", false, true, (*Model).paletteSwitchAccount},
+		{"add relay", "<wss://...>", "Add a relay and save it", true, false, (*Model).paletteAddRelay},
+		{"search", "<query>", "Search notes on relays that support it (NIP-50)", true, false, (*Model).paletteSearch},
+	}
+}
+
+// paletteScreen is the ':' prompt over the timeline: a command, maybe
+// with arguments, and which of the matching commands is selected
+type paletteScreen struct {
+	input  string
+	cursor int
+}
+
+// paletteCommandMsg asks to run a command picked in the palette
+type paletteCommandMsg struct {
+	command paletteCommand
+	arg     string
+}
+
+// noteMsg delivers a note looked up to open its thread
+type noteMsg struct {
+	event *nostr.Event
+	err   error
+}
+
+// followMsg reports the result of following someone
+type followMsg struct {
+	pubkey    string
+	following []string // Who we follow now
+	err       error
+}
+
+// resolvePaletteCommand returns the command input names and its arguments.
+// A command is named by its full name, or by its first word when arguments
+// follow it, as in ":open nevent1...".
+func resolvePaletteCommand(input string) (paletteCommand, string, bool) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return paletteCommand{}, "", false
+	}
+	for _, c := range paletteCommands() {
+		name := strings.Fields(c.name)
+		if len(fields) >= len(name) && slices.Equal(fields[:len(name)], name) {
+			return c, skipFields(input, len(name)), true
+		}
+		if fields[0] == name[0] && len(fields) > 1 {
+			return c, skipFields(input, 1), true
+		}
+	}
+	return paletteCommand{}, "", false
+}
+
+// skipFields returns s without its first n words, trimmed
+func skipFields(s string, n int) string {
+	for range n {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if i := strings.IndexFunc(s, unicode.IsSpace); i >= 0 {
+			s = s[i:]
+		} else {
+			s = ""
+		}
+	}
+	return strings.TrimSpace(s)
+}
+
+// fuzzyScore reports whether pattern's letters appear in s in order,
+// ignoring case, and how well: letters that follow each other or start a
+// word count for more
+func fuzzyScore(pattern, s string) (int, bool) {
+	p, t := []rune(strings.ToLower(pattern)), []rune(strings.ToLower(s))
+	score, matched, prev := 0, 0, -2
+	for i := 0; i < len(t) && matched < len(p); i++ {
+		if t[i] != p[matched] {
+			continue
+		}
+		switch {
+		case i == prev+1:
+			score += 3
+		case i == 0 || t[i-1] == ' ':
+			score += 2
+		default:
+			score++
+		}
+		prev = i
+		matched++
+	}
+	return score, matched == len(p)
+}
+
+// matches returns the commands the input could mean, best first
+func (p *paletteScreen) matches() []paletteCommand {
+	if c, _, ok := resolvePaletteCommand(p.input); ok {
+		return []paletteCommand{c}
+	}
+	pattern := strings.TrimSpace(p.input)
+	type scored struct {
+		command paletteCommand
+		score   int
+	}
+	var found []scored
+	for _, c := range paletteCommands() {
+		if score, ok := fuzzyScore(pattern, c.name); ok {
+			found = append(found, scored{c, score})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].score > found[j].score
+	})
+	commands := make([]paletteCommand, len(found))
+	for i, f := range found {
+		commands[i] = f.command
+	}
+	return commands
+}
+
+// open opens the palette with nothing typed
+func (p *paletteScreen) open(app *appContext) {
+	*p = paletteScreen{}
+	app.overlay = overlayPalette
+}
+
+// close closes the palette
+func (p *paletteScreen) close(app *appContext) {
+	*p = paletteScreen{}
+	app.overlay = overlayNone
+}
+
+// Update types into the palette, picks a command and runs it
+func (p *paletteScreen) Update(app *appContext, msg tea.KeyMsg) tea.Cmd {
+	matches := p.matches()
+	switch msg.String() {
+	case "esc":
+		p.close(app)
+		app.statusMsg = ""
+	case "up", "ctrl+p":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "down", "ctrl+n":
+		if p.cursor < len(matches)-1 {
+			p.cursor++
+		}
+	case "tab":
+		// Complete the selected command, ready for its arguments
+		if len(matches) > 0 {
+			p.input = matches[min(p.cursor, len(matches)-1)].name + " "
+			p.cursor = 0
+		}
+	case "enter":
+		c, arg, ok := resolvePaletteCommand(p.input)
+		if !ok {
+			if len(matches) == 0 {
+				app.statusMsg = "⚠️ No command matches " + strings.TrimSpace(p.input)
+				return nil
+			}
+			c = matches[min(p.cursor, len(matches)-1)]
+		}
+		if c.needsArg && arg == "" {
+			p.input = c.name + " "
+			p.cursor = 0
+			app.statusMsg = fmt.Sprintf("%s needs %s", c.name, c.args)
+			return nil
+		}
+		p.close(app)
+		return func() tea.Msg { return paletteCommandMsg{command: c, arg: arg} }
+	case "backspace":
+		if runes := []rune(p.input); len(runes) > 0 {
+			p.input = string(runes[:len(runes)-1])
+			p.cursor = 0
+		}
+	case " ", "space":
+		p.input += " "
+		p.cursor = 0
+	default:
+		if msg.Type == tea.KeyRunes {
+			p.input += string(msg.Runes)
+			p.cursor = 0
+		}
+	}
+	return nil
+}
+
+// View lists the commands matching what's been typed, in place of the feed
+func (p *paletteScreen) View(app *appContext) string {
+	matches := p.matches()
+	usageWidth := 0
+	for _, c := range matches {
+		usageWidth = max(usageWidth, len(c.usage()))
+	}
+
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	var lines []string
+	for i, c := range matches {
+		usage := fmt.Sprintf("%-*s", usageWidth, c.usage())
+		if i == min(p.cursor, len(matches)-1) {
+			lines = append(lines, selectedStyle.Render("> "+usage)+"  "+helpStyle.Render(c.help))
+		} else {
+			lines = append(lines, "  "+usage+"  "+helpStyle.Render(c.help))
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, helpStyle.Render("No matching command"))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("205")).
+		Padding(0, 1).
+		MaxWidth(app.width).
+		Render(strings.Join(lines, "\n"))
+}
+
+// Status shows the palette's input. Secret arguments show as asterisks,
+// like the nsec field in settings.
+func (p *paletteScreen) Status(app *appContext) string {
+	input := p.input
+	if c, arg, ok := resolvePaletteCommand(input); ok && c.secret && arg != "" {
+		i := strings.LastIndex(input, arg)
+		input = input[:i] + strings.Repeat("*", len([]rune(arg))) + input[i+len(arg):]
+	}
+	return fmt.Sprintf(":%s_ (Enter to run, Tab to complete, Esc to cancel)", input)
+}
+
+// selectedEvent returns the note under the cursor, or nil
+func (m *Model) selectedEvent() *nostr.Event {
+	events := m.getCurrentEvents()
+	if m.cursor >= len(events) {
+		return nil
+	}
+	return &events[m.cursor]
+}
+
+// parsePubkey returns the hex pubkey of an npub, nprofile or hex key
+func (a *appContext) parsePubkey(input string) (string, error) {
+	input = strings.TrimPrefix(strings.TrimSpace(input), "nostr:")
+	if nostr.IsValid32ByteHex(input) {
+		return input, nil
+	}
+	if pubkey := a.extractPubkeyFromNip19(input); pubkey != "" {
+		return pubkey, nil
+	}
+	return "", fmt.Errorf("%s isn't an npub or nprofile", input)
+}
+
+// pubkeyArg returns whose pubkey a command's argument names, or the
+// selected note's author if there's none
+func (m *Model) pubkeyArg(arg string) (string, error) {
+	if arg != "" {
+		return m.parsePubkey(arg)
+	}
+	if evt := m.selectedEvent(); evt != nil {
+		return evt.PubKey, nil
+	}
+	return "", fmt.Errorf("give an npub or select a note")
+}
+
+func (m *Model) palettePost(arg string) tea.Cmd {
+	m.composer.open(m.appContext, composePost, nil)
+	m.composer.textarea.SetValue(arg)
+	return nil
+}
+
+func (m *Model) paletteDM(arg string) tea.Cmd {
+	// Without a recipient, answer the selected DM like R does
+	if evt := m.selectedEvent(); arg == "" && evt != nil && m.currentView == viewDMs {
+		m.composer.open(m.appContext, composeDM, evt)
+		return nil
+	}
+	pubkey, err := m.pubkeyArg(arg)
+	if err != nil {
+		m.statusMsg = fmt.Sprintf("⚠️ Can't send a DM: %v", err)
+		return nil
+	}
+	m.composer.openDM(m.appContext, pubkey)
+	return nil
+}
+
+func (m *Model) paletteZap(arg string) tea.Cmd {
+	if m.nwcString == "" {
+		m.statusMsg = "⚠️ No wallet connected. Add NWC in Settings → Wallet"
+		return nil
+	}
+	if arg != "" {
+		m.zapPrompt.openProfile(m.appContext, arg)
+		return m.zapPrompt.submitProfile(m.appContext)
+	}
+	evt := m.selectedEvent()
+	switch {
+	case evt == nil:
+		m.statusMsg = "⚠️ Can't zap: give an npub or select a note"
+	case m.selectedIsWalletNotification():
+		m.statusMsg = "Not available for wallet payments"
+	case m.selectedIsZapReceipt():
+		m.statusMsg = "Not available for zap receipts"
+	default:
+		m.statusMsg = "Enter zap amount (sats): "
+		return m.zapPrompt.open(m.appContext, subjectForEvent(*evt))
+	}
+	return nil
+}
+
+func (m *Model) paletteFollow(arg string) tea.Cmd {
+	pubkey, err := m.pubkeyArg(arg)
+	switch {
+	case err != nil:
+		m.statusMsg = fmt.Sprintf("⚠️ Can't follow: %v", err)
+	case pubkey == m.pubKey:
+		m.statusMsg = "That's you"
+	case slices.Contains(m.following, pubkey):
+		m.statusMsg = "Already following @" + m.displayName(pubkey)
+	default:
+		m.statusMsg = "Following @" + m.displayName(pubkey) + "..."
+		return followCmd(m.client(), pubkey)
+	}
+	return nil
+}
+
+func (m *Model) paletteOpen(arg string) tea.Cmd {
+	if id := m.extractEventIDFromNip19(strings.TrimPrefix(arg, "nostr:")); id != "" {
+		m.statusMsg = "Loading note..."
+		return fetchNoteCmd(m.client(), id)
+	}
+	pubkey, err := m.pubkeyArg(arg)
+	if err != nil {
+		m.statusMsg = fmt.Sprintf("⚠️ Can't open %s: give an npub, nprofile, note or nevent", arg)
+		return nil
+	}
+	m.requestProfiles(pubkey)
+	return m.openColumn(config.Column{Kind: config.ColumnFeed, Title: "@" + m.displayName(pubkey), Authors: []string{pubkey}})
+}
+
+func (m *Model) paletteSwitchAccount(arg string) tea.Cmd {
+	if arg != "" {
+		if prefix, _, err := nip19.Decode(arg); err != nil || prefix != "nsec" {
+			m.statusMsg = "⚠️ That isn't an nsec"
+			return nil
+		}
+	}
+	m.resetAccount()
+	if arg != "" {
+		m.authMethod = "nsec"
+		m.state = stateConnecting
+		return connectWithNsecCmd(arg)
+	}
+	m.state = stateSettings
+	m.settings.menu, m.settings.cursor = 0, 0
+	m.statusMsg = "Choose how to log in"
+	return nil
+}
+
+func (m *Model) paletteAddRelay(arg string) tea.Cmd {
+	relay := nostr.NormalizeURL(arg)
+	if u, err := url.Parse(relay); err != nil || (u.Scheme != "wss" && u.Scheme != "ws") || u.Host == "" {
+		m.statusMsg = "⚠️ Relays are wss:// URLs"
+		return nil
+	}
+	if slices.Contains(m.relays, relay) {
+		m.statusMsg = "Already using " + relay
+		return nil
+	}
+	m.relays = append(m.relays, relay)
+	m.saveConfig()
+	m.statusMsg = "✓ Added relay " + relay
+	return nil
+}
+
+func (m *Model) paletteSearch(arg string) tea.Cmd {
+	return m.openColumn(config.Column{Kind: config.ColumnFeed, Search: arg})
+}
+
+// resetAccount forgets what was loaded for the logged-in account, before
+// logging in as someone else. Columns opened from the palette close.
+func (m *Model) resetAccount() {
+	m.resetSubscriptions()
+	m.pubKey, m.privKey, m.npub = "", "", ""
+	m.events, m.dms, m.notifications, m.following = nil, nil, nil, nil
+	m.readDMs, m.readNotifs = make(map[string]bool), make(map[string]bool)
+	m.dmTexts, m.dmQueued, m.decryptQueue = make(map[string]dmResult), make(map[string]bool), nil
+	m.lastEventTime, m.lastDMTime, m.lastNotifTime = 0, 0, 0
+	m.columns = newColumns(m.appContext.columns)
+	m.focus, m.currentView = 0, m.columns[0].view
+	m.cursor, m.top, m.topLine = 0, 0, 0
+}
+
+// fetchNoteCmd looks up a note to open its thread
+func fetchNoteCmd(c *client.Client, id string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		evt, err := c.FetchEvent(ctx, id)
+		if err == nil && evt == nil {
+			err = fmt.Errorf("no relay has it")
+		}
+		if err != nil {
+			log.Printf("⚠️  Failed to fetch note %s: %v", id, err)
+		}
+		return noteMsg{event: evt, err: err}
+	}
+}
+
+// followCmd adds pubkey to our contact list
+func followCmd(c *client.Client, pubkey string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		following, err := c.Follow(ctx, pubkey)
+		if err != nil {
+			log.Printf("⚠️  Failed to follow %s: %v", pubkey, err)
+		}
+		return followMsg{pubkey: pubkey, following: following, err: err}
+	}
+}
@@ -0,0 +1,163 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"noscli/pkg/config"
+)
+
+func TestPaletteMatching(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  string // First match
+	}{
+		{"", "post"},
+		{"se", "search"},
+		{"sw", "switch account"},
+		{"ar", "add relay"},
+		{"opr", "open profile"},
+		{"FOL", "follow"},
+	} {
+		p := paletteScreen{input: tc.input}
+		if got := p.matches(); len(got) == 0 || got[0].name != tc.want {
+			t.Errorf("%q matches %v, want %s first", tc.input, got, tc.want)
+		}
+	}
+	if got := (&paletteScreen{input: "xyz"}).matches(); len(got) != 0 {
+		t.Errorf("xyz matches %v", got)
+	}
+
+	for _, tc := range []struct {
+		input, name, arg string
+	}{
+		{"open nevent1abc", "open profile", "nevent1abc"},
+		{"open profile npub1abc", "open profile", "npub1abc"},
+		{"post  gm  frens ", "post", "gm  frens"},
+		{"dm", "dm", ""},
+		{"add wss://relay.example.com", "add relay", "wss://relay.example.com"},
+	} {
+		c, arg, ok := resolvePaletteCommand(tc.input)
+		if !ok || c.name != tc.name || arg != tc.arg {
+			t.Errorf("%q resolves to %q %q %v, want %q %q", tc.input, c.name, arg, ok, tc.name, tc.arg)
+		}
+	}
+	if c, _, ok := resolvePaletteCommand("open"); ok {
+		t.Errorf("open alone resolves to %s", c.name)
+	}
+}
+
+func TestPaletteGolden(t *testing.T) {
+	h := newUIHarness(t, &config.Config{AuthMethod: "nsec"})
+	h.loggedIn(h.event(goldenAlice, 1, "gm", time.Minute))
+
+	h.keys(":")
+	h.typeText("o")
+	h.golden("palette")
+
+	// Esc closes it without running anything
+	h.keys("esc")
+	if h.m.overlay != overlayNone || h.m.state != stateTimeline {
+		t.Errorf("esc left overlay %d, state %d", h.m.overlay, h.m.state)
+	}
+}
+
+// runPalette types text into the palette, presses enter and runs the
+// command it picked, returning the command's own command
+func (h *uiHarness) runPalette(text string) tea.Cmd {
+	h.t.Helper()
+	h.keys(":")
+	h.typeText(text)
+	cmd := h.update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		h.t.Fatalf("%q ran nothing", text)
+	}
+	return h.update(cmd())
+}
+
+func TestPaletteCommands(t *testing.T) {
+	h := newUIHarness(t, &config.Config{AuthMethod: "nsec"})
+	h.loggedIn(h.event(goldenAlice, 1, "gm", time.Minute))
+	bobNpub, _ := nip19.EncodePublicKey(goldenBob.pk)
+
+	// A DM to anyone, by npub
+	h.runPalette("dm " + bobNpub)
+	if h.m.state != stateComposing || h.m.composer.mode != composeDM || h.m.composer.recipient != goldenBob.pk {
+		t.Fatalf("dm: state %d, mode %d, recipient %q", h.m.state, h.m.composer.mode, h.m.composer.recipient)
+	}
+	h.keys("esc")
+
+	// Without an argument, follow acts on the selected note's author
+	h.runPalette("follow")
+	if h.m.statusMsg != "Already following @alice" {
+		t.Errorf("follow: %q", h.m.statusMsg)
+	}
+
+	// Commands that need an argument are completed and wait for it
+	h.keys(":")
+	h.typeText("se")
+	h.keys("enter")
+	if h.m.overlay != overlayPalette || h.m.palette.input != "search " {
+		t.Fatalf("search without a query: overlay %d, %+v", h.m.overlay, h.m.palette)
+	}
+	h.typeText("bitcoin")
+	cmd := h.update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil {
+		cmd = h.update(cmd())
+	}
+	if cmd == nil || h.m.columns[h.m.focus].title() != "🔍 bitcoin" || h.m.currentView != viewFeed {
+		t.Fatalf("search opened %q, view %d", h.m.columns[h.m.focus].title(), h.m.currentView)
+	}
+
+	// The same search focuses its column instead of adding another
+	h.keys("tab")
+	h.runPalette("search bitcoin")
+	if len(h.m.columns) != 4 || h.m.focus != 3 {
+		t.Errorf("%d columns, focus %d", len(h.m.columns), h.m.focus)
+	}
+
+	// Columns opened from the palette aren't saved with the layout
+	h.keys("<")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Columns) != 3 {
+		t.Errorf("saved columns %+v", cfg.Columns)
+	}
+
+	// Profiles open as a column of their notes
+	h.runPalette("open " + bobNpub)
+	if c := h.m.columns[h.m.focus]; c.title() != "@bob" || len(c.Authors) != 1 || c.Authors[0] != goldenBob.pk {
+		t.Errorf("open profile: %+v", c.Column)
+	}
+
+	// Relays are saved
+	h.runPalette("add relay relay.damus.io")
+	cfg, _ = config.Load()
+	if got := cfg.Relays[len(cfg.Relays)-1]; got != "wss://relay.damus.io" {
+		t.Errorf("relays %v", cfg.Relays)
+	}
+	h.runPalette("add relay https://example.com")
+	if !strings.Contains(h.m.statusMsg, "wss://") {
+		t.Errorf("added a relay that isn't one: %q", h.m.statusMsg)
+	}
+
+	// An nsec typed into the palette never shows
+	nsec, _ := nip19.EncodePrivateKey(goldenBob.sk)
+	h.keys(":")
+	h.typeText("switch account " + nsec)
+	if view := h.m.View(); strings.Contains(view, nsec) || strings.Contains(view, nsec[:12]) || !strings.Contains(view, ":switch account "+strings.Repeat("*", len(nsec))+"_") {
+		t.Errorf("the nsec is echoed:\n%s", view)
+	}
+	h.keys("esc")
+
+	// Switching accounts forgets this one's notes
+	h.runPalette("switch account")
+	if h.m.state != stateSettings || h.m.pubKey != "" || len(h.m.events) != 0 || len(h.m.columns) != 3 {
+		t.Errorf("switch account: state %d, pubkey %q, %d events, %d columns", h.m.state, h.m.pubKey, len(h.m.events), len(h.m.columns))
+	}
+}
@@ -0,0 +1,384 @@
+package tui
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"noscli/pkg/config"
+	"noscli/pkg/nwc"
+	"noscli/pkg/zap"
+)
+
+// invoiceReadyMsg carries an invoice waiting for the user's confirmation
+type invoiceReadyMsg struct {
+	invoice string
+	decoded *zap.Invoice
+	target  string // Lightning address the invoice was requested from, if any
+}
+
+// paymentResultMsg reports the outcome of paying an invoice
+type paymentResultMsg struct {
+	wallet     string
+	amountSats int64
+	err        error
+}
+
+// extractInvoices returns the BOLT-11 invoices found in note content
+func extractInvoices(text string) []string {
+	var invoices []string
+	for _, word := range strings.Fields(text) {
+		cleanWord := strings.TrimRight(word, ".,;:!?)]")
+		if zap.IsInvoice(cleanWord) {
+			invoices = append(invoices, strings.ToLower(trimLightningScheme(cleanWord)))
+		}
+	}
+	return invoices
+}
+
+// extractLightningAddresses returns lightning addresses and LNURLs from lightning: URIs in note content
+func extractLightningAddresses(text string) []string {
+	var addresses []string
+	for _, word := range strings.Fields(text) {
+		cleanWord := strings.TrimRight(word, ".,;:!?)]")
+		if !strings.HasPrefix(strings.ToLower(cleanWord), "lightning:") {
+			continue
+		}
+		target := trimLightningScheme(cleanWord)
+		isLNURL := strings.HasPrefix(strings.ToLower(target), "lnurl1")
+		if (strings.Count(target, "@") == 1 || isLNURL) && !zap.IsInvoice(target) {
+			addresses = append(addresses, target)
+		}
+	}
+	return addresses
+}
+
+// trimLightningScheme removes a case-insensitive lightning: prefix
+func trimLightningScheme(s string) string {
+	if strings.HasPrefix(strings.ToLower(s), "lightning:") {
+		return s[len("lightning:"):]
+	}
+	return s
+}
+
+// renderInvoiceLine summarizes an invoice for display under a note
+func renderInvoiceLine(bolt11 string) string {
+	decoded, err := zap.DecodeInvoice(bolt11)
+	if err != nil {
+		return "⚡ Invoice (unreadable)"
+	}
+
+	amount := "any amount"
+	if decoded.AmountMsats > 0 {
+		amount = formatSats(decoded.AmountMsats)
+	}
+
+	line := "⚡ Invoice: " + amount
+	if decoded.Description != "" {
+		line += " — " + truncateRunes(decoded.Description, 50)
+	}
+
+	if decoded.Expired() {
+		line += " (expired)"
+	} else {
+		line += " (expires " + formatUntil(decoded.ExpiresAt()) + ")"
+	}
+
+	return line
+}
+
+// formatUntil formats a future time relative to now
+func formatUntil(t time.Time) string {
+	diff := time.Until(t)
+	switch {
+	case diff < time.Minute:
+		return "in under a minute"
+	case diff < time.Hour:
+		return fmt.Sprintf("in %d mins", int(diff.Minutes()))
+	case diff < 24*time.Hour:
+		return fmt.Sprintf("in %d hours", int(diff.Hours()))
+	default:
+		return "on " + t.Format("Jan 2")
+	}
+}
+
+// checkPayableInvoice decodes an invoice and rejects ones we can't pay
+func checkPayableInvoice(invoice string) (*zap.Invoice, error) {
+	decoded, err := zap.DecodeInvoice(invoice)
+	if err != nil {
+		return nil, err
+	}
+	if decoded.Expired() {
+		return nil, fmt.Errorf("invoice expired %s", formatTimestamp(decoded.ExpiresAt()))
+	}
+	if decoded.AmountMsats == 0 {
+		return nil, fmt.Errorf("invoices without an amount aren't supported")
+	}
+	return decoded, nil
+}
+
+// fetchAddressInvoiceCmd requests a plain LNURL-pay invoice from a lightning address
+func fetchAddressInvoiceCmd(lnurl *zap.Client, address string, amountSats int64) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		log.Printf("🔗 Requesting %d sat invoice from %s...", amountSats, address)
+		lnurlEndpoint, err := zap.ResolvePayEndpoint(address)
+		if err != nil {
+			return paymentResultMsg{err: fmt.Errorf("invalid lightning address: %w", err)}
+		}
+
+		lnurlInfo, err := lnurl.FetchLNURLPay(ctx, lnurlEndpoint)
+		if err != nil {
+			return paymentResultMsg{err: fmt.Errorf("failed to fetch LNURL info: %w", err)}
+		}
+
+		amountMsats := amountSats * 1000
+		if lnurlInfo.MinSendable > 0 && amountMsats < lnurlInfo.MinSendable {
+			return paymentResultMsg{err: fmt.Errorf("minimum is %s", formatSats(lnurlInfo.MinSendable))}
+		}
+		if lnurlInfo.MaxSendable > 0 && amountMsats > lnurlInfo.MaxSendable {
+			return paymentResultMsg{err: fmt.Errorf("maximum is %s", formatSats(lnurlInfo.MaxSendable))}
+		}
+
+		invoice, err := lnurl.GetPayInvoice(ctx, lnurlInfo.Callback, amountSats, "")
+		if err != nil {
+			return paymentResultMsg{err: fmt.Errorf("failed to get invoice: %w", err)}
+		}
+
+		decoded, err := checkPayableInvoice(invoice)
+		if err != nil {
+			return paymentResultMsg{err: err}
+		}
+		if decoded.AmountMsats != amountMsats {
+			return paymentResultMsg{err: fmt.Errorf("invoice is for %s, expected %s", formatSats(decoded.AmountMsats), formatSats(amountMsats))}
+		}
+		// LUD-06: the invoice must commit to the metadata we were shown
+		metadataHash := sha256.Sum256([]byte(lnurlInfo.Metadata))
+		if decoded.DescriptionHash != hex.EncodeToString(metadataHash[:]) {
+			return paymentResultMsg{err: fmt.Errorf("invoice from %s doesn't match its LNURL metadata", address)}
+		}
+
+		log.Printf("✅ Invoice received from %s", address)
+		return invoiceReadyMsg{invoice: invoice, decoded: decoded, target: address}
+	}
+}
+
+// payInvoiceCmd pays a confirmed invoice from the given wallet
+func (a *appContext) payInvoiceCmd(invoice string, amountSats int64, wallet config.Wallet) tea.Cmd {
+	return func() tea.Msg {
+		if _, err := a.payInvoice(context.Background(), invoice, wallet); err != nil {
+			return paymentResultMsg{err: err}
+		}
+		return paymentResultMsg{wallet: wallet.Name, amountSats: amountSats}
+	}
+}
+
+// payInvoice pays an invoice via NWC, picking the transport that fits the
+// auth method and whether the wallet has the persistent subscription
+func (a *appContext) payInvoice(ctx context.Context, invoice string, wallet config.Wallet) (*nwc.PayInvoiceResult, error) {
+	log.Printf("💸 Paying invoice via NWC wallet '%s' (auth: %s)...", wallet.Name, a.authMethod)
+	var result *nwc.PayInvoiceResult
+	if a.authMethod == "nsec" && a.privKey != "" {
+		// Use NWC client with private key (old method)
+		log.Printf("🔑 Creating NWC client with nsec...")
+		nwcClient, err := nwc.NewNWCClient(wallet.NWC)
+		if err != nil {
+			log.Printf("❌ Failed to create NWC client: %v", err)
+			return nil, fmt.Errorf("failed to create NWC client: %w", err)
+		}
+		log.Printf("✅ NWC client created, calling PayInvoice...")
+		if result, err = nwcClient.PayInvoice(ctx, invoice); err != nil {
+			log.Printf("❌ PayInvoice failed: %v", err)
+			return nil, fmt.Errorf("failed to pay invoice: %w", err)
+		}
+	} else if wallet.NWC == a.nwcString {
+		// Use persistent NWC subscription with Pleb Signer
+		log.Printf("🔏 Using persistent NWC subscription with Pleb Signer...")
+		var err error
+		if result, err = a.payInvoiceWithPersistentSub(ctx, invoice); err != nil {
+			log.Printf("❌ Payment failed: %v", err)
+			return nil, fmt.Errorf("failed to pay invoice: %w", err)
+		}
+	} else {
+		// The persistent subscription only covers the default wallet
+		log.Printf("🔏 Using one-off NWC subscription for wallet '%s'...", wallet.Name)
+		var err error
+		if result, err = payInvoiceWithSigner(ctx, wallet.NWC, invoice, a.pubKey, a.signer); err != nil {
+			log.Printf("❌ Payment failed: %v", err)
+			return nil, fmt.Errorf("failed to pay invoice: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// payConfirmScreen asks whether to pay an invoice, and with which wallet
+type payConfirmScreen struct {
+	invoice string
+	info    *zap.Invoice
+	target  string // Lightning address the invoice was requested from, if any
+	wallet  int    // Index of the wallet paying it
+}
+
+// open asks whether to pay invoice from the default wallet
+func (p *payConfirmScreen) open(app *appContext, invoice string, info *zap.Invoice, target string) {
+	*p = payConfirmScreen{
+		invoice: invoice,
+		info:    info,
+		target:  target,
+		wallet:  max(app.walletIndex(app.defaultWallet), 0),
+	}
+	app.overlay = overlayPayConfirm
+}
+
+// close closes the prompt without paying
+func (p *payConfirmScreen) close(app *appContext) {
+	*p = payConfirmScreen{}
+	app.overlay = overlayNone
+}
+
+// Update pays the invoice, picks another wallet or cancels
+func (p *payConfirmScreen) Update(app *appContext, msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "y", "Y":
+		invoice, amount := p.invoice, p.info.AmountSats()
+		wallet := app.wallets[p.wallet]
+		p.close(app)
+		app.statusMsg = "⚡ Paying invoice..."
+		return app.payInvoiceCmd(invoice, amount, wallet)
+	case "tab":
+		if len(app.wallets) > 0 {
+			p.wallet = (p.wallet + 1) % len(app.wallets)
+		}
+	case "n", "N", "esc":
+		p.close(app)
+		app.statusMsg = "Payment cancelled"
+	}
+	return nil
+}
+
+// Status describes the payment being confirmed
+func (p *payConfirmScreen) Status(app *appContext) string {
+	prompt := "⚡ Pay " + formatSats(p.info.AmountMsats)
+	if p.target != "" {
+		prompt += " to " + p.target
+	} else if p.info.Description != "" {
+		prompt += " for \"" + truncateRunes(p.info.Description, 30) + "\""
+	}
+	if p.wallet < len(app.wallets) {
+		prompt += " via " + app.wallets[p.wallet].Name
+		exceeded := config.CheckBudget(app.wallets[p.wallet], app.spending, p.info.AmountSats(), time.Now())
+		if len(exceeded) > 0 {
+			prompt += " ⚠️ exceeds " + strings.Join(exceeded, ", ")
+		}
+	}
+	prompt += "? (y/n"
+	if len(app.wallets) > 1 {
+		prompt += ", Tab wallet"
+	}
+	return prompt + ")"
+}
+
+// View leaves the feed showing under the prompt
+func (p *payConfirmScreen) View(app *appContext) string {
+	return ""
+}
+
+// sendSatsScreen asks for a lightning address and then the amount to send it
+type sendSatsScreen struct {
+	address     string
+	amount      string
+	enteringAmt bool // Whether the address is in and the amount is being typed
+}
+
+// open asks for the lightning address to send sats to
+func (s *sendSatsScreen) open(app *appContext) {
+	*s = sendSatsScreen{}
+	app.overlay = overlaySendSats
+}
+
+// openAmount asks how many sats to send to address
+func (s *sendSatsScreen) openAmount(app *appContext, address string) {
+	*s = sendSatsScreen{address: address, amount: "21", enteringAmt: true}
+	app.overlay = overlaySendSats
+}
+
+// close closes the prompt without sending anything
+func (s *sendSatsScreen) close(app *appContext) {
+	*s = sendSatsScreen{}
+	app.overlay = overlayNone
+}
+
+// Update types the address or amount, and requests an invoice once both are in
+func (s *sendSatsScreen) Update(app *appContext, msg tea.KeyMsg) tea.Cmd {
+	if !s.enteringAmt {
+		switch msg.String() {
+		case "esc":
+			s.close(app)
+			app.statusMsg = ""
+		case "enter":
+			address := trimLightningScheme(strings.TrimSpace(s.address))
+			if _, err := zap.ResolvePayEndpoint(address); err != nil {
+				app.statusMsg = "⚠️ Enter a lightning address like name@domain.com or an LNURL"
+				return nil
+			}
+			s.openAmount(app, address)
+		case "backspace":
+			if len(s.address) > 0 {
+				s.address = s.address[:len(s.address)-1]
+			}
+		default:
+			s.address += msg.String()
+		}
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		s.close(app)
+		app.statusMsg = ""
+	case "enter":
+		amount, err := strconv.ParseInt(s.amount, 10, 64)
+		if err != nil || amount <= 0 {
+			app.statusMsg = "⚠️ Invalid amount"
+			return nil
+		}
+		address := s.address
+		s.close(app)
+		app.statusMsg = "⚡ Requesting invoice from " + address + "..."
+		return fetchAddressInvoiceCmd(app.lnurl, address, amount)
+	case "backspace":
+		if len(s.amount) > 0 {
+			s.amount = s.amount[:len(s.amount)-1]
+		}
+	default:
+		// Only accept digits
+		if len(msg.String()) == 1 {
+			ch := msg.String()[0]
+			if ch >= '0' && ch <= '9' {
+				s.amount += msg.String()
+			}
+		}
+	}
+	return nil
+}
+
+// Status shows the address or amount being typed
+func (s *sendSatsScreen) Status(app *appContext) string {
+	if !s.enteringAmt {
+		return fmt.Sprintf("⚡ Send to lightning address: %s_ (Enter to continue, Esc to cancel)", s.address)
+	}
+	return fmt.Sprintf("⚡ Amount to send to %s (sats): %s_ (Enter to confirm, Esc to cancel)", s.address, s.amount)
+}
+
+// View leaves the feed showing under the prompt
+func (s *sendSatsScreen) View(app *appContext) string {
+	return ""
+}
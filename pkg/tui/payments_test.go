@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nbd-wtf/go-nostr"
+	"noscli/internal/mockwallet"
+	"noscli/internal/testrelay"
+	"noscli/internal/testsigner"
+	"noscli/pkg/zap"
+)
+
+func TestPayInvoiceWithSigner(t *testing.T) {
+	relay := testrelay.New()
+	defer relay.Close()
+	wallet := mockwallet.New(relay, 50_000_000)
+	s, pubKey := testsigner.New()
+
+	invoice, _ := mockwallet.Invoice(1_000_000, "zap")
+	result, err := payInvoiceWithSigner(context.Background(), wallet.URI(), invoice, pubKey, s)
+	if err != nil {
+		t.Fatalf("payInvoiceWithSigner: %v", err)
+	}
+	if result.Preimage == "" {
+		t.Error("no preimage")
+	}
+
+	// Pleb Signer signs requests with the user's key, not the connection secret
+	requests := wallet.Requests()
+	if len(requests) != 1 || requests[0].Event.PubKey != pubKey {
+		t.Errorf("wallet got %+v, want one request signed by %s", requests, pubKey)
+	}
+
+	wallet.Script("pay_invoice", mockwallet.Result{Code: "PAYMENT_FAILED", Message: "no route"})
+	if _, err := payInvoiceWithSigner(context.Background(), wallet.URI(), invoice, pubKey, s); err == nil || !strings.Contains(err.Error(), "no route") {
+		t.Errorf("err = %v, want the wallet's error", err)
+	}
+}
+
+func TestPayInvoiceWithPersistentSub(t *testing.T) {
+	relay := testrelay.New()
+	defer relay.Close()
+	wallet := mockwallet.New(relay, 50_000_000)
+	wallet.SetFees(1000)
+	s, pubKey := testsigner.New()
+
+	m := &Model{appContext: &appContext{
+		pool:            nostr.NewSimplePool(context.Background()),
+		signer:          s,
+		pubKey:          pubKey,
+		nwcString:       wallet.URI(),
+		nwcResponseChan: make(chan nostr.RelayEvent, 10),
+		pendingPayments: make(map[string]chan *nostr.Event),
+	}}
+	m.startNWCSubscription()
+	defer m.stopNWCSubscription()
+
+	for relay.Subscriptions() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Route responses to waiting payments the way the program loop does
+	go func() {
+		for {
+			select {
+			case event := <-m.nwcResponseChan:
+				m.Update(nwcResponseMsg{event: event.Event})
+			case <-time.After(10 * time.Second):
+				return
+			}
+		}
+	}()
+
+	invoice, _ := mockwallet.Invoice(2_100_000, "")
+	result, err := m.payInvoiceWithPersistentSub(context.Background(), invoice)
+	if err != nil {
+		t.Fatalf("payInvoiceWithPersistentSub: %v", err)
+	}
+	if result.FeesPaid != 1000 {
+		t.Errorf("fees = %d, want 1000", result.FeesPaid)
+	}
+	if got := wallet.Balance(); got != 50_000_000-2_101_000 {
+		t.Errorf("balance = %d, want %d", got, 50_000_000-2_101_000)
+	}
+
+	wallet.Script("pay_invoice", mockwallet.Result{NoReply: true})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := m.payInvoiceWithPersistentSub(ctx, invoice); err == nil {
+		t.Error("payment without a reply succeeded")
+	}
+}
+
+func TestFetchAddressInvoiceCmd(t *testing.T) {
+	metadata := `[["text/plain","alice"],["text/identifier","alice@example.com"]]`
+	invoiceFor := metadata
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/callback" {
+			invoice, _ := mockwallet.Invoice(21_000, invoiceFor)
+			json.NewEncoder(w).Encode(zap.LNURLCallbackResponse{PR: invoice})
+			return
+		}
+		json.NewEncoder(w).Encode(zap.LNURLResponse{
+			Callback:    server.URL + "/callback",
+			MinSendable: 1000,
+			MaxSendable: 100_000_000,
+			Metadata:    metadata,
+			Tag:         "payRequest",
+		})
+	}))
+	defer server.Close()
+	lnurl := zap.NewClient(server.Client())
+	address := "alice@" + strings.TrimPrefix(server.URL, "https://")
+
+	msg, ok := fetchAddressInvoiceCmd(lnurl, address, 21)().(invoiceReadyMsg)
+	if !ok || msg.decoded.AmountMsats != 21_000 || msg.target != address {
+		t.Fatalf("got %+v, want an invoice for 21 sats", msg)
+	}
+
+	// An invoice committing to anything but the metadata is refused
+	invoiceFor = `[["text/plain","mallory"]]`
+	result, ok := fetchAddressInvoiceCmd(lnurl, address, 21)().(paymentResultMsg)
+	if !ok || result.err == nil || !strings.Contains(result.err.Error(), "metadata") {
+		t.Errorf("got %+v, want a metadata mismatch", result)
+	}
+}
+
+func TestInvoiceDescriptionsTruncateByRune(t *testing.T) {
+	// Multi-byte characters straddle the byte offsets a byte cut would use
+	description := "Café ☕ " + strings.Repeat("☕", 60)
+	invoice, _ := mockwallet.DescribedInvoice(21_000, description)
+
+	line := renderInvoiceLine(invoice)
+	if !utf8.ValidString(line) || !strings.Contains(line, string([]rune(description)[:50])+"...") {
+		t.Errorf("invoice line = %q", line)
+	}
+
+	decoded, err := zap.DecodeInvoice(invoice)
+	if err != nil {
+		t.Fatalf("DecodeInvoice: %v", err)
+	}
+	p := &payConfirmScreen{info: decoded}
+	if prompt := p.Status(&appContext{}); !utf8.ValidString(prompt) || !strings.Contains(prompt, `"`+string([]rune(description)[:30])+`..."`) {
+		t.Errorf("pay prompt = %q", prompt)
+	}
+}
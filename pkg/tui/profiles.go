@@ -0,0 +1,324 @@
+package tui
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"noscli/pkg/client"
+)
+
+// profileBatchDelay is how long requests for unknown profiles gather before
+// they're fetched together, so one render asks the relays once
+const profileBatchDelay = 100 * time.Millisecond
+
+// profileRetryAfter is how long a pubkey no relay had a profile for is left
+// alone before it's asked for again
+const profileRetryAfter = 5 * time.Minute
+
+// nip05Workers is how many NIP-05 identifiers are checked at once
+const nip05Workers = 4
+
+// profileSaveDelay gathers cache changes into one write to disk
+const profileSaveDelay = time.Second
+
+// profilesMsg delivers fetched profiles to Update
+type profilesMsg struct {
+	profiles  map[string]*client.Profile
+	requested []string         // Pubkeys the fetch was for, found or not
+	recheck   []client.Profile // Fresh cached profiles whose NIP-05 check is due
+}
+
+// nip05Result is the outcome of checking one NIP-05 identifier
+type nip05Result struct {
+	pubkey     string
+	identifier string
+	valid      bool
+}
+
+// nip05Msg delivers NIP-05 checks to Update. Checks that failed to run
+// have no result, so they're retried later.
+type nip05Msg struct {
+	checked []string // Every pubkey that was due
+	results []nip05Result
+}
+
+// profileService caches profiles and fetches unknown and stale ones in the
+// background. Views request profiles while rendering and fetches finish on
+// their own goroutines, so it's guarded by a mutex. Results reach Update as
+// profilesMsg through updates and are stored from there.
+type profileService struct {
+	mu       sync.Mutex
+	cache    *client.ProfileCache
+	path     string               // Where the cache is saved; empty to keep it in memory
+	missing  map[string]time.Time // pubkey -> when a fetch last came back without it
+	inFlight map[string]bool      // Queued or being fetched
+	checking map[string]bool      // NIP-05 identifiers being checked, by pubkey
+	queue    []string
+	recheck  []client.Profile // Cached profiles to check with the next flush
+	saving   bool             // A save is scheduled
+	saveMu   sync.Mutex       // Keeps writes to disk in order
+	client   *client.Client   // Client of the newest request, used by the next fetch
+	updates  chan profilesMsg
+
+	// fetch queries the relays; tests replace it
+	fetch func(c *client.Client, ctx context.Context, pubkeys []string) (map[string]*client.Profile, error)
+}
+
+func newProfileService() *profileService {
+	return &profileService{
+		cache:    client.NewProfileCache(),
+		missing:  make(map[string]time.Time),
+		inFlight: make(map[string]bool),
+		checking: make(map[string]bool),
+		updates:  make(chan profilesMsg, 16),
+		fetch:    (*client.Client).FetchProfiles,
+	}
+}
+
+// useCache replaces the in-memory cache with one loaded from path, which
+// is saved back after every change
+func (s *profileService) useCache(cache *client.ProfileCache, path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache, s.path = cache, path
+}
+
+// name returns the display name of pubkey, or "" if we don't know it
+func (s *profileService) name(pubkey string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cached := s.cache.Get(pubkey); cached != nil {
+		return cached.BestName()
+	}
+	return ""
+}
+
+// profile returns a copy of pubkey's cached profile
+func (s *profileService) profile(pubkey string) (client.CachedProfile, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cached := s.cache.Get(pubkey); cached != nil {
+		return *cached, true
+	}
+	return client.CachedProfile{}, false
+}
+
+// verified reports whether pubkey's NIP-05 identifier checked out
+func (s *profileService) verified(pubkey string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cached := s.cache.Get(pubkey)
+	return cached != nil && cached.NIP05 != "" && cached.NIP05Valid
+}
+
+// request queues the pubkeys we have no fresh profile for and aren't
+// already fetching. The queue is fetched with c after profileBatchDelay.
+// Fresh profiles aren't fetched, but go along to have their NIP-05
+// identifier checked if that's due.
+func (s *profileService) request(c *client.Client, pubkeys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	wasEmpty := len(s.queue) == 0 && len(s.recheck) == 0
+	for _, pubkey := range pubkeys {
+		if s.inFlight[pubkey] {
+			continue
+		}
+		if cached := s.cache.Get(pubkey); cached != nil && !cached.Stale(now) {
+			if !s.checking[pubkey] && cached.NIP05Due(now) {
+				s.checking[pubkey] = true
+				s.recheck = append(s.recheck, cached.Profile)
+			}
+			continue
+		}
+		if missed, ok := s.missing[pubkey]; ok && now.Sub(missed) < profileRetryAfter {
+			continue
+		}
+		s.inFlight[pubkey] = true
+		s.queue = append(s.queue, pubkey)
+	}
+	s.client = c
+
+	if wasEmpty && (len(s.queue) > 0 || len(s.recheck) > 0) {
+		time.AfterFunc(profileBatchDelay, s.flush)
+	}
+}
+
+// flush fetches the queued profiles and sends the result to updates, with
+// the cached profiles due for a NIP-05 check
+func (s *profileService) flush() {
+	s.mu.Lock()
+	pubkeys, due, c := s.queue, s.recheck, s.client
+	s.queue, s.recheck = nil, nil
+	s.mu.Unlock()
+
+	msg := profilesMsg{requested: pubkeys, recheck: due}
+	defer func() {
+		if r := recover(); r != nil {
+			// Catch panics from relay operations
+		}
+		s.updates <- msg
+	}()
+	if len(pubkeys) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	log.Printf("👤 Fetching %d profiles...", len(pubkeys))
+	profiles, err := s.fetch(c, ctx, pubkeys)
+	if err != nil {
+		log.Printf("⚠️  Failed to fetch profiles: %v", err)
+	}
+	msg.profiles = profiles
+}
+
+// store keeps the profiles in msg that are newer than the ones we have and
+// saves the cache
+func (s *profileService) store(msg profilesMsg) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, pubkey := range msg.requested {
+		delete(s.inFlight, pubkey)
+		if msg.profiles[pubkey] == nil {
+			s.missing[pubkey] = now
+		}
+	}
+	for pubkey, profile := range msg.profiles {
+		s.cache.Put(profile, now)
+		delete(s.missing, pubkey)
+	}
+	s.save()
+}
+
+// nip05Due returns the profiles among pubkeys whose NIP-05 identifier
+// needs checking and marks them as being checked
+func (s *profileService) nip05Due(pubkeys []string) []client.Profile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var due []client.Profile
+	for _, pubkey := range pubkeys {
+		cached := s.cache.Get(pubkey)
+		if cached == nil || s.checking[pubkey] || !cached.NIP05Due(now) {
+			continue
+		}
+		s.checking[pubkey] = true
+		due = append(due, cached.Profile)
+	}
+	return due
+}
+
+// storeNIP05 records NIP-05 checks and saves the cache
+func (s *profileService) storeNIP05(msg nip05Msg) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, pubkey := range msg.checked {
+		delete(s.checking, pubkey)
+	}
+	now := time.Now()
+	for _, result := range msg.results {
+		s.cache.SetNIP05(result.pubkey, result.identifier, result.valid, now)
+	}
+	s.save()
+}
+
+// save schedules writing the cache to disk after profileSaveDelay, so a
+// burst of changes is written once and never from Update; the caller holds mu
+func (s *profileService) save() {
+	if s.path == "" || s.saving {
+		return
+	}
+	s.saving = true
+	time.AfterFunc(profileSaveDelay, s.flushSave)
+}
+
+// flushSave writes the cache to disk if a save is scheduled. The cache is
+// encoded under mu but written without it, so renders don't wait on the disk.
+func (s *profileService) flushSave() {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.Lock()
+	if !s.saving {
+		s.mu.Unlock()
+		return
+	}
+	s.saving = false
+	path := s.path
+	data, err := s.cache.Encode()
+	s.mu.Unlock()
+
+	if err == nil {
+		err = client.WriteProfileCache(path, data)
+	}
+	if err != nil {
+		log.Printf("⚠️  Failed to save profile cache: %v", err)
+	}
+}
+
+// nip05Badge marks names whose NIP-05 identifier checked out. Rendering a
+// name asks for its profile, so stale profiles and checks get refreshed.
+func (a *appContext) nip05Badge(pubkey string) string {
+	a.requestProfiles(pubkey)
+	if a.profiles.verified(pubkey) {
+		return " ✓"
+	}
+	return ""
+}
+
+// waitForProfiles returns a Cmd that waits for the next profile fetch
+func waitForProfiles(updates chan profilesMsg) tea.Cmd {
+	return func() tea.Msg {
+		return <-updates
+	}
+}
+
+// verifyNIP05Cmd checks the NIP-05 identifiers of profiles, a few at a time
+func verifyNIP05Cmd(c *client.Client, profiles []client.Profile) tea.Cmd {
+	if len(profiles) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		var mu sync.Mutex
+		msg := nip05Msg{}
+		for _, profile := range profiles {
+			msg.checked = append(msg.checked, profile.PubKey)
+		}
+		var wg sync.WaitGroup
+		work := make(chan client.Profile)
+		for i := 0; i < min(nip05Workers, len(profiles)); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for profile := range work {
+					ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+					valid, err := c.VerifyNIP05(ctx, profile.PubKey, profile.NIP05)
+					cancel()
+					if err != nil {
+						log.Printf("⚠️  Can't check NIP-05 %s: %v", profile.NIP05, err)
+						continue
+					}
+					mu.Lock()
+					msg.results = append(msg.results, nip05Result{profile.PubKey, profile.NIP05, valid})
+					mu.Unlock()
+				}
+			}()
+		}
+		for _, profile := range profiles {
+			work <- profile
+		}
+		close(work)
+		wg.Wait()
+		return msg
+	}
+}
@@ -0,0 +1,233 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"noscli/pkg/client"
+	"noscli/pkg/zap"
+)
+
+// waitProfiles returns the next fetch result from s
+func waitProfiles(t *testing.T, s *profileService) profilesMsg {
+	t.Helper()
+	select {
+	case msg := <-s.updates:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no profiles delivered")
+		return profilesMsg{}
+	}
+}
+
+func TestProfileServiceBatches(t *testing.T) {
+	relay, pool := newTestRelay(t)
+	alice, bob, carol := newTestUser(), newTestUser(), newTestUser()
+	alice.publish(t, relay, 0, `{"name":"alice"}`, 100)
+	bob.publish(t, relay, 0, `{"display_name":"Bob","name":"bob"}`, 100)
+	c := readClient(pool, "", relay.URL)
+
+	s := newProfileService()
+	// Renders ask for the same names over and over, from several goroutines
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.request(c, alice.pk, bob.pk, carol.pk)
+			s.name(alice.pk)
+		}()
+	}
+	wg.Wait()
+
+	msg := waitProfiles(t, s)
+	if len(msg.requested) != 3 {
+		t.Errorf("requested %v, want each pubkey once", msg.requested)
+	}
+	s.store(msg)
+	if s.name(alice.pk) != "alice" || s.name(bob.pk) != "Bob" || s.name(carol.pk) != "" {
+		t.Errorf("names = %q, %q, %q", s.name(alice.pk), s.name(bob.pk), s.name(carol.pk))
+	}
+
+	requests := relay.Requests()
+	if len(requests) != 1 || len(requests[0][0].Authors) != 3 {
+		t.Fatalf("relay got %v, want one query for all three", requests)
+	}
+
+	// Known profiles and ones no relay had aren't fetched again
+	s.request(c, alice.pk, bob.pk, carol.pk)
+	time.Sleep(2 * profileBatchDelay)
+	if got := len(relay.Requests()); got != 1 {
+		t.Errorf("%d queries after asking again, want 1", got)
+	}
+}
+
+func TestProfileServiceKeepsNewest(t *testing.T) {
+	s := newProfileService()
+	alice := newTestUser()
+
+	s.store(profilesMsg{profiles: map[string]*client.Profile{alice.pk: {PubKey: alice.pk, Name: "new", CreatedAt: 200}}})
+	s.store(profilesMsg{profiles: map[string]*client.Profile{alice.pk: {PubKey: alice.pk, Name: "old", CreatedAt: 100}}})
+	if got := s.name(alice.pk); got != "new" {
+		t.Errorf("name = %q, want the newer profile's", got)
+	}
+}
+
+func TestProfileServiceFetchError(t *testing.T) {
+	s := newProfileService()
+	s.fetch = func(*client.Client, context.Context, []string) (map[string]*client.Profile, error) {
+		panic("relay blew up")
+	}
+	alice := newTestUser()
+
+	// A failed fetch still reports back, so the pubkey isn't stuck in flight
+	s.request(&client.Client{}, alice.pk)
+	msg := waitProfiles(t, s)
+	if len(msg.requested) != 1 || msg.requested[0] != alice.pk {
+		t.Errorf("requested = %v", msg.requested)
+	}
+	s.store(msg)
+	if s.inFlight[alice.pk] {
+		t.Error("alice is still in flight")
+	}
+	if _, ok := s.missing[alice.pk]; !ok {
+		t.Error("alice isn't marked as missing")
+	}
+}
+
+func TestProfileServiceDiskCache(t *testing.T) {
+	relay, pool := newTestRelay(t)
+	alice := newTestUser()
+	alice.publish(t, relay, 0, `{"name":"alice","nip05":"alice@example.com"}`, 200)
+	c := readClient(pool, "", relay.URL)
+
+	// Yesterday's cache still names alice right away
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	cache := client.NewProfileCache()
+	cache.Put(&client.Profile{PubKey: alice.pk, Name: "alice (old)", CreatedAt: 100}, time.Now().Add(-client.ProfileStaleAfter-time.Hour))
+	s := newProfileService()
+	s.useCache(cache, path)
+	if got := s.name(alice.pk); got != "alice (old)" {
+		t.Errorf("name = %q before fetching", got)
+	}
+
+	// but it's stale, so asking for it fetches it again
+	s.request(c, alice.pk)
+	s.store(waitProfiles(t, s))
+	if got := s.name(alice.pk); got != "alice" {
+		t.Errorf("name = %q after fetching", got)
+	}
+
+	// The new identifier is due for a check, once
+	due := s.nip05Due([]string{alice.pk})
+	if len(due) != 1 || due[0].NIP05 != "alice@example.com" || len(s.nip05Due([]string{alice.pk})) != 0 {
+		t.Fatalf("due = %+v", due)
+	}
+	app := &appContext{profiles: s}
+	if app.nip05Badge(alice.pk) != "" {
+		t.Error("badge before the check")
+	}
+	s.storeNIP05(nip05Msg{checked: []string{alice.pk}, results: []nip05Result{{alice.pk, "alice@example.com", true}}})
+	if app.nip05Badge(alice.pk) != " ✓" {
+		t.Error("no badge after a valid check")
+	}
+
+	// The changes are written together a little later, off the caller's
+	// goroutine, and everything makes it to disk
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("cache written right away: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		loaded, err := client.LoadProfileCache(path)
+		if err != nil {
+			t.Fatalf("LoadProfileCache: %v", err)
+		}
+		if got := loaded.Get(alice.pk); got != nil && got.Name == "alice" && got.NIP05Valid {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("saved profile = %+v", loaded.Get(alice.pk))
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if leftovers, _ := filepath.Glob(path + ".*"); len(leftovers) != 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+}
+
+func TestProfileServiceRechecksCachedNIP05(t *testing.T) {
+	s := newProfileService()
+	fetches := 0
+	s.fetch = func(*client.Client, context.Context, []string) (map[string]*client.Profile, error) {
+		fetches++
+		return nil, nil
+	}
+	alice := newTestUser()
+
+	// A fresh profile whose identifier was last checked two days ago
+	now := time.Now()
+	cache := client.NewProfileCache()
+	cache.Put(&client.Profile{PubKey: alice.pk, Name: "alice", NIP05: "alice@example.com"}, now)
+	cache.SetNIP05(alice.pk, "alice@example.com", true, now.Add(-2*client.NIP05RecheckAfter))
+	s.useCache(cache, "")
+
+	// Rendering the name is enough to get it checked, without a fetch
+	app := &appContext{profiles: s}
+	app.nip05Badge(alice.pk)
+	app.nip05Badge(alice.pk)
+	msg := waitProfiles(t, s)
+	if len(msg.requested) != 0 || len(msg.recheck) != 1 || msg.recheck[0].NIP05 != "alice@example.com" || fetches != 0 {
+		t.Fatalf("got %+v after %d fetches, want alice's identifier to recheck", msg, fetches)
+	}
+
+	// It isn't queued again while the check runs
+	s.request(&client.Client{}, alice.pk)
+	select {
+	case msg := <-s.updates:
+		t.Errorf("queued again: %+v", msg)
+	case <-time.After(2 * profileBatchDelay):
+	}
+	s.storeNIP05(nip05Msg{checked: []string{alice.pk}, results: []nip05Result{{alice.pk, "alice@example.com", true}}})
+	if profile, _ := s.profile(alice.pk); profile.NIP05Due(time.Now()) {
+		t.Error("still due after the check")
+	}
+}
+
+func TestResolveZapTargetFromCache(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(zap.LNURLResponse{
+			Callback:    server.URL + "/callback",
+			MinSendable: 1000,
+			MaxSendable: 100_000_000,
+			Tag:         "payRequest",
+			AllowsNostr: true,
+		})
+	}))
+	defer server.Close()
+
+	// No relays: the lightning address can only come from the cache
+	alice := newTestUser()
+	c := &client.Client{LNURL: zap.NewClient(server.Client())}
+	cached := &client.Profile{PubKey: alice.pk, LUD16: "alice@" + strings.TrimPrefix(server.URL, "https://")}
+
+	msg := resolveZapTargetCmd(c, alice.pk, cached)().(zapTargetMsg)
+	if msg.err != nil || msg.target == nil || msg.target.Info.Callback != server.URL+"/callback" {
+		t.Errorf("got %+v", msg)
+	}
+
+	// Without a cached address it has to ask the relays
+	msg = resolveZapTargetCmd(c, alice.pk, &client.Profile{PubKey: alice.pk})().(zapTargetMsg)
+	if msg.err == nil {
+		t.Error("resolved a profile without a lightning address or relays")
+	}
+}
@@ -0,0 +1,316 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/nbd-wtf/go-nostr"
+	"noscli/pkg/zap"
+)
+
+// receiptFetchDelay gives the recipient's LNURL server time to publish the
+// receipt before we look for it after a zap
+const receiptFetchDelay = 5 * time.Second
+
+// zapReceiptsMsg carries zap receipts (kind 9735) for notes we display
+type zapReceiptsMsg struct {
+	events []nostr.Event
+}
+
+// zapperKeysMsg maps recipient pubkeys to the nostrPubkey their LNURL server
+// signs receipts with ("" if they have none). Recipients whose lookup failed
+// are left out, so their receipts stay unchecked until it's tried again.
+type zapperKeysMsg struct {
+	requested []string
+	keys      map[string]string
+}
+
+// zapReceiptInfo is a parsed receipt and the outcome of validating it
+type zapReceiptInfo struct {
+	receipt *zap.Receipt // nil if the receipt couldn't be parsed
+	err     error        // Why the receipt is invalid
+	checked bool         // Whether validation has run
+}
+
+// zapTotal sums the valid zaps on a note
+type zapTotal struct {
+	msats int64
+	count int
+}
+
+// fetchZapReceiptsCmd fetches zap receipts for the given notes
+func fetchZapReceiptsCmd(pool *nostr.SimplePool, relays []string, eventIDs []string) tea.Cmd {
+	return func() tea.Msg {
+		defer func() {
+			if r := recover(); r != nil {
+				// Catch panics from relay operations
+			}
+		}()
+
+		if len(eventIDs) == 0 {
+			return zapReceiptsMsg{}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		filter := nostr.Filter{
+			Kinds: []int{zap.KindZapReceipt},
+			Tags:  nostr.TagMap{"e": eventIDs},
+			Limit: 500,
+		}
+
+		var receipts []nostr.Event
+		eventsChannel := pool.SubManyEose(ctx, relays, []nostr.Filter{filter})
+		for event := range eventsChannel {
+			if event.Event == nil {
+				continue
+			}
+			receipts = append(receipts, *event.Event)
+		}
+
+		return zapReceiptsMsg{receipts}
+	}
+}
+
+// fetchZapperKeysCmd looks up the nostrPubkey of each recipient's LNURL
+// server, which is the only key allowed to sign their zap receipts
+func fetchZapperKeysCmd(pool *nostr.SimplePool, lnurl *zap.Client, relays []string, pubkeys []string) tea.Cmd {
+	return func() (msg tea.Msg) {
+		keys := make(map[string]string)
+		defer func() {
+			if r := recover(); r != nil {
+				// Catch panics from relay operations; what wasn't looked up is retried
+				msg = zapperKeysMsg{requested: pubkeys, keys: keys}
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		filter := nostr.Filter{
+			Kinds:   []int{0},
+			Authors: pubkeys,
+		}
+
+		// Keep the newest profile per author
+		newest := make(map[string]*nostr.Event)
+		eventsChannel := pool.SubManyEose(ctx, relays, []nostr.Filter{filter})
+		for event := range eventsChannel {
+			if event.Event == nil {
+				continue
+			}
+			if existing, ok := newest[event.Event.PubKey]; !ok || event.Event.CreatedAt > existing.CreatedAt {
+				newest[event.Event.PubKey] = event.Event
+			}
+		}
+
+		// A profile without a usable lightning address means nothing can
+		// sign valid receipts for them. A missing profile or an LNURL server
+		// that didn't answer may just be down, so those are left out.
+		for pk, profile := range newest {
+			target := zap.GetLightningAddress(profile.Content)
+			if target == "" {
+				keys[pk] = ""
+				continue
+			}
+			endpoint, err := zap.ResolvePayEndpoint(target)
+			if err != nil {
+				keys[pk] = ""
+				continue
+			}
+			lnurlCtx, lnurlCancel := context.WithTimeout(context.Background(), 10*time.Second)
+			info, err := lnurl.FetchLNURLPay(lnurlCtx, endpoint)
+			lnurlCancel()
+			if err != nil {
+				log.Printf("⚠️  Can't verify zaps to %s: %v", pk[:8], err)
+				continue
+			}
+			keys[pk] = info.NostrPubkey
+		}
+
+		return zapperKeysMsg{requested: pubkeys, keys: keys}
+	}
+}
+
+// addZapReceipts parses new receipts, validates the ones whose recipient's
+// LNURL key is known and returns a command to look up the missing keys,
+// including those of earlier receipts whose lookup failed
+func (m *Model) addZapReceipts(events []nostr.Event) tea.Cmd {
+	for _, evt := range events {
+		if _, seen := m.zapReceipts[evt.ID]; seen {
+			continue
+		}
+
+		evt := evt
+		receipt, err := zap.ParseReceipt(&evt)
+		if err != nil {
+			m.zapReceipts[evt.ID] = &zapReceiptInfo{err: err, checked: true}
+			continue
+		}
+		info := &zapReceiptInfo{receipt: receipt}
+		m.zapReceipts[evt.ID] = info
+		m.zapDialog.matchReceipt(info)
+
+		if receipt.Sender == m.pubKey && receipt.EventID != "" && receipt.EventID == m.awaitingReceipt {
+			m.awaitingReceipt = ""
+			m.statusMsg = "🧾 Zap receipt published for " + formatSats(receipt.AmountMsats)
+		}
+	}
+
+	m.validateZapReceipts()
+
+	var missing []string
+	for _, info := range m.zapReceipts {
+		recipient := ""
+		if info.receipt != nil {
+			recipient = info.receipt.Recipient
+		}
+		if _, known := m.zapperKeys[recipient]; !info.checked && !known && !m.zapperKeysPending[recipient] {
+			m.zapperKeysPending[recipient] = true
+			missing = append(missing, recipient)
+		}
+	}
+
+	var cmds []tea.Cmd
+	if len(missing) > 0 {
+		cmds = append(cmds, fetchZapperKeysCmd(m.pool, m.lnurl, m.relays, missing))
+	}
+	m.requestProfiles(m.zapSenders()...)
+	return tea.Batch(cmds...)
+}
+
+// validateZapReceipts validates every unchecked receipt whose recipient's
+// LNURL key is known, then recomputes the per-note totals
+func (m *Model) validateZapReceipts() {
+	for id, info := range m.zapReceipts {
+		if info.checked {
+			continue
+		}
+		key, known := m.zapperKeys[info.receipt.Recipient]
+		if !known {
+			continue
+		}
+		info.err = info.receipt.Validate(key)
+		info.checked = true
+		if info.err != nil {
+			log.Printf("⚠️  Invalid zap receipt %s: %v", id[:8], info.err)
+		}
+	}
+
+	m.zapTotals = make(map[string]zapTotal)
+	for _, info := range m.zapReceipts {
+		if !info.checked || info.err != nil || info.receipt.EventID == "" {
+			continue
+		}
+		total := m.zapTotals[info.receipt.EventID]
+		total.msats += info.receipt.AmountMsats
+		total.count++
+		m.zapTotals[info.receipt.EventID] = total
+	}
+}
+
+// zapSenders returns the senders of the zap receipts we have
+func (m *Model) zapSenders() []string {
+	seen := make(map[string]bool)
+	var senders []string
+	for _, info := range m.zapReceipts {
+		if info.receipt == nil || info.receipt.Sender == "" || seen[info.receipt.Sender] {
+			continue
+		}
+		seen[info.receipt.Sender] = true
+		senders = append(senders, info.receipt.Sender)
+	}
+	return senders
+}
+
+// zapReceiptHeader returns the header line for a zap receipt in Notifications
+func (m *Model) zapReceiptHeader(evt nostr.Event) string {
+	info := m.zapReceipts[evt.ID]
+	if info == nil || info.receipt == nil || info.receipt.Sender == "" {
+		return "⚡ Zap"
+	}
+	sender := m.profiles.name(info.receipt.Sender)
+	if sender == "" {
+		sender = info.receipt.Sender[:8] + "..."
+	}
+	return "⚡ Zap from @" + sender
+}
+
+// zapReceiptText describes a zap receipt: amount, comment and validity
+func (m *Model) zapReceiptText(evt nostr.Event) string {
+	info := m.zapReceipts[evt.ID]
+	if info == nil {
+		return "Zap receipt"
+	}
+	if info.receipt == nil {
+		return fmt.Sprintf("⚠️ Invalid zap receipt: %v", info.err)
+	}
+
+	text := formatSats(info.receipt.AmountMsats)
+	switch {
+	case info.receipt.EventID != "":
+		text += " on your note"
+	case info.receipt.Address != "":
+		text += " on your " + addressKindName(info.receipt.Address)
+	default:
+		text += " to your profile"
+	}
+	if info.receipt.Comment != "" {
+		text += "\n" + info.receipt.Comment
+	}
+
+	switch {
+	case info.err != nil:
+		text += fmt.Sprintf("\n⚠️ Invalid receipt: %v", info.err)
+	case !info.checked:
+		text += "\n(verifying receipt...)"
+	}
+	return text
+}
+
+// zapTotalLine summarizes the valid zaps on a note, or returns "" if none
+func (m *Model) zapTotalLine(eventID string) string {
+	total, ok := m.zapTotals[eventID]
+	if !ok || total.count == 0 {
+		return ""
+	}
+	if total.count == 1 {
+		return "⚡ " + formatSats(total.msats) + " from 1 zap"
+	}
+	return fmt.Sprintf("⚡ %s from %d zaps", formatSats(total.msats), total.count)
+}
+
+// selectedIsZapReceipt reports whether the cursor is on a zap receipt
+func (m *Model) selectedIsZapReceipt() bool {
+	currentEvents := m.getCurrentEvents()
+	if m.cursor < len(currentEvents) {
+		return currentEvents[m.cursor].Kind == zap.KindZapReceipt
+	}
+	return false
+}
+
+// eventIDs returns the IDs of the given events
+func eventIDs(events []nostr.Event) []string {
+	ids := make([]string, 0, len(events))
+	for _, evt := range events {
+		ids = append(ids, evt.ID)
+	}
+	return ids
+}
+
+// addressKindName names the kind of an addressable event coordinate
+func addressKindName(address string) string {
+	switch strings.SplitN(address, ":", 2)[0] {
+	case "30023":
+		return "article"
+	case "30311":
+		return "live stream"
+	default:
+		return "event"
+	}
+}
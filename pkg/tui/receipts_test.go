@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"noscli/pkg/zap"
+)
+
+func TestZapTotalsCountOnlyValidatedReceipts(t *testing.T) {
+	h := newUIHarness(t, nil)
+	h.loggedIn()
+
+	recipient := goldenAlice.pk
+	id := strings.Repeat("1", 64)
+	h.m.zapReceipts[id] = &zapReceiptInfo{receipt: &zap.Receipt{EventID: "note", Recipient: recipient, AmountMsats: 21_000}}
+
+	// Until the recipient's LNURL key is known the receipt proves nothing
+	if cmd := h.m.addZapReceipts(nil); cmd == nil || !h.m.zapperKeysPending[recipient] {
+		t.Fatal("didn't look up the recipient's LNURL key")
+	}
+	if total := h.m.zapTotals["note"]; total.count != 0 {
+		t.Errorf("unchecked receipt counted: %+v", total)
+	}
+
+	// A failed lookup leaves it unchecked and lets the next batch retry
+	h.send(zapperKeysMsg{requested: []string{recipient}, keys: map[string]string{}})
+	if h.m.zapperKeysPending[recipient] || h.m.zapReceipts[id].checked {
+		t.Fatalf("pending = %v, checked = %v after a failed lookup", h.m.zapperKeysPending[recipient], h.m.zapReceipts[id].checked)
+	}
+	if cmd := h.m.addZapReceipts(nil); cmd == nil || !h.m.zapperKeysPending[recipient] {
+		t.Error("didn't retry the failed lookup")
+	}
+
+	// A server without a nostrPubkey can't sign valid receipts
+	h.send(zapperKeysMsg{requested: []string{recipient}, keys: map[string]string{recipient: ""}})
+	if info := h.m.zapReceipts[id]; !info.checked || info.err == nil {
+		t.Errorf("receipt = %+v, want checked and invalid", info)
+	}
+	if total := h.m.zapTotals["note"]; total.count != 0 {
+		t.Errorf("invalid receipt counted: %+v", total)
+	}
+}
+
+func TestFetchZapperKeysCmd(t *testing.T) {
+	serverKey := strings.Repeat("ab", 32)
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/alice") {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(zap.LNURLResponse{
+			Callback:    server.URL + "/callback",
+			Tag:         "payRequest",
+			AllowsNostr: true,
+			NostrPubkey: serverKey,
+		})
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	relay, pool := newTestRelay(t)
+	alice, bob, carol, dave := newTestUser(), newTestUser(), newTestUser(), newTestUser()
+	alice.publish(t, relay, 0, `{"lud16":"alice@`+host+`"}`, 100)
+	bob.publish(t, relay, 0, `{"lud16":"bob@`+host+`"}`, 100)
+	carol.publish(t, relay, 0, `{"name":"carol"}`, 100)
+
+	cmd := fetchZapperKeysCmd(pool, zap.NewClient(server.Client()), []string{relay.URL}, []string{alice.pk, bob.pk, carol.pk, dave.pk})
+	msg := cmd().(zapperKeysMsg)
+
+	if len(msg.requested) != 4 {
+		t.Errorf("requested = %v", msg.requested)
+	}
+	if msg.keys[alice.pk] != serverKey {
+		t.Errorf("alice's key = %q, want %q", msg.keys[alice.pk], serverKey)
+	}
+	if key, ok := msg.keys[carol.pk]; !ok || key != "" {
+		t.Errorf("carol has no lightning address, got %q, %v", key, ok)
+	}
+	// Bob's server is down and dave's profile is missing; both get retried
+	for name, pk := range map[string]string{"bob": bob.pk, "dave": dave.pk} {
+		if key, ok := msg.keys[pk]; ok {
+			t.Errorf("%s's failed lookup recorded as %q", name, key)
+		}
+	}
+}
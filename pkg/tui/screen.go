@@ -0,0 +1,66 @@
+package tui
+
+import "github.com/charmbracelet/bubbletea"
+
+// screen is a sub-model with its own input state. Model routes key presses
+// to the screen that owns the current state. Screens read and change shared
+// state, including which screen is shown, through the app context.
+type screen interface {
+	Update(app *appContext, msg tea.KeyMsg) tea.Cmd
+	View(app *appContext) string
+}
+
+// overlay is a screen shown over the timeline, which draws it: Status goes
+// in the status line, and View, unless it's empty, in place of the feed.
+type overlay interface {
+	screen
+	Status(app *appContext) string
+}
+
+// overlayKind is which overlay is open over the timeline
+type overlayKind int
+
+const (
+	overlayNone overlayKind = iota
+	overlayPalette
+	overlayZapPrompt
+	overlayZapDialog
+	overlayPayConfirm
+	overlaySendSats
+)
+
+// activeScreen returns the screen for the current state, or nil for the
+// states Model draws itself: the timeline, connecting, loading and errors
+func (m *Model) activeScreen() screen {
+	switch m.state {
+	case stateLanding:
+		return &m.landing
+	case stateSettings:
+		return &m.settings
+	case stateComposing:
+		return &m.composer
+	case stateThread:
+		return &m.thread
+	}
+	return nil
+}
+
+// activeOverlay returns the overlay open over the timeline, or nil
+func (m *Model) activeOverlay() overlay {
+	if m.state != stateTimeline {
+		return nil
+	}
+	switch m.overlay {
+	case overlayPalette:
+		return &m.palette
+	case overlayZapPrompt:
+		return &m.zapPrompt
+	case overlayZapDialog:
+		return &m.zapDialog
+	case overlayPayConfirm:
+		return &m.payConfirm
+	case overlaySendSats:
+		return &m.sendSats
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"noscli/pkg/config"
+)
+
+func TestThreadReplyOpensComposer(t *testing.T) {
+	h := newUIHarness(t, &config.Config{AuthMethod: "nsec"})
+	root := h.event(goldenAlice, 1, "what's everyone reading?", time.Hour)
+	h.loggedIn(root)
+	h.keys("t")
+
+	// The thread can't reach the composer, it asks the router with a composeMsg
+	_, cmd := h.m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	if cmd == nil {
+		t.Fatal("R returned no command")
+	}
+	h.send(cmd())
+
+	if h.m.state != stateComposing || h.m.composer.mode != composeReply {
+		t.Fatalf("state = %v mode = %v, want a reply being composed", h.m.state, h.m.composer.mode)
+	}
+	if h.m.composer.replyingTo == nil || h.m.composer.replyingTo.ID != root.ID {
+		t.Errorf("replying to %v, want the thread root", h.m.composer.replyingTo)
+	}
+
+	h.keys("esc")
+	if h.m.state != stateTimeline || h.m.composer.replyingTo != nil {
+		t.Errorf("esc left state %v replying to %v", h.m.state, h.m.composer.replyingTo)
+	}
+}
@@ -0,0 +1,602 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"noscli/pkg/config"
+)
+
+// settingsScreen has the Authentication, Relays and Wallet tabs
+type settingsScreen struct {
+	menu              int    // 0 = Auth, 1 = Relays, 2 = Wallet
+	cursor            int    // Which item is selected in settings
+	editingRelay      bool   // Whether we're editing a relay
+	newRelayInput     string // Input for new relay
+	editingNsec       bool   // Whether we're editing nsec input
+	nsecInput         string // Input for the nsec key
+	walletCursor      int    // Which wallet is selected in settings
+	editingNWC        bool   // Whether we're editing NWC input
+	nwcInput          string // Input for NWC connection string
+	editingWalletName bool   // Whether we're naming a new wallet
+	walletNameInput   string // Input for new wallet name
+	editingLimits     bool   // Whether we're editing wallet spending limits
+	limitsInput       string // Input for limits: "per-zap daily monthly"
+}
+
+// Update edits the settings, saving them as they change
+func (s *settingsScreen) Update(app *appContext, msg tea.KeyMsg) tea.Cmd {
+	if s.editingNsec {
+		switch msg.String() {
+		case "esc":
+			s.editingNsec = false
+			s.nsecInput = ""
+		case "enter":
+			// Debug: Show raw input before any processing
+			debugInfo := fmt.Sprintf("Raw input (len=%d): ", len(s.nsecInput))
+			for i, r := range s.nsecInput {
+				if i < 30 {
+					debugInfo += fmt.Sprintf("%c(%d) ", r, r)
+				}
+			}
+			app.statusMsg = debugInfo
+
+			if strings.TrimSpace(s.nsecInput) != "" {
+				// Find and extract nsec from the input (handles bracket paste and other junk)
+				input := strings.TrimSpace(s.nsecInput)
+
+				// Look for "nsec1" anywhere in the input
+				nsecIndex := strings.Index(input, "nsec1")
+				if nsecIndex >= 0 {
+					// Extract from "nsec1" to the end, removing any trailing junk
+					cleanKey := input[nsecIndex:]
+					// Remove any non-alphanumeric characters from the end
+					cleanKey = strings.TrimRightFunc(cleanKey, func(r rune) bool {
+						return r < '0' || r > 'z' || (r > '9' && r < 'A') || (r > 'Z' && r < 'a')
+					})
+
+					// Validate length (nsec should be 63 characters)
+					if len(cleanKey) >= 60 && len(cleanKey) <= 66 {
+						s.editingNsec = false
+						app.statusMsg = "Decoding nsec key..."
+						s.nsecInput = "" // Clear for next time
+						// Decode and save the nsec key
+						return connectWithNsecCmd(cleanKey)
+					} else {
+						app.statusMsg = fmt.Sprintf("❌ Invalid nsec length: %d chars (expected ~63)", len(cleanKey))
+						s.nsecInput = ""
+						s.editingNsec = false
+					}
+				} else {
+					app.statusMsg = fmt.Sprintf("❌ No 'nsec1' found in: '%s'", input[:min(50, len(input))])
+					s.nsecInput = ""
+					s.editingNsec = false
+				}
+			} else {
+				app.statusMsg = "❌ Please enter an nsec key (field is empty)"
+				s.editingNsec = false
+			}
+		case "backspace":
+			if len(s.nsecInput) > 0 {
+				s.nsecInput = s.nsecInput[:len(s.nsecInput)-1]
+			}
+		default:
+			// Handle paste events and single character input
+			input := msg.String()
+			if len(input) > 0 {
+				// Accept both single chars and multi-char paste
+				s.nsecInput += input
+			}
+		}
+		return nil
+	}
+
+	if s.editingWalletName {
+		switch msg.String() {
+		case "esc":
+			s.editingWalletName = false
+			s.walletNameInput = ""
+		case "enter":
+			name := strings.TrimSpace(s.walletNameInput)
+			if name == "" {
+				app.statusMsg = "❌ Please enter a wallet name"
+				return nil
+			}
+			if app.walletIndex(name) >= 0 {
+				app.statusMsg = fmt.Sprintf("❌ A wallet named '%s' already exists", name)
+				return nil
+			}
+			// Name chosen, now ask for the connection string
+			s.walletNameInput = name
+			s.editingWalletName = false
+			s.editingNWC = true
+			s.nwcInput = ""
+		case "backspace":
+			if len(s.walletNameInput) > 0 {
+				s.walletNameInput = s.walletNameInput[:len(s.walletNameInput)-1]
+			}
+		default:
+			s.walletNameInput += msg.String()
+		}
+		return nil
+	}
+
+	if s.editingNWC {
+		switch msg.String() {
+		case "esc":
+			s.editingNWC = false
+			s.nwcInput = ""
+			s.walletNameInput = ""
+		case "enter":
+			if strings.TrimSpace(s.nwcInput) != "" {
+				// Find and extract nostr+walletconnect:// URL
+				input := strings.TrimSpace(s.nwcInput)
+
+				// Look for "nostr+walletconnect://" anywhere in the input
+				nwcIndex := strings.Index(input, "nostr+walletconnect://")
+				if nwcIndex >= 0 {
+					// Extract from "nostr+walletconnect://" to the end
+					cleanNWC := input[nwcIndex:]
+					// Remove any trailing junk
+					cleanNWC = strings.TrimSpace(cleanNWC)
+
+					if s.walletNameInput != "" {
+						// Adding a new wallet
+						app.wallets = append(app.wallets, config.Wallet{Name: s.walletNameInput, NWC: cleanNWC})
+						s.walletCursor = len(app.wallets) - 1
+						if app.defaultWallet == "" || len(app.wallets) == 1 {
+							app.defaultWallet = s.walletNameInput
+						}
+						app.statusMsg = fmt.Sprintf("✓ Wallet '%s' added", s.walletNameInput)
+					} else if s.walletCursor < len(app.wallets) {
+						app.wallets[s.walletCursor].NWC = cleanNWC
+						app.statusMsg = fmt.Sprintf("✓ Wallet '%s' updated", app.wallets[s.walletCursor].Name)
+					}
+
+					s.nwcInput = ""
+					s.walletNameInput = ""
+					s.editingNWC = false
+					app.saveConfig()
+
+					// Restart subscription if the default wallet changed
+					app.applyDefaultWallet()
+				} else {
+					app.statusMsg = "❌ Invalid NWC format - must start with nostr+walletconnect://"
+					s.nwcInput = ""
+					s.walletNameInput = ""
+					s.editingNWC = false
+				}
+			} else {
+				app.statusMsg = "❌ Please enter a NWC connection string"
+				s.walletNameInput = ""
+				s.editingNWC = false
+			}
+		case "backspace":
+			if len(s.nwcInput) > 0 {
+				s.nwcInput = s.nwcInput[:len(s.nwcInput)-1]
+			}
+		default:
+			// Handle paste events and single character input
+			input := msg.String()
+			if len(input) > 0 {
+				s.nwcInput += input
+			}
+		}
+		return nil
+	}
+
+	if s.editingLimits {
+		switch msg.String() {
+		case "esc":
+			s.editingLimits = false
+			s.limitsInput = ""
+		case "enter":
+			limits, err := parseWalletLimits(s.limitsInput)
+			if err != nil {
+				app.statusMsg = "❌ " + err.Error()
+				return nil
+			}
+			if s.walletCursor < len(app.wallets) {
+				w := &app.wallets[s.walletCursor]
+				w.MaxPerZap, w.DailyBudget, w.MonthlyBudget = limits[0], limits[1], limits[2]
+				app.saveConfig()
+				app.statusMsg = fmt.Sprintf("✓ Limits saved for '%s'", w.Name)
+			}
+			s.editingLimits = false
+			s.limitsInput = ""
+		case "backspace":
+			if len(s.limitsInput) > 0 {
+				s.limitsInput = s.limitsInput[:len(s.limitsInput)-1]
+			}
+		default:
+			input := msg.String()
+			if input == " " || (len(input) == 1 && input[0] >= '0' && input[0] <= '9') {
+				s.limitsInput += input
+			}
+		}
+		return nil
+	}
+
+	if s.editingRelay {
+		switch msg.String() {
+		case "esc":
+			s.editingRelay = false
+			s.newRelayInput = ""
+		case "enter":
+			if strings.TrimSpace(s.newRelayInput) != "" {
+				// Add new relay
+				app.relays = append(app.relays, strings.TrimSpace(s.newRelayInput))
+				s.newRelayInput = ""
+				s.editingRelay = false
+				app.saveConfig()
+			}
+		case "backspace":
+			if len(s.newRelayInput) > 0 {
+				s.newRelayInput = s.newRelayInput[:len(s.newRelayInput)-1]
+			}
+		default:
+			// Handle paste events and single character input
+			input := msg.String()
+			if len(input) > 0 {
+				// Accept both single chars and multi-char paste
+				s.newRelayInput += input
+			}
+		}
+		return nil
+	}
+
+	// Settings menu navigation
+	switch msg.String() {
+	case "q", "esc":
+		// Back to landing
+		app.state = stateLanding
+	case "tab":
+		// Cycle through auth, relays, and wallet menus
+		s.menu = (s.menu + 1) % 3
+		s.cursor = 0
+	case "up", "k":
+		if s.menu == 2 {
+			if s.walletCursor > 0 {
+				s.walletCursor--
+			}
+		} else if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.menu == 1 && s.cursor < len(app.relays)-1 {
+			s.cursor++
+		} else if s.menu == 0 && s.cursor < 1 {
+			s.cursor++
+		} else if s.menu == 2 && s.walletCursor < len(app.wallets)-1 {
+			s.walletCursor++
+		}
+	case "enter":
+		if s.menu == 0 {
+			// Auth method selection
+			if s.cursor == 0 {
+				app.authMethod = "pleb_signer"
+				app.saveConfig()
+			} else {
+				s.editingNsec = true
+				s.nsecInput = ""
+			}
+		} else if s.menu == 1 && app.authMethod != "" {
+			// Start client if auth method is set
+			return app.connect()
+		}
+	case "a":
+		// Add new relay (only in relays menu)
+		if s.menu == 1 {
+			s.editingRelay = true
+			s.newRelayInput = ""
+		}
+		// Add new wallet (only in wallet menu)
+		if s.menu == 2 {
+			s.editingWalletName = true
+			s.walletNameInput = ""
+		}
+	case "d", "x":
+		// Delete selected relay (only in relays menu)
+		if s.menu == 1 && s.cursor < len(app.relays) && len(app.relays) > 1 {
+			app.relays = append(app.relays[:s.cursor], app.relays[s.cursor+1:]...)
+			if s.cursor >= len(app.relays) {
+				s.cursor = len(app.relays) - 1
+			}
+			app.saveConfig()
+		}
+		// Delete selected wallet (only in wallet menu)
+		if s.menu == 2 && s.walletCursor < len(app.wallets) {
+			name := app.wallets[s.walletCursor].Name
+			app.wallets = append(app.wallets[:s.walletCursor], app.wallets[s.walletCursor+1:]...)
+			if s.walletCursor >= len(app.wallets) && s.walletCursor > 0 {
+				s.walletCursor = len(app.wallets) - 1
+			}
+			if app.defaultWallet == name {
+				app.defaultWallet = ""
+				if len(app.wallets) > 0 {
+					app.defaultWallet = app.wallets[0].Name
+				}
+			}
+			app.saveConfig()
+
+			// Stops the subscription if no wallet is left
+			app.applyDefaultWallet()
+			app.statusMsg = fmt.Sprintf("Wallet '%s' removed", name)
+		}
+	case "e":
+		// Edit NWC connection (only in wallet menu)
+		if s.menu == 2 {
+			if len(app.wallets) == 0 {
+				// Nothing to edit yet, add the first wallet instead
+				s.editingWalletName = true
+				s.walletNameInput = ""
+			} else {
+				s.editingNWC = true
+				s.nwcInput = ""
+			}
+		}
+	case "s":
+		// Make selected wallet the default (only in wallet menu)
+		if s.menu == 2 && s.walletCursor < len(app.wallets) {
+			app.defaultWallet = app.wallets[s.walletCursor].Name
+			app.saveConfig()
+			app.applyDefaultWallet()
+			app.statusMsg = fmt.Sprintf("✓ '%s' is now the default wallet", app.defaultWallet)
+		}
+	case "l":
+		// Edit spending limits of selected wallet (only in wallet menu)
+		if s.menu == 2 && s.walletCursor < len(app.wallets) {
+			w := app.wallets[s.walletCursor]
+			s.editingLimits = true
+			s.limitsInput = fmt.Sprintf("%d %d %d", w.MaxPerZap, w.DailyBudget, w.MonthlyBudget)
+		}
+	case "n":
+		// Toggle wallet notifications in the Notifications view (only in wallet menu)
+		if s.menu == 2 {
+			app.walletNotifsInFeed = !app.walletNotifsInFeed
+			app.saveConfig()
+			if app.walletNotifsInFeed {
+				app.statusMsg = "Wallet payments will appear in Notifications"
+			} else {
+				app.statusMsg = "Wallet payments hidden from Notifications"
+			}
+		}
+	}
+	return nil
+}
+
+// View draws the active settings tab
+func (s *settingsScreen) View(app *appContext) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("205")).
+		Padding(1, 0)
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("214")).
+		Bold(true).
+		Padding(1, 0)
+
+	itemStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252")).
+		Padding(0, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("205")).
+		Bold(true).
+		Background(lipgloss.Color("236")).
+		Padding(0, 2)
+
+	activeStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("46")).
+		Bold(true).
+		Padding(0, 2)
+
+	tabStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Padding(0, 2)
+
+	activeTabStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("205")).
+		Bold(true).
+		Padding(0, 2)
+
+	footerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Padding(1, 0)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("⚙️  SETTINGS"))
+	content.WriteString("\n\n")
+
+	// Tab indicators
+	if s.menu == 0 {
+		content.WriteString(activeTabStyle.Render("[ Authentication ]"))
+		content.WriteString(tabStyle.Render("  Relays  "))
+		content.WriteString(tabStyle.Render("  Wallet  "))
+	} else if s.menu == 1 {
+		content.WriteString(tabStyle.Render("  Authentication  "))
+		content.WriteString(activeTabStyle.Render("[ Relays ]"))
+		content.WriteString(tabStyle.Render("  Wallet  "))
+	} else {
+		content.WriteString(tabStyle.Render("  Authentication  "))
+		content.WriteString(tabStyle.Render("  Relays  "))
+		content.WriteString(activeTabStyle.Render("[ Wallet ]"))
+	}
+	content.WriteString(footerStyle.Render("  (Tab to switch)"))
+	content.WriteString("\n\n")
+
+	if s.menu == 0 {
+		// Authentication menu
+		content.WriteString(headerStyle.Render("Choose Authentication Method:"))
+		content.WriteString("\n\n")
+
+		// Pleb Signer option
+		authIndicator := ""
+		if app.authMethod == "pleb_signer" {
+			authIndicator = " ✓"
+		}
+		if s.cursor == 0 {
+			content.WriteString(selectedStyle.Render(fmt.Sprintf("► Pleb Signer (DBus)%s", authIndicator)))
+		} else {
+			if app.authMethod == "pleb_signer" {
+				content.WriteString(activeStyle.Render(fmt.Sprintf("  Pleb Signer (DBus)%s", authIndicator)))
+			} else {
+				content.WriteString(itemStyle.Render("  Pleb Signer (DBus)"))
+			}
+		}
+		content.WriteString("\n")
+
+		// Nsec option
+		nsecIndicator := ""
+		if app.authMethod == "nsec" {
+			nsecIndicator = " ✓"
+		}
+		if s.cursor == 1 {
+			content.WriteString(selectedStyle.Render(fmt.Sprintf("► Private Key (nsec)%s", nsecIndicator)))
+		} else {
+			if app.authMethod == "nsec" {
+				content.WriteString(activeStyle.Render(fmt.Sprintf("  Private Key (nsec)%s", nsecIndicator)))
+			} else {
+				content.WriteString(itemStyle.Render("  Private Key (nsec)"))
+			}
+		}
+		content.WriteString("\n")
+
+		if s.editingNsec {
+			content.WriteString("\n")
+			content.WriteString(headerStyle.Render("Enter your nsec key (paste supported):"))
+			content.WriteString("\n")
+			maskedKey := strings.Repeat("*", len(s.nsecInput))
+			content.WriteString(itemStyle.Render(fmt.Sprintf("> %s_", maskedKey)))
+			content.WriteString("\n")
+			content.WriteString(footerStyle.Render("Enter to save • Esc to cancel • Ctrl+Shift+V to paste"))
+		} else {
+			content.WriteString("\n\n")
+
+			// Show status messages
+			if app.statusMsg != "" {
+				statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Padding(0, 2)
+				content.WriteString(statusStyle.Render(app.statusMsg))
+				content.WriteString("\n")
+			}
+
+			if app.authMethod == "" {
+				content.WriteString(footerStyle.Render("⚠️  Please select an authentication method before starting client"))
+				content.WriteString("\n")
+			}
+			if app.authMethod != "" {
+				content.WriteString(footerStyle.Render("✓ Auth set! Press Tab to view/edit Relays"))
+				content.WriteString("\n")
+			}
+			content.WriteString(footerStyle.Render("↑/↓ nav • Enter select • Tab → Relays • Esc back"))
+		}
+
+	} else if s.menu == 1 {
+		// Relays menu
+		content.WriteString(headerStyle.Render("Nostr Relays:"))
+		content.WriteString("\n\n")
+
+		for i, relay := range app.relays {
+			if i == s.cursor {
+				content.WriteString(selectedStyle.Render(fmt.Sprintf("► %s", relay)))
+			} else {
+				content.WriteString(itemStyle.Render(fmt.Sprintf("  %s", relay)))
+			}
+			content.WriteString("\n")
+		}
+
+		if s.editingRelay {
+			content.WriteString("\n")
+			content.WriteString(headerStyle.Render("Add New Relay:"))
+			content.WriteString("\n")
+			content.WriteString(itemStyle.Render(fmt.Sprintf("> %s_", s.newRelayInput)))
+			content.WriteString("\n")
+			content.WriteString(footerStyle.Render("Enter to add • Esc to cancel"))
+		} else {
+			content.WriteString("\n")
+			if app.authMethod == "" {
+				content.WriteString(footerStyle.Render("⚠️  Set authentication method first (Tab to switch)"))
+				content.WriteString("\n")
+				content.WriteString(footerStyle.Render("↑/↓ navigate • a add relay • d/x delete • Esc/q back"))
+			} else {
+				content.WriteString(footerStyle.Render("↑/↓ navigate • a add relay • d/x delete • Enter start client • Esc/q back"))
+			}
+		}
+
+	} else {
+		// Wallet menu
+		content.WriteString(headerStyle.Render("Nostr Wallet Connect (NWC):"))
+		content.WriteString("\n\n")
+
+		if s.editingWalletName {
+			content.WriteString(headerStyle.Render("Name for the new wallet:"))
+			content.WriteString("\n")
+			content.WriteString(itemStyle.Render(fmt.Sprintf("> %s_", s.walletNameInput)))
+			content.WriteString("\n")
+			content.WriteString(footerStyle.Render("Enter to continue • Esc to cancel"))
+		} else if s.editingNWC {
+			content.WriteString(headerStyle.Render("Enter NWC connection string:"))
+			content.WriteString("\n")
+			// Show masked connection string
+			maskedNWC := strings.Repeat("*", len(s.nwcInput))
+			content.WriteString(itemStyle.Render(fmt.Sprintf("> %s_", maskedNWC)))
+			content.WriteString("\n")
+			content.WriteString(footerStyle.Render("Enter to save • Esc to cancel • Paste nostr+walletconnect://..."))
+		} else if s.editingLimits {
+			content.WriteString(headerStyle.Render("Spending limits in sats: per-zap daily monthly (0 = no limit):"))
+			content.WriteString("\n")
+			content.WriteString(itemStyle.Render(fmt.Sprintf("> %s_", s.limitsInput)))
+			content.WriteString("\n")
+			content.WriteString(footerStyle.Render("Enter to save • Esc to cancel"))
+		} else {
+			if len(app.wallets) > 0 {
+				for i, w := range app.wallets {
+					marker := "  "
+					if w.Name == app.defaultWallet {
+						marker = "★ "
+					}
+					line := marker + w.Name + "  " + app.walletLimitsSummary(w)
+					if i == s.walletCursor {
+						content.WriteString(selectedStyle.Render("► " + line))
+					} else if w.Name == app.defaultWallet {
+						content.WriteString(activeStyle.Render("  " + line))
+					} else {
+						content.WriteString(itemStyle.Render("  " + line))
+					}
+					content.WriteString("\n")
+				}
+				content.WriteString("\n")
+				if app.statusMsg != "" {
+					statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Padding(0, 2)
+					content.WriteString(statusStyle.Render(app.statusMsg))
+					content.WriteString("\n\n")
+				}
+				content.WriteString(app.renderWalletHistory(10))
+				content.WriteString("\n")
+				feedSetting := "off"
+				if app.walletNotifsInFeed {
+					feedSetting = "on"
+				}
+				content.WriteString(itemStyle.Render(fmt.Sprintf("Show payments in Notifications: %s (press 'n' to toggle)", feedSetting)))
+				content.WriteString("\n\n")
+				content.WriteString(footerStyle.Render("↑/↓ navigate • a add • e edit • s set default • l limits • d delete"))
+			} else {
+				content.WriteString(itemStyle.Render("Press 'a' to add a NWC connection string"))
+				content.WriteString("\n\n")
+				content.WriteString(footerStyle.Render("💡 Get your NWC string from Alby, Mutiny, or your wallet"))
+			}
+			content.WriteString("\n\n")
+			content.WriteString(footerStyle.Render("Tab to switch • Esc/q back"))
+
+			// Add config file location hint at the bottom
+			configPath, _ := config.GetConfigPath()
+			content.WriteString("\n")
+			content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(fmt.Sprintf("Config: %s", configPath)))
+		}
+	}
+
+	return content.String()
+}
@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// threadScreen shows a note and its replies. Model renders them through
+// render, since rendering notes needs the feed's caches.
+type threadScreen struct {
+	root     *nostr.Event  // Root event of thread being viewed
+	events   []nostr.Event // All events in thread
+	viewport viewport.Model
+}
+
+// open shows the thread of root, with replies to follow in a threadEventsMsg
+func (t *threadScreen) open(app *appContext, root *nostr.Event) {
+	app.state = stateThread
+	t.root = root
+	t.events = nil
+	t.viewport.GotoTop()
+}
+
+// render lays out the root and its replies in the viewport, drawing each
+// note with renderEvent
+func (t *threadScreen) render(renderEvent func(evt nostr.Event) string) {
+	heading := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214"))
+	var content strings.Builder
+	content.WriteString(heading.Render("━━━ Thread Root ━━━"))
+	content.WriteString("\n\n")
+	content.WriteString(renderEvent(*t.root))
+	content.WriteString("\n\n")
+
+	if len(t.events) > 0 {
+		content.WriteString(heading.Render(fmt.Sprintf("━━━ Replies (%d) ━━━", len(t.events))))
+		content.WriteString("\n\n")
+		for _, evt := range t.events {
+			content.WriteString(renderEvent(evt))
+			content.WriteString("\n")
+		}
+	} else {
+		content.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241")).
+			Render("No replies yet"))
+		content.WriteString("\n")
+	}
+
+	t.viewport.SetContent(content.String())
+}
+
+// Update scrolls the thread, replies to its root or goes back to the timeline
+func (t *threadScreen) Update(app *appContext, msg tea.KeyMsg) tea.Cmd {
+	switch app.keys.action(keysThread, msg.String()) {
+	case actBack:
+		// Exit thread view
+		app.state = stateTimeline
+		t.root = nil
+		t.events = nil
+		app.statusMsg = "Back to timeline"
+	case actReply:
+		// Reply to thread root
+		root := t.root
+		return func() tea.Msg { return composeMsg{mode: composeReply, replyTo: root} }
+	case actUp:
+		t.viewport.LineUp(1)
+	case actDown:
+		t.viewport.LineDown(1)
+	case actPageUp:
+		t.viewport.ViewUp()
+	case actPageDown:
+		t.viewport.ViewDown()
+	case actTop:
+		t.viewport.GotoTop()
+	case actBottom:
+		t.viewport.GotoBottom()
+	}
+	return nil
+}
+
+// View draws the status header, the thread and the key hints
+func (t *threadScreen) View(app *appContext) string {
+	header := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("205")).
+		Render(fmt.Sprintf("Noscli - %s", app.statusMsg))
+
+	footer := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Render(joinHints(app.keys.hint("reply", actReply), app.keys.hint("scroll", actUp, actDown), app.keys.hint("back", actBack), app.keys.hint("help", actHelp)))
+
+	return fmt.Sprintf("%s\n%s\n\n%s", header, t.viewport.View(), footer)
+}
@@ -0,0 +1,401 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// renderKey identifies one rendering of an event. Everything else a
+// rendering depends on (names, read marks, zap totals, decrypted DMs) comes
+// from the model's caches, and updateContent drops all renderings when those
+// change.
+type renderKey struct {
+	id       string
+	width    int
+	selected bool
+	compact  bool
+}
+
+// renderedEvent is an event as drawn in the timeline
+type renderedEvent struct {
+	text   string
+	height int // Lines the event takes up
+}
+
+// renderCached renders evt, reusing the rendering from an earlier frame if
+// nothing changed since
+func (m *Model) renderCached(evt nostr.Event, selected bool) renderedEvent {
+	key := renderKey{id: evt.ID, width: m.viewport.Width, selected: selected, compact: m.compact}
+	if r, ok := m.rendered[key]; ok {
+		return r
+	}
+	var text string
+	if m.compact {
+		text = m.renderCompact(evt, selected)
+	} else {
+		text = m.renderEvent(evt, selected, m.viewport.Width)
+	}
+	r := renderedEvent{text: text, height: lipgloss.Height(text)}
+	m.rendered[key] = r
+	return r
+}
+
+// eventHeight returns how many lines event i of the current view takes up
+func (m *Model) eventHeight(events []nostr.Event, i int) int {
+	return m.renderCached(events[i], i == m.cursor).height
+}
+
+// layoutTimeline fills the viewport with the current view's events from
+// m.top on, hiding the first m.topLine lines of the top one. Only events that
+// end up on screen are rendered, so long timelines cost no more than short
+// ones.
+func (m *Model) layoutTimeline() {
+	events := m.getCurrentEvents()
+	if len(events) == 0 {
+		m.top, m.topLine = 0, 0
+		empty := "No posts yet."
+		if keys := m.keys.keys(actRefresh); len(keys) > 0 {
+			empty += " Press '" + keyList(keys) + "' to refresh."
+		}
+		m.viewport.SetContent(empty + "\n")
+		m.viewport.GotoTop()
+		return
+	}
+	// The events may have shrunk, as when wallet payments are hidden
+	m.cursor = min(m.cursor, len(events)-1)
+	if m.top >= len(events) {
+		m.top, m.topLine = len(events)-1, 0
+	}
+
+	var content strings.Builder
+	lines := 0
+	for i := m.top; i < len(events) && lines < m.topLine+m.viewport.Height; i++ {
+		r := m.renderCached(events[i], i == m.cursor)
+		content.WriteString(r.text)
+		content.WriteString("\n")
+		lines += r.height
+	}
+	m.viewport.SetContent(content.String())
+	m.viewport.SetYOffset(m.topLine)
+}
+
+// scrollToCursor scrolls as little as possible to show all of the selected
+// event, or its top if it's taller than the viewport, and lays out the
+// timeline
+func (m *Model) scrollToCursor() {
+	events := m.getCurrentEvents()
+	if m.cursor >= len(events) {
+		m.layoutTimeline()
+		return
+	}
+
+	if m.cursor < m.top || (m.cursor == m.top && m.topLine > 0) {
+		// Above the viewport: bring its top to the top
+		m.top, m.topLine = m.cursor, 0
+	} else {
+		// Find where the selected event ends, giving up as soon as it's
+		// clearly below the viewport
+		bottom := -m.topLine
+		for i := m.top; i <= m.cursor && bottom <= m.viewport.Height; i++ {
+			bottom += m.eventHeight(events, i)
+		}
+		if bottom > m.viewport.Height {
+			m.alignCursorToBottom(events)
+		}
+	}
+	m.layoutTimeline()
+}
+
+// alignCursorToBottom scrolls so the selected event ends at the bottom of
+// the viewport, walking back from it only as far as the viewport reaches
+func (m *Model) alignCursorToBottom(events []nostr.Event) {
+	remaining := m.viewport.Height - m.eventHeight(events, m.cursor)
+	if remaining <= 0 {
+		m.top, m.topLine = m.cursor, 0
+		return
+	}
+	for i := m.cursor - 1; i >= 0; i-- {
+		height := m.eventHeight(events, i)
+		if height >= remaining {
+			m.top, m.topLine = i, height-remaining
+			return
+		}
+		remaining -= height
+	}
+	m.top, m.topLine = 0, 0
+}
+
+// pageStep returns how many events from the cursor fit in one viewport
+// going in direction dir (1 or -1), at least one
+func (m *Model) pageStep(dir int) int {
+	events := m.getCurrentEvents()
+	step, lines := 0, 0
+	for i := m.cursor + dir; i >= 0 && i < len(events); i += dir {
+		lines += m.eventHeight(events, i)
+		if lines > m.viewport.Height {
+			break
+		}
+		step++
+	}
+	return max(step, 1)
+}
+
+// renderTimeline draws the header with status, the tabs, body and the key
+// hints. An empty body shows the feed.
+func (m *Model) renderTimeline(status, body string) string {
+	if !m.ready {
+		return "Initializing..."
+	}
+
+	header := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("205")).
+		Render(fmt.Sprintf("Noscli - %s", status))
+
+	if body == "" {
+		body = m.viewport.View()
+		if m.dashboard() {
+			body = m.renderColumns()
+		}
+	}
+
+	return fmt.Sprintf("%s\n%s\n\n%s\n\n%s", header, m.renderTabs(), body, m.renderFooter())
+}
+
+// updateTimeline moves around the feed and acts on the selected note,
+// when no prompt or dialog is open over it
+func (m *Model) updateTimeline(msg tea.KeyMsg) tea.Cmd {
+	act := m.keys.action(keysTimeline, msg.String())
+
+	// Wallet notifications and zap receipts aren't notes, so note actions don't apply to them
+	switch act {
+	case actZap, actReply, actRepost, actQuote, actThread:
+		if m.selectedIsWalletNotification() {
+			m.statusMsg = "Not available for wallet payments"
+			return nil
+		}
+		if m.selectedIsZapReceipt() {
+			m.statusMsg = "Not available for zap receipts"
+			return nil
+		}
+	}
+
+	// Normal mode key handling
+	switch act {
+	case actQuit:
+		return tea.Quit
+	case actHelp:
+		m.openHelp()
+		return nil
+	case actPalette:
+		m.palette.open(m.appContext)
+		return nil
+	case actCompose:
+		// Start composing new post
+		m.composer.open(m.appContext, composePost, nil)
+		return nil
+	case actZap:
+		// Zap selected post
+		if m.nwcString == "" {
+			m.statusMsg = "⚠️ No wallet connected. Add NWC in Settings → Wallet"
+			return nil
+		}
+		currentEvents := m.getCurrentEvents()
+		if len(currentEvents) > 0 && m.cursor < len(currentEvents) {
+			m.statusMsg = "Enter zap amount (sats): "
+			return m.zapPrompt.open(m.appContext, subjectForEvent(currentEvents[m.cursor]))
+		}
+		return nil
+	case actPayInvoice:
+		// Pay a lightning invoice (or address) found in the selected post
+		if m.nwcString == "" {
+			m.statusMsg = "⚠️ No wallet connected. Add NWC in Settings → Wallet"
+			return nil
+		}
+		currentEvents := m.getCurrentEvents()
+		if len(currentEvents) == 0 || m.cursor >= len(currentEvents) {
+			return nil
+		}
+		evt := currentEvents[m.cursor]
+		content := evt.Content
+		if isDM(evt) {
+			content, _ = m.decryptedText(evt)
+		}
+		if invoices := extractInvoices(content); len(invoices) > 0 {
+			decoded, err := checkPayableInvoice(invoices[0])
+			if err != nil {
+				m.statusMsg = fmt.Sprintf("⚠️ Can't pay invoice: %v", err)
+				return nil
+			}
+			m.payConfirm.open(m.appContext, invoices[0], decoded, "")
+			return nil
+		}
+		if addresses := extractLightningAddresses(content); len(addresses) > 0 {
+			m.sendSats.openAmount(m.appContext, addresses[0])
+			return nil
+		}
+		m.statusMsg = "No lightning invoice in this post"
+		return nil
+	case actZapUser:
+		// Zap a profile, addressable event or lightning address without a note
+		if m.nwcString == "" {
+			m.statusMsg = "⚠️ No wallet connected. Add NWC in Settings → Wallet"
+			return nil
+		}
+		m.zapPrompt.openProfile(m.appContext, m.zapProfileDefault())
+		return nil
+	case actSendSats:
+		// Send sats to a lightning address
+		if m.nwcString == "" {
+			m.statusMsg = "⚠️ No wallet connected. Add NWC in Settings → Wallet"
+			return nil
+		}
+		m.sendSats.open(m.appContext)
+		return nil
+	case actReply:
+		// Reply to selected post or DM
+		currentEvents := m.getCurrentEvents()
+		if len(currentEvents) > 0 && m.cursor < len(currentEvents) {
+			evt := currentEvents[m.cursor]
+			if m.currentView == viewDMs {
+				m.composer.open(m.appContext, composeDM, &evt)
+			} else {
+				m.composer.open(m.appContext, composeReply, &evt)
+			}
+			return nil
+		}
+	case actNextColumn, actPrevColumn:
+		// Focus the next or previous column
+		next := (m.focus + 1) % len(m.columns)
+		if act == actPrevColumn {
+			next = (m.focus + len(m.columns) - 1) % len(m.columns)
+		}
+		cmd := m.focusColumn(next)
+		m.updateContent()
+		return cmd
+	case actMoveLeft, actMoveRight:
+		// Move the focused column, and save the layout
+		if act == actMoveLeft {
+			m.moveColumn(-1)
+		} else {
+			m.moveColumn(1)
+		}
+		m.updateContent()
+	case actRefresh:
+		// Refresh (unless in DMs or Notifications where 'r' might be confused)
+		if m.currentView == viewFollowing {
+			m.statusMsg = "Refreshing..."
+			return fetchEventsCmd(m.client(), m.following)
+		}
+		if m.currentView == viewFeed {
+			m.statusMsg = "Refreshing..."
+			return fetchFeedCmd(m.client(), &m.columns[m.focus])
+		}
+		// In DMs, retry decrypting the selected DM if it failed
+		if m.currentView == viewDMs && m.cursor < len(m.dms) {
+			cmd := m.retryDecrypt(m.dms[m.cursor])
+			m.updateContent()
+			return cmd
+		}
+	case actRepost:
+		// Simple repost (kind 6)
+		currentEvents := m.getCurrentEvents()
+		if len(currentEvents) > 0 && m.cursor < len(currentEvents) {
+			evt := currentEvents[m.cursor]
+			if m.currentView != viewDMs { // Can't repost DMs
+				m.statusMsg = "Reposting..."
+				return repostCmd(m.client(), &evt)
+			}
+		}
+	case actQuote:
+		// Quote repost (kind 1 with quote)
+		currentEvents := m.getCurrentEvents()
+		if len(currentEvents) > 0 && m.cursor < len(currentEvents) {
+			evt := currentEvents[m.cursor]
+			if m.currentView != viewDMs { // Can't quote DMs
+				m.composer.open(m.appContext, composeQuote, &evt)
+				return nil
+			}
+		}
+	case actCompact:
+		// Switch between note cards and one line per note
+		m.compact = !m.compact
+		m.scrollToCursor()
+	case actTop:
+		// Go to top
+		m.cursor = 0
+		m.scrollToCursor()
+	case actBottom:
+		// Go to bottom
+		currentEvents := m.getCurrentEvents()
+		if len(currentEvents) > 0 {
+			m.cursor = len(currentEvents) - 1
+		}
+		m.scrollToCursor()
+	case actThread:
+		// View thread
+		currentEvents := m.getCurrentEvents()
+		if len(currentEvents) > 0 && m.cursor < len(currentEvents) {
+			evt := currentEvents[m.cursor]
+			if m.currentView != viewDMs { // Don't show threads for DMs
+				m.thread.open(m.appContext, &evt)
+				m.statusMsg = "Loading thread..."
+				m.updateContent()
+				return fetchThreadCmd(m.client(), &evt)
+			}
+		}
+	case actUp:
+		if m.cursor > 0 {
+			m.cursor--
+			m.scrollToCursor()
+		}
+	case actDown:
+		currentEvents := m.getCurrentEvents()
+		if m.cursor < len(currentEvents)-1 {
+			m.cursor++
+			m.scrollToCursor()
+		}
+	case actPageUp:
+		// Move cursor up by viewport height worth of events
+		m.cursor -= m.pageStep(-1)
+		if m.cursor < 0 {
+			m.cursor = 0
+		}
+		m.scrollToCursor()
+	case actPageDown:
+		// Move cursor down by viewport height worth of events
+		currentEvents := m.getCurrentEvents()
+		m.cursor += m.pageStep(1)
+		if m.cursor >= len(currentEvents) {
+			m.cursor = len(currentEvents) - 1
+		}
+		m.scrollToCursor()
+	case actOpen:
+		currentEvents := m.getCurrentEvents()
+		if len(currentEvents) > 0 && m.cursor < len(currentEvents) {
+			evt := currentEvents[m.cursor]
+			url := extractFirstURL(evt.Content)
+			if url != "" {
+				go OpenMedia(url)
+				m.statusMsg = "Opening: " + url
+			}
+		}
+	case actOpenLink:
+		// Open specific URL by number
+		currentEvents := m.getCurrentEvents()
+		if len(currentEvents) > 0 && m.cursor < len(currentEvents) {
+			evt := currentEvents[m.cursor]
+			urls := extractAllURLs(evt.Content)
+			index := m.keys.linkIndex(msg.String())
+			if index >= 0 && index < len(urls) {
+				go OpenMedia(urls[index])
+				m.statusMsg = fmt.Sprintf("Opening [%d]: %s", index+1, urls[index])
+			}
+		}
+	}
+	return nil
+}
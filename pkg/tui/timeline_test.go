@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"noscli/pkg/config"
+)
+
+// longTimeline logs in to a timeline of n notes, every third one several
+// lines long, so event heights vary
+func longTimeline(t *testing.T, n int) (*uiHarness, []nostr.Event) {
+	h := newUIHarness(t, &config.Config{AuthMethod: "nsec"})
+	events := make([]nostr.Event, n)
+	for i := range events {
+		content := fmt.Sprintf("note %d", i)
+		if i%3 == 0 {
+			content += strings.Repeat(" more", 40)
+		}
+		events[i] = h.event(goldenAlice, 1, content, time.Duration(i+1)*time.Minute)
+	}
+	h.loggedIn(events...)
+	return h, events
+}
+
+// selectedVisible checks the whole selected note, border to border, is in
+// the viewport
+func selectedVisible(t *testing.T, h *uiHarness) {
+	t.Helper()
+	view := h.m.viewport.View()
+	lines := strings.Split(view, "\n")
+	note := regexp.MustCompile(fmt.Sprintf(`\bnote %d\b`, h.m.cursor))
+	for i, line := range lines {
+		if !strings.Contains(line, "│ > ") {
+			continue
+		}
+		if i == 0 || !strings.Contains(lines[i-1], "╭") {
+			break
+		}
+		for _, rest := range lines[i+1:] {
+			if strings.Contains(rest, "╰") {
+				if note.MatchString(strings.Join(lines[i:], "\n")) {
+					return
+				}
+				break
+			}
+		}
+		break
+	}
+	t.Fatalf("cursor %d isn't all on screen:\n%s", h.m.cursor, view)
+}
+
+func TestTimelineScrollsExactly(t *testing.T) {
+	h, events := longTimeline(t, 50)
+
+	for i := 1; i < len(events); i++ {
+		h.keys("down")
+		selectedVisible(t, h)
+	}
+	for i := 0; i < 10; i++ {
+		h.keys("up")
+		selectedVisible(t, h)
+	}
+
+	h.keys("g")
+	if h.m.cursor != 0 || h.m.top != 0 || h.m.topLine != 0 {
+		t.Errorf("g: cursor %d, top %d+%d", h.m.cursor, h.m.top, h.m.topLine)
+	}
+	h.keys("f")
+	selectedVisible(t, h)
+	h.keys("G")
+	if h.m.cursor != len(events)-1 {
+		t.Errorf("G: cursor %d", h.m.cursor)
+	}
+	selectedVisible(t, h)
+	h.keys("b")
+	selectedVisible(t, h)
+}
+
+func TestTimelineRendersOnlyVisible(t *testing.T) {
+	h, events := longTimeline(t, 2000)
+	if len(h.m.rendered) > h.m.viewport.Height {
+		t.Errorf("rendered %d of %d events for a %d line viewport", len(h.m.rendered), len(events), h.m.viewport.Height)
+	}
+
+	// Moving the cursor reuses the other renderings
+	h.keys("down")
+	rendered := len(h.m.rendered)
+	h.keys("up", "down")
+	if len(h.m.rendered) != rendered {
+		t.Errorf("cursor moves rendered %d more events", len(h.m.rendered)-rendered)
+	}
+
+	// Jumping to the bottom only renders the events near it
+	h.keys("G")
+	selectedVisible(t, h)
+	if len(h.m.rendered) > 3*h.m.viewport.Height {
+		t.Errorf("rendered %d events jumping to the bottom", len(h.m.rendered))
+	}
+}
@@ -0,0 +1,292 @@
+package tui
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nbd-wtf/go-nostr"
+	"noscli/pkg/config"
+	"noscli/pkg/nwc"
+)
+
+// maxWalletHistory caps how many wallet payments are kept in memory
+const maxWalletHistory = 50
+
+// walletIndex returns the index of the named wallet, or -1
+func (a *appContext) walletIndex(name string) int {
+	for i, w := range a.wallets {
+		if w.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyDefaultWallet points the persistent NWC subscription at the default
+// wallet, restarting it if the connection string changed
+func (a *appContext) applyDefaultWallet() {
+	uri := ""
+	if i := a.walletIndex(a.defaultWallet); i >= 0 {
+		uri = a.wallets[i].NWC
+	}
+
+	if uri == a.nwcString && (uri == "" || a.nwcActive) {
+		return
+	}
+
+	a.stopNWCSubscription()
+	a.nwcString = uri
+	a.startNWCSubscription()
+}
+
+// walletLimitsSummary describes a wallet's limits and what was spent against them
+func (a *appContext) walletLimitsSummary(w config.Wallet) string {
+	now := time.Now()
+	var parts []string
+	if w.MaxPerZap > 0 {
+		parts = append(parts, fmt.Sprintf("max %d/zap", w.MaxPerZap))
+	}
+	if w.DailyBudget > 0 {
+		spent := a.spending.SpentSince(w.Name, config.StartOfDay(now))
+		parts = append(parts, fmt.Sprintf("today %d/%d", spent, w.DailyBudget))
+	}
+	if w.MonthlyBudget > 0 {
+		spent := a.spending.SpentSince(w.Name, config.StartOfMonth(now))
+		parts = append(parts, fmt.Sprintf("month %d/%d", spent, w.MonthlyBudget))
+	}
+	if len(parts) == 0 {
+		return "(no limits)"
+	}
+	return "(" + strings.Join(parts, ", ") + " sats)"
+}
+
+// parseWalletLimits parses "per-zap daily monthly" into three sat amounts
+func parseWalletLimits(input string) ([3]int64, error) {
+	var limits [3]int64
+	fields := strings.Fields(input)
+	if len(fields) != 3 {
+		return limits, fmt.Errorf("enter three numbers: per-zap daily monthly")
+	}
+	for i, field := range fields {
+		value, err := strconv.ParseInt(field, 10, 64)
+		if err != nil || value < 0 {
+			return limits, fmt.Errorf("invalid limit: %s", field)
+		}
+		limits[i] = value
+	}
+	return limits, nil
+}
+
+// handleNWCResponse hands a wallet's response to the payment waiting for
+// it, and keeps listening on the NWC subscription
+func (m *Model) handleNWCResponse(evt *nostr.Event) tea.Cmd {
+	// Wallet notifications share the NWC subscription with responses
+	if nwc.IsNotificationKind(evt.Kind) {
+		m.handleWalletNotification(evt)
+		return waitForNWCResponse(m.nwcResponseChan)
+	}
+
+	// Handle NWC response - match to pending payment
+	log.Printf("📬 [NWC] Processing response: %s", evt.ID[:8])
+
+	// Find which request this is responding to by checking 'e' tag
+	var requestID string
+	for _, tag := range evt.Tags {
+		if len(tag) >= 2 && tag[0] == "e" {
+			requestID = tag[1]
+			break
+		}
+	}
+
+	if requestID != "" {
+		log.Printf("🔗 [NWC] Response for request: %s", requestID[:8])
+		if respChan, ok := m.takePendingPayment(requestID); ok {
+			log.Printf("✅ [NWC] Found pending payment, forwarding response")
+			// Send response to waiting channel
+			select {
+			case respChan <- evt:
+				log.Printf("📨 [NWC] Response delivered")
+			default:
+				log.Printf("⚠️  [NWC] Response channel full or closed")
+			}
+		} else {
+			log.Printf("⚠️  [NWC] No pending payment for request %s", requestID[:8])
+		}
+	} else {
+		log.Printf("⚠️  [NWC] Response has no 'e' tag")
+	}
+
+	// Continue waiting for more responses
+	return waitForNWCResponse(m.nwcResponseChan)
+}
+
+// handleWalletNotification decrypts a NIP-47 notification and records it in
+// the wallet history, the status bar and optionally the Notifications view
+func (m *Model) handleWalletNotification(evt *nostr.Event) {
+	if _, seen := m.walletNotifText[evt.ID]; seen {
+		// Relays replay stored notifications whenever the subscription restarts
+		return
+	}
+
+	walletPubkey, _, secret, err := nwc.ParseNWCString(m.nwcString)
+	if err != nil {
+		log.Printf("❌ [NWC] Can't handle notification, bad NWC string: %v", err)
+		return
+	}
+
+	notification, err := nwc.DecryptNotification(evt, walletPubkey, secret)
+	if err != nil {
+		log.Printf("❌ [NWC] Failed to decrypt notification %s: %v", evt.ID[:8], err)
+		return
+	}
+	log.Printf("🔔 [NWC] Notification %s: %s %d msats", evt.ID[:8], notification.NotificationType, notification.Notification.Amount)
+
+	var summary string
+	switch notification.NotificationType {
+	case nwc.NotificationPaymentReceived:
+		summary = "⬇️  Received " + formatSats(notification.Notification.Amount)
+	case nwc.NotificationPaymentSent:
+		summary = "⬆️  Sent " + formatSats(notification.Notification.Amount)
+		if notification.Notification.FeesPaid > 0 {
+			summary += fmt.Sprintf(" (fee %s)", formatSats(notification.Notification.FeesPaid))
+		}
+	default:
+		log.Printf("⚠️  [NWC] Ignoring unknown notification type: %s", notification.NotificationType)
+		return
+	}
+	if notification.Notification.Description != "" {
+		summary += " — " + notification.Notification.Description
+	}
+	m.walletNotifText[evt.ID] = summary
+
+	m.addWalletHistory(notification.Notification)
+
+	// Only announce payments that happened while we were listening, not the backlog
+	if evt.CreatedAt >= m.nwcSubStarted {
+		m.statusMsg = "⚡ " + summary
+	}
+
+	if m.walletNotifsInFeed {
+		m.notifications = append(m.notifications, *evt)
+		sort.Slice(m.notifications, func(i, j int) bool {
+			return m.notifications[i].CreatedAt.Time().After(m.notifications[j].CreatedAt.Time())
+		})
+		m.updateContent()
+	}
+}
+
+// addWalletHistory records a transaction, deduplicating by payment hash
+func (m *Model) addWalletHistory(tx nwc.Transaction) {
+	for i, existing := range m.walletHistory {
+		if tx.PaymentHash != "" && existing.PaymentHash == tx.PaymentHash {
+			m.walletHistory[i] = tx
+			return
+		}
+	}
+
+	m.walletHistory = append(m.walletHistory, tx)
+	sort.Slice(m.walletHistory, func(i, j int) bool {
+		return walletTxTime(m.walletHistory[i]) > walletTxTime(m.walletHistory[j])
+	})
+	if len(m.walletHistory) > maxWalletHistory {
+		m.walletHistory = m.walletHistory[:maxWalletHistory]
+	}
+}
+
+// walletTxTime returns the best known timestamp for a transaction
+func walletTxTime(tx nwc.Transaction) int64 {
+	if tx.SettledAt != 0 {
+		return tx.SettledAt
+	}
+	return tx.CreatedAt
+}
+
+// visibleNotifications returns the Notifications view's events. Wallet
+// payments already in it drop out while the setting hides them.
+func (m *Model) visibleNotifications() []nostr.Event {
+	if m.walletNotifsInFeed {
+		return m.notifications
+	}
+	return slices.DeleteFunc(slices.Clone(m.notifications), func(evt nostr.Event) bool {
+		return nwc.IsNotificationKind(evt.Kind)
+	})
+}
+
+// selectedIsWalletNotification reports whether the cursor is on a wallet notification
+func (m *Model) selectedIsWalletNotification() bool {
+	currentEvents := m.getCurrentEvents()
+	if m.cursor < len(currentEvents) {
+		return nwc.IsNotificationKind(currentEvents[m.cursor].Kind)
+	}
+	return false
+}
+
+// renderWalletHistory renders the most recent wallet payments for the Wallet tab
+func (a *appContext) renderWalletHistory(limit int) string {
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true).Padding(0, 2)
+	itemStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Padding(0, 2)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Padding(0, 2)
+	incomingStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
+	outgoingStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+	var content strings.Builder
+	content.WriteString(headerStyle.Render("Recent payments:"))
+	content.WriteString("\n")
+
+	if len(a.walletHistory) == 0 {
+		content.WriteString(dimStyle.Render("No payments yet (your wallet must support notifications)"))
+		content.WriteString("\n")
+		return content.String()
+	}
+
+	for i, tx := range a.walletHistory {
+		if i >= limit {
+			break
+		}
+		amount := outgoingStyle.Render("-" + formatSats(tx.Amount))
+		if tx.Type == "incoming" {
+			amount = incomingStyle.Render("+" + formatSats(tx.Amount))
+		}
+		line := fmt.Sprintf("%s  %s", formatTimestamp(time.Unix(walletTxTime(tx), 0)), amount)
+		if tx.Description != "" {
+			line += "  " + truncateRunes(tx.Description, 40)
+		}
+		content.WriteString(itemStyle.Render(line))
+		content.WriteString("\n")
+	}
+
+	return content.String()
+}
+
+// formatSats formats a millisatoshi amount as sats with thousands separators
+func formatSats(msats int64) string {
+	sats := msats / 1000
+	digits := fmt.Sprintf("%d", sats)
+	if sats < 0 {
+		digits = digits[1:]
+	}
+
+	var grouped strings.Builder
+	for i, ch := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(ch)
+	}
+
+	unit := " sats"
+	if sats == 1 || sats == -1 {
+		unit = " sat"
+	}
+	if sats < 0 {
+		return "-" + grouped.String() + unit
+	}
+	return grouped.String() + unit
+}
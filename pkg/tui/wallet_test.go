@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nbd-wtf/go-nostr"
+	"noscli/pkg/config"
+	"noscli/pkg/nwc"
+)
+
+func TestHidingWalletNotificationsFromFeed(t *testing.T) {
+	h := newUIHarness(t, &config.Config{AuthMethod: "nsec", WalletNotifications: true})
+	h.loggedIn()
+	mention := h.event(goldenAlice, 1, "gm @me", time.Minute)
+	payment := h.event(goldenBob, nwc.KindNotificationNIP44, "", 2*time.Minute)
+	h.send(notificationsMsg{events: []nostr.Event{mention}})
+	h.m.notifications = append(h.m.notifications, payment)
+	h.m.walletNotifText[payment.ID] = "Received 21 sats"
+	for i, c := range h.m.columns {
+		if c.view == viewNotifications {
+			h.m.focusColumn(i)
+		}
+	}
+	h.m.cursor = 1
+	h.m.updateContent()
+	if !strings.Contains(h.m.View(), "Received 21 sats") {
+		t.Fatalf("wallet payment not shown:\n%s", h.m.View())
+	}
+
+	// Turning the setting off hides the payments already in the view
+	h.m.settings.menu = 2
+	h.m.settings.Update(h.m.appContext, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if h.m.walletNotifsInFeed {
+		t.Fatal("n didn't turn wallet notifications off")
+	}
+	h.m.updateContent()
+	if events := h.m.getCurrentEvents(); len(events) != 1 || events[0].ID != mention.ID || h.m.cursor != 0 {
+		t.Errorf("events = %d, cursor = %d, want only the mention selected", len(events), h.m.cursor)
+	}
+	if view := h.m.View(); strings.Contains(view, "Received 21 sats") {
+		t.Errorf("hidden wallet payment still shown:\n%s", view)
+	}
+
+	// And turning it back on shows them again
+	h.m.settings.Update(h.m.appContext, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if events := h.m.getCurrentEvents(); len(events) != 2 {
+		t.Errorf("events = %d after turning them back on, want 2", len(events))
+	}
+}
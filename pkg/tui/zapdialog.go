@@ -0,0 +1,259 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"noscli/pkg/fiat"
+	"noscli/pkg/zap"
+)
+
+// fiatRateTTL is how long a fetched exchange rate is used before refreshing
+const fiatRateTTL = 10 * time.Minute
+
+// zapPaid is one recipient's settled share of a zap
+type zapPaid struct {
+	pubkey     string
+	amountSats int64
+	invoice    string
+	preimage   string
+	feesMsats  int64 // Routing fees, if the wallet reported them
+}
+
+// fiatRateMsg carries a freshly fetched BTC price in the configured currency
+type fiatRateMsg struct {
+	rate float64
+	err  error
+}
+
+// fetchFiatRateCmd fetches the price of one bitcoin for fiat estimates
+func fetchFiatRateCmd(currency, rateURL string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		rate, err := fiat.FetchRate(ctx, &http.Client{Timeout: 10 * time.Second}, rateURL, currency)
+		return fiatRateMsg{rate: rate, err: err}
+	}
+}
+
+// refreshFiatRate returns a command fetching the exchange rate if estimates
+// are enabled and the rate is missing or stale
+func (a *appContext) refreshFiatRate() tea.Cmd {
+	if a.fiatCurrency == "" || a.fiatRatePending || time.Since(a.fiatRateTime) < fiatRateTTL {
+		return nil
+	}
+	a.fiatRatePending = true
+	return fetchFiatRateCmd(a.fiatCurrency, a.fiatRateURL)
+}
+
+// fiatEstimate describes sats in the configured currency, or "" if unknown
+func (a *appContext) fiatEstimate(sats int64) string {
+	if a.fiatCurrency == "" || a.fiatRate <= 0 {
+		return ""
+	}
+	return fiat.Estimate(sats, a.fiatRate, a.fiatCurrency)
+}
+
+// zapDialogScreen is a zap in the confirmation dialog: waiting to be
+// confirmed, then being sent, then showing its outcome
+type zapDialogScreen struct {
+	subject  zapSubject
+	payments []zapPayment
+	opts     zapOptions
+	exceeded []string          // Budgets the zap would go over
+	sending  bool              // Whether the zap was confirmed and is on its way
+	result   *zapSuccessMsg    // Outcome, once at least one recipient was paid
+	err      error             // Why the zap failed for every recipient
+	receipts []*zapReceiptInfo // Receipts published for the invoices we paid
+}
+
+// open asks for confirmation before sending a zap
+func (d *zapDialogScreen) open(app *appContext, msg confirmZapMsg) tea.Cmd {
+	*d = zapDialogScreen{
+		subject:  msg.subject,
+		payments: msg.payments,
+		opts:     msg.opts,
+		exceeded: msg.exceeded,
+	}
+	app.overlay = overlayZapDialog
+	app.statusMsg = "⚡ Confirm zap"
+	return app.refreshFiatRate()
+}
+
+// close closes the dialog. A zap being sent keeps going, and its outcome
+// lands in the status line.
+func (d *zapDialogScreen) close(app *appContext) {
+	*d = zapDialogScreen{}
+	app.overlay = overlayNone
+}
+
+// Update sends or cancels the zap, and closes the dialog once it's done
+func (d *zapDialogScreen) Update(app *appContext, msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case !d.sending:
+		switch msg.String() {
+		case "y", "Y", "enter":
+			d.sending = true
+			app.statusMsg = "⚡ Zapping..."
+			return app.performZap(d.subject, d.payments, d.opts)
+		case "n", "N", "esc":
+			d.close(app)
+			app.statusMsg = "Zap cancelled"
+		}
+	case d.result == nil && d.err == nil:
+		if msg.String() == "esc" {
+			d.close(app)
+		}
+	default:
+		switch msg.String() {
+		case "enter", "esc", "q":
+			d.close(app)
+		}
+	}
+	return nil
+}
+
+// Status leaves the status line as it is
+func (d *zapDialogScreen) Status(app *appContext) string {
+	return app.statusMsg
+}
+
+// matchReceipt attaches a receipt to the dialog if it's for an invoice we paid
+func (d *zapDialogScreen) matchReceipt(info *zapReceiptInfo) {
+	if d.result == nil || info.receipt == nil {
+		return
+	}
+	for _, paid := range d.result.paid {
+		if strings.EqualFold(paid.invoice, info.receipt.Bolt11) {
+			d.receipts = append(d.receipts, info)
+			return
+		}
+	}
+}
+
+// View shows the confirmation dialog or the zap's outcome in place of the feed
+func (d *zapDialogScreen) View(app *appContext) string {
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+
+	var lines []string
+	field := func(label, value string) {
+		lines = append(lines, labelStyle.Render(fmt.Sprintf("%-10s", label))+value)
+	}
+
+	amount := formatSats(d.opts.amountSats * 1000)
+	if estimate := app.fiatEstimate(d.opts.amountSats); estimate != "" {
+		amount += " " + labelStyle.Render(estimate)
+	}
+
+	switch {
+	case !d.sending:
+		lines = append(lines, titleStyle.Render("⚡ Confirm zap"), "")
+		for _, payment := range d.payments {
+			recipient := "@" + app.displayName(payment.pubkey)
+			if payment.target != nil {
+				recipient += " (" + payment.target.Address + ")"
+			}
+			if len(d.payments) > 1 {
+				recipient += fmt.Sprintf(": %s", formatSats(payment.amountSats*1000))
+			}
+			if payment.err != nil {
+				recipient += warnStyle.Render(fmt.Sprintf(" ⚠️ %v", payment.err))
+			}
+			field("To", recipient)
+		}
+		field("For", d.describeSubject(app))
+		field("Amount", amount)
+		comment := d.opts.comment
+		if comment == "" {
+			comment = labelStyle.Render("(none)")
+		}
+		field("Comment", comment)
+		field("Wallet", d.opts.wallet.Name)
+		if d.opts.anon {
+			field("Sender", "anonymous")
+		}
+		if len(d.exceeded) > 0 {
+			lines = append(lines, "", warnStyle.Render("⚠️ Exceeds "+strings.Join(d.exceeded, ", ")))
+		}
+		lines = append(lines, "", labelStyle.Render("y/Enter zap • n/Esc cancel"))
+
+	case d.err != nil:
+		lines = append(lines, titleStyle.Render("⚠️ Zap failed"), "", fmt.Sprintf("%v", d.err))
+		lines = append(lines, "", labelStyle.Render("Enter/Esc close"))
+
+	case d.result == nil:
+		lines = append(lines, titleStyle.Render("⚡ Zapping "+amount+"..."), "")
+		lines = append(lines, labelStyle.Render("Waiting for the wallet • Esc hide"))
+
+	default:
+		lines = append(lines, titleStyle.Render("⚡ "+app.zapResultStatus(*d.result)), "")
+		for _, paid := range d.result.paid {
+			summary := fmt.Sprintf("✅ @%s %s", app.displayName(paid.pubkey), formatSats(paid.amountSats*1000))
+			if paid.feesMsats > 0 {
+				summary += fmt.Sprintf(", fee %s", formatSats(paid.feesMsats))
+			}
+			lines = append(lines, summary)
+			if paid.preimage != "" {
+				field("  Preimage", paid.preimage)
+			}
+		}
+		for _, failure := range d.result.failures {
+			lines = append(lines, warnStyle.Render(fmt.Sprintf("❌ @%s %v", app.displayName(failure.pubkey), failure.err)))
+		}
+		lines = append(lines, "", d.describeReceipts())
+		lines = append(lines, "", labelStyle.Render("Enter/Esc close"))
+	}
+
+	width := min(max(app.width-4, 40), 80)
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("214")).
+		Padding(1, 2).
+		Width(width).
+		Render(strings.Join(lines, "\n"))
+}
+
+// describeSubject describes what the dialog's zap is for
+func (d *zapDialogScreen) describeSubject(app *appContext) string {
+	subject := d.subject
+	switch {
+	case subject.eventID == "":
+		return "profile of @" + app.displayName(subject.pubkey)
+	case zap.IsAddress(subject.eventID):
+		return addressKindName(subject.eventID) + " by @" + app.displayName(subject.pubkey)
+	default:
+		return "note " + subject.eventID[:8] + " by @" + app.displayName(subject.pubkey)
+	}
+}
+
+// describeReceipts describes the receipts published for the dialog's zap
+func (d *zapDialogScreen) describeReceipts() string {
+	if len(d.receipts) == 0 {
+		if d.result.eventID == "" || zap.IsAddress(d.result.eventID) {
+			return "🧾 Receipts are only tracked for zaps on notes"
+		}
+		return "🧾 Waiting for the zap receipt..."
+	}
+
+	var parts []string
+	for _, info := range d.receipts {
+		id := info.receipt.ID
+		switch {
+		case !info.checked:
+			parts = append(parts, "🧾 Receipt "+id[:8]+" (verifying...)")
+		case info.err != nil:
+			parts = append(parts, fmt.Sprintf("⚠️ Receipt %s is invalid: %v", id[:8], info.err))
+		default:
+			parts = append(parts, "🧾 Receipt "+id[:8]+" verified")
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,623 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"noscli/pkg/client"
+	"noscli/pkg/config"
+	"noscli/pkg/zap"
+)
+
+// zapPresets are the amounts Up/Down cycle through in the zap prompt
+var zapPresets = []int64{21, 100, 500, 1000, 5000, 10000, 21000}
+
+// zapTargetMsg reports the result of resolving a recipient for zapping
+type zapTargetMsg struct {
+	pubkey string
+	target *client.ZapTarget
+	err    error
+}
+
+// zapSubject is what a zap is for: a note, an addressable event or a profile
+type zapSubject struct {
+	pubkey  string     // Author, or the profile being zapped
+	eventID string     // Note ID or addressable event coordinate; empty for profile zaps
+	tags    nostr.Tags // Tags of the zapped event, for zap splits
+	relays  []string   // Relay hints for the recipient's profile
+}
+
+// subjectForEvent returns the zap subject for an event we display
+func subjectForEvent(evt nostr.Event) zapSubject {
+	subject := zapSubject{pubkey: evt.PubKey, eventID: evt.ID, tags: evt.Tags}
+	if nostr.IsAddressableKind(evt.Kind) {
+		subject.eventID = fmt.Sprintf("%d:%s:%s", evt.Kind, evt.PubKey, evt.Tags.GetD())
+	}
+	return subject
+}
+
+// parseZapSubject parses what the Z prompt accepts: an npub, nprofile, hex
+// pubkey, naddr or nevent. It returns ok=false for anything else, such as a
+// lightning address, which can only receive plain payments.
+func parseZapSubject(input string) (zapSubject, bool, error) {
+	input = strings.TrimPrefix(strings.TrimSpace(input), "nostr:")
+	if nostr.IsValid32ByteHex(input) {
+		return zapSubject{pubkey: input}, true, nil
+	}
+
+	prefix, value, err := nip19.Decode(input)
+	if err != nil {
+		return zapSubject{}, false, nil
+	}
+
+	switch prefix {
+	case "npub":
+		return zapSubject{pubkey: value.(string)}, true, nil
+	case "nprofile":
+		pointer := value.(nostr.ProfilePointer)
+		return zapSubject{pubkey: pointer.PublicKey, relays: pointer.Relays}, true, nil
+	case "naddr":
+		pointer := value.(nostr.EntityPointer)
+		return zapSubject{
+			pubkey:  pointer.PublicKey,
+			eventID: fmt.Sprintf("%d:%s:%s", pointer.Kind, pointer.PublicKey, pointer.Identifier),
+			relays:  pointer.Relays,
+		}, true, nil
+	case "nevent":
+		pointer := value.(nostr.EventPointer)
+		if pointer.Author == "" {
+			return zapSubject{}, true, fmt.Errorf("nevent has no author, zap the note from the timeline instead")
+		}
+		return zapSubject{pubkey: pointer.Author, eventID: pointer.ID, relays: pointer.Relays}, true, nil
+	default:
+		return zapSubject{}, true, fmt.Errorf("can't zap a %s", prefix)
+	}
+}
+
+// zapPayment is one recipient's part of a (possibly split) zap
+type zapPayment struct {
+	pubkey     string
+	amountSats int64
+	target     *client.ZapTarget
+	err        error // Why the recipient couldn't be resolved
+}
+
+// zapFailure records a split recipient that couldn't be paid
+type zapFailure struct {
+	pubkey string
+	err    error
+}
+
+// zapOptions are the choices made in the zap prompt
+type zapOptions struct {
+	amountSats int64
+	comment    string
+	anon       bool // Sign with a throwaway key instead of ours
+	wallet     config.Wallet
+}
+
+// resolveZapTargetCmd looks up a recipient's lightning address and fetches
+// their LNURL-pay info so the zap prompt can enforce its limits. The
+// address in a cached profile is tried first; the relays are only asked for
+// the profile if there's none or it doesn't work anymore.
+func resolveZapTargetCmd(c *client.Client, pubkey string, cached *client.Profile) tea.Cmd {
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				// Catch panics from relay operations
+				msg = zapTargetMsg{pubkey: pubkey, err: fmt.Errorf("failed to fetch recipient profile")}
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		if cached != nil && cached.LightningAddress() != "" {
+			target, err := c.ResolveProfileZapTarget(ctx, cached)
+			if err == nil {
+				return zapTargetMsg{pubkey: pubkey, target: target}
+			}
+			log.Printf("⚠️  Cached lightning address of %s failed, fetching their profile: %v", pubkey[:8], err)
+		}
+
+		target, err := c.ResolveZapTarget(ctx, pubkey)
+		return zapTargetMsg{pubkey: pubkey, target: target, err: err}
+	}
+}
+
+// nextZapPreset returns the preset after (or before) the given amount
+func nextZapPreset(amount int64, up bool) int64 {
+	if up {
+		for _, preset := range zapPresets {
+			if preset > amount {
+				return preset
+			}
+		}
+		return zapPresets[len(zapPresets)-1]
+	}
+	for i := len(zapPresets) - 1; i >= 0; i-- {
+		if zapPresets[i] < amount {
+			return zapPresets[i]
+		}
+	}
+	return zapPresets[0]
+}
+
+// zapResultStatus describes the outcome of a (possibly split) zap
+func (a *appContext) zapResultStatus(msg zapSuccessMsg) string {
+	if len(msg.failures) == 0 {
+		if msg.recipients > 1 {
+			return fmt.Sprintf("⚡ Zapped %s split between %d recipients!", formatSats(msg.amountSats*1000), msg.recipients)
+		}
+		return "⚡ Zap sent successfully!"
+	}
+
+	var failed []string
+	for _, failure := range msg.failures {
+		failed = append(failed, fmt.Sprintf("@%s (%v)", a.displayName(failure.pubkey), failure.err))
+	}
+	return fmt.Sprintf("⚠️ Zapped %s to %d of %d recipients; failed: %s",
+		formatSats(msg.amountSats*1000), msg.recipients-len(msg.failures), msg.recipients, strings.Join(failed, ", "))
+}
+
+// displayName returns the cached name for a pubkey, or a shortened pubkey
+func (a *appContext) displayName(pubkey string) string {
+	if name := a.profiles.name(pubkey); name != "" {
+		return name
+	}
+	if len(pubkey) > 8 {
+		return pubkey[:8] + "..."
+	}
+	return pubkey
+}
+
+// parseZapSplits parses "<npub|hex|me> <weight> ..." into zap split
+// recipients for a post we're composing. Empty input clears the splits.
+func parseZapSplits(input, selfPubkey, relayHint string) ([]zap.Split, error) {
+	fields := strings.Fields(strings.ReplaceAll(input, ",", " "))
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("enter pairs of recipient and weight, e.g. me 80 npub1... 20")
+	}
+
+	var splits []zap.Split
+	for i := 0; i < len(fields); i += 2 {
+		pubkey := fields[i]
+		switch {
+		case pubkey == "me":
+			pubkey = selfPubkey
+		case strings.HasPrefix(pubkey, "npub1"):
+			prefix, value, err := nip19.Decode(pubkey)
+			if err != nil || prefix != "npub" {
+				return nil, fmt.Errorf("invalid npub: %s", fields[i])
+			}
+			pubkey = value.(string)
+		case !nostr.IsValid32ByteHex(pubkey):
+			return nil, fmt.Errorf("invalid recipient: %s", fields[i])
+		}
+
+		weight, err := strconv.ParseFloat(fields[i+1], 64)
+		if err != nil || weight <= 0 {
+			return nil, fmt.Errorf("invalid weight: %s", fields[i+1])
+		}
+		splits = append(splits, zap.Split{Pubkey: pubkey, Relay: relayHint, Weight: weight})
+	}
+	return splits, nil
+}
+
+// formatZapSplits renders zap splits as "@name weight, ..." for the composer
+func (a *appContext) formatZapSplits(splits []zap.Split) string {
+	var parts []string
+	for _, split := range splits {
+		name := "me"
+		if split.Pubkey != a.pubKey {
+			name = a.displayName(split.Pubkey)
+		}
+		parts = append(parts, fmt.Sprintf("@%s %s", name, strconv.FormatFloat(split.Weight, 'f', -1, 64)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// zapSplitsInput renders zap splits in the format parseZapSplits accepts
+func (a *appContext) zapSplitsInput(splits []zap.Split) string {
+	var parts []string
+	for _, split := range splits {
+		recipient := "me"
+		if split.Pubkey != a.pubKey {
+			recipient, _ = nip19.EncodePublicKey(split.Pubkey)
+		}
+		parts = append(parts, recipient+" "+strconv.FormatFloat(split.Weight, 'f', -1, 64))
+	}
+	return strings.Join(parts, " ")
+}
+
+// zapSplitLine shows who a note's zaps go to, or "" if they go to its author
+func (m *Model) zapSplitLine(evt nostr.Event) string {
+	splits := zap.ParseSplits(evt.Tags)
+	if len(splits) == 0 || (len(splits) == 1 && splits[0].Pubkey == evt.PubKey) {
+		return ""
+	}
+
+	var total float64
+	for _, split := range splits {
+		total += split.Weight
+	}
+	var parts []string
+	for _, split := range splits {
+		parts = append(parts, fmt.Sprintf("@%s %.0f%%", m.displayName(split.Pubkey), split.Weight/total*100))
+	}
+	return "⚡ Zaps split: " + strings.Join(parts, ", ")
+}
+
+// zapProfileDefault prefills the Z prompt from the selected item: the note's
+// author, or the sender of a zap receipt so it's easy to zap back
+func (m *Model) zapProfileDefault() string {
+	currentEvents := m.getCurrentEvents()
+	if m.cursor >= len(currentEvents) || m.selectedIsWalletNotification() {
+		return ""
+	}
+	pubkey := currentEvents[m.cursor].PubKey
+	if info := m.zapReceipts[currentEvents[m.cursor].ID]; info != nil && info.receipt != nil {
+		pubkey = info.receipt.Sender
+	}
+	npub, err := nip19.EncodePublicKey(pubkey)
+	if err != nil {
+		return ""
+	}
+	return npub
+}
+
+// zapStep is what the zap prompt is asking for
+type zapStep int
+
+const (
+	zapStepProfile zapStep = iota // Who to zap without a note (Z)
+	zapStepAmount
+	zapStepComment
+)
+
+// sendSatsMsg asks for the amount to send to a lightning address, when the
+// zap prompt is given one that has no nostr profile to zap
+type sendSatsMsg struct {
+	address string
+}
+
+// confirmZapMsg asks to confirm a zap in the zap dialog before sending it
+type confirmZapMsg struct {
+	subject  zapSubject
+	payments []zapPayment
+	opts     zapOptions
+	exceeded []string // Budgets the zap would go over
+}
+
+// zapPromptScreen asks who to zap, how much and with what comment in the
+// status line, while it looks up the recipients' wallets
+type zapPromptScreen struct {
+	step         zapStep
+	profileInput string                       // Input for npub, nprofile, naddr or lightning address
+	amount       string                       // Amount input, in sats
+	comment      string                       // Optional comment sent with the zap
+	anon         bool                         // Whether to zap anonymously
+	wallet       int                          // Index of the wallet paying this zap
+	subject      *zapSubject                  // Note, addressable event or profile being zapped
+	splits       []zap.Split                  // Who the zap pays: the author, or the note's zap split recipients
+	targets      map[string]*client.ZapTarget // Recipient pubkey -> resolved LNURL-pay endpoint
+	targetErrs   map[string]error             // Recipient pubkey -> why they can't be zapped
+}
+
+// openProfile asks who to zap, with input filled in
+func (z *zapPromptScreen) openProfile(app *appContext, input string) {
+	*z = zapPromptScreen{step: zapStepProfile, profileInput: input}
+	app.overlay = overlayZapPrompt
+}
+
+// open asks how much to zap subject, and looks up the wallets of everyone
+// the zap pays: the author or profile, or the recipients of zap split tags
+func (z *zapPromptScreen) open(app *appContext, subject zapSubject) tea.Cmd {
+	splits := zap.ParseSplits(subject.tags)
+	if len(splits) == 0 {
+		splits = []zap.Split{{Pubkey: subject.pubkey, Weight: 1}}
+	}
+	*z = zapPromptScreen{
+		step:       zapStepAmount,
+		amount:     "21", // Default 21 sats
+		wallet:     max(app.walletIndex(app.defaultWallet), 0),
+		subject:    &subject,
+		splits:     splits,
+		targets:    make(map[string]*client.ZapTarget),
+		targetErrs: make(map[string]error),
+	}
+	app.overlay = overlayZapPrompt
+
+	cmds := []tea.Cmd{app.refreshFiatRate()}
+	for _, split := range splits {
+		relays := append(append([]string{}, subject.relays...), app.relays...)
+		if split.Relay != "" {
+			relays = append([]string{split.Relay}, relays...)
+		}
+		c := app.client()
+		c.Relays = relays
+		var cached *client.Profile
+		if profile, ok := app.profiles.profile(split.Pubkey); ok {
+			cached = &profile.Profile
+		}
+		cmds = append(cmds, resolveZapTargetCmd(c, split.Pubkey, cached))
+	}
+	return tea.Batch(cmds...)
+}
+
+// close closes the prompt and forgets what was entered
+func (z *zapPromptScreen) close(app *appContext) {
+	*z = zapPromptScreen{}
+	app.overlay = overlayNone
+}
+
+// addTarget records a recipient's resolved wallet. Lookups for a prompt
+// that was cancelled or moved on are ignored.
+func (z *zapPromptScreen) addTarget(msg zapTargetMsg) {
+	if z.subject == nil || !z.isRecipient(msg.pubkey) {
+		return
+	}
+	if msg.err != nil {
+		z.targetErrs[msg.pubkey] = msg.err
+	} else {
+		z.targets[msg.pubkey] = msg.target
+	}
+}
+
+// submitProfile starts zapping who the Z prompt names, or paying them if
+// it's a plain lightning address. The prompt stays open if it's neither.
+func (z *zapPromptScreen) submitProfile(app *appContext) tea.Cmd {
+	input := trimLightningScheme(strings.TrimSpace(z.profileInput))
+	subject, ok, err := parseZapSubject(input)
+	if err != nil {
+		app.statusMsg = fmt.Sprintf("⚠️ %v", err)
+		return nil
+	}
+	if ok {
+		app.statusMsg = "Enter zap amount (sats): "
+		return z.open(app, subject)
+	}
+	// Without a nostr pubkey there's nothing to zap, but we can still pay
+	if _, err := zap.ResolvePayEndpoint(input); err != nil {
+		app.statusMsg = "⚠️ Enter an npub, nprofile, naddr or lightning address"
+		return nil
+	}
+	z.close(app)
+	app.statusMsg = "Not a nostr profile, sending a plain lightning payment"
+	return func() tea.Msg { return sendSatsMsg{address: input} }
+}
+
+// isRecipient reports whether pubkey is paid by the zap being prepared
+func (z *zapPromptScreen) isRecipient(pubkey string) bool {
+	for _, split := range z.splits {
+		if split.Pubkey == pubkey {
+			return true
+		}
+	}
+	return false
+}
+
+// commentLimit returns the strictest comment length among the recipients, or 0
+func (z *zapPromptScreen) commentLimit() int {
+	limit := 0
+	for _, target := range z.targets {
+		if allowed := target.Info.CommentAllowed; allowed > 0 && (limit == 0 || allowed < limit) {
+			limit = allowed
+		}
+	}
+	return limit
+}
+
+// payments splits amountSats between the recipients and checks each share
+// against its recipient's LNURL limits. Recipients whose wallet couldn't be
+// resolved are kept, with their error, so they can be reported as failed.
+func (z *zapPromptScreen) payments(app *appContext, amountSats int64, comment string) ([]zapPayment, error) {
+	if resolved := len(z.targets) + len(z.targetErrs); resolved < len(z.splits) {
+		return nil, fmt.Errorf("still looking up the recipient's wallet")
+	}
+
+	var payments []zapPayment
+	payable := 0
+	for _, share := range zap.SplitAmount(z.splits, amountSats) {
+		payment := zapPayment{pubkey: share.Pubkey, amountSats: share.AmountSats}
+		if err, failed := z.targetErrs[share.Pubkey]; failed {
+			payment.err = err
+		} else {
+			payment.target = z.targets[share.Pubkey]
+			if err := zap.CheckZapParams(payment.target.Info, share.AmountSats, comment); err != nil {
+				if len(z.splits) == 1 {
+					return nil, err
+				}
+				return nil, fmt.Errorf("@%s's share of %d sats: %w", app.displayName(share.Pubkey), share.AmountSats, err)
+			}
+			payable++
+		}
+		payments = append(payments, payment)
+	}
+
+	if payable == 0 {
+		if len(z.splits) == 1 {
+			return nil, z.targetErrs[z.splits[0].Pubkey]
+		}
+		return nil, fmt.Errorf("none of the recipients can be zapped")
+	}
+	return payments, nil
+}
+
+// options collects the choices made in the prompt
+func (z *zapPromptScreen) options(app *appContext) zapOptions {
+	amount, _ := strconv.ParseInt(z.amount, 10, 64)
+	return zapOptions{
+		amountSats: amount,
+		comment:    strings.TrimSpace(z.comment),
+		anon:       z.anon,
+		wallet:     app.wallets[z.wallet],
+	}
+}
+
+// Update types into the prompt and moves on to the next question, the
+// confirmation dialog or the zap itself
+func (z *zapPromptScreen) Update(app *appContext, msg tea.KeyMsg) tea.Cmd {
+	switch z.step {
+	case zapStepProfile:
+		switch msg.String() {
+		case "esc":
+			z.close(app)
+			app.statusMsg = ""
+		case "enter":
+			return z.submitProfile(app)
+		case "backspace":
+			if len(z.profileInput) > 0 {
+				z.profileInput = z.profileInput[:len(z.profileInput)-1]
+			}
+		default:
+			if msg.Type == tea.KeyRunes {
+				z.profileInput += string(msg.Runes)
+			}
+		}
+
+	case zapStepComment:
+		switch msg.String() {
+		case "esc":
+			z.close(app)
+			app.statusMsg = ""
+		case "enter":
+			opts := z.options(app)
+			payments, err := z.payments(app, opts.amountSats, opts.comment)
+			if err != nil {
+				app.statusMsg = fmt.Sprintf("⚠️ %v", err)
+				return nil
+			}
+			subject := *z.subject
+			z.close(app)
+
+			// Check client-side limits before anything leaves the wallet
+			exceeded := config.CheckBudget(opts.wallet, app.spending, opts.amountSats, time.Now())
+			if len(exceeded) > 0 || opts.amountSats > app.zapConfirmAbove {
+				return func() tea.Msg {
+					return confirmZapMsg{subject: subject, payments: payments, opts: opts, exceeded: exceeded}
+				}
+			}
+
+			app.statusMsg = "⚡ Zapping..."
+			return app.performZap(subject, payments, opts)
+		case "backspace":
+			if runes := []rune(z.comment); len(runes) > 0 {
+				z.comment = string(runes[:len(runes)-1])
+			}
+		case " ", "space":
+			z.comment += " "
+		default:
+			if msg.Type == tea.KeyRunes {
+				z.comment += string(msg.Runes)
+			}
+		}
+
+	default:
+		switch msg.String() {
+		case "esc":
+			z.close(app)
+			app.statusMsg = ""
+		case "enter":
+			amount, err := strconv.ParseInt(z.amount, 10, 64)
+			if err != nil || amount <= 0 {
+				app.statusMsg = "⚠️ Invalid amount"
+				return nil
+			}
+			// Enforce the recipients' min/max before asking for a comment
+			if _, err := z.payments(app, amount, ""); err != nil {
+				app.statusMsg = fmt.Sprintf("⚠️ %v", err)
+				return nil
+			}
+			z.step = zapStepComment
+		case "up", "down":
+			amount, _ := strconv.ParseInt(z.amount, 10, 64)
+			z.amount = strconv.FormatInt(nextZapPreset(amount, msg.String() == "up"), 10)
+		case "a":
+			z.anon = !z.anon
+		case "tab":
+			// Pick which wallet pays
+			if len(app.wallets) > 0 {
+				z.wallet = (z.wallet + 1) % len(app.wallets)
+			}
+		case "backspace":
+			if len(z.amount) > 0 {
+				z.amount = z.amount[:len(z.amount)-1]
+			}
+		default:
+			// Only accept digits
+			if len(msg.String()) == 1 {
+				ch := msg.String()[0]
+				if ch >= '0' && ch <= '9' {
+					z.amount += msg.String()
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Status describes the prompt for the status line
+func (z *zapPromptScreen) Status(app *appContext) string {
+	switch z.step {
+	case zapStepProfile:
+		return fmt.Sprintf("⚡ Zap npub, nprofile, naddr or lightning address: %s_ (Enter to continue, Esc to cancel)", z.profileInput)
+	case zapStepComment:
+		prompt := fmt.Sprintf("⚡ Comment for %s sats: %s_", z.amount, z.comment)
+		if limit := z.commentLimit(); limit > 0 {
+			prompt += fmt.Sprintf(" (%d/%d)", len([]rune(z.comment)), limit)
+		}
+		return prompt + " (Enter send, Esc cancel)"
+	}
+
+	prompt := fmt.Sprintf("⚡ Zap amount (sats): %s_", z.amount)
+	if z.subject != nil && z.subject.eventID == "" {
+		prompt = fmt.Sprintf("⚡ Zap @%s's profile (sats): %s_", app.displayName(z.subject.pubkey), z.amount)
+	}
+	if amount, err := strconv.ParseInt(z.amount, 10, 64); err == nil {
+		if estimate := app.fiatEstimate(amount); estimate != "" {
+			prompt += " " + estimate
+		}
+	}
+	if len(z.splits) > 1 {
+		prompt += fmt.Sprintf(" split between %d", len(z.splits))
+	}
+	resolved := len(z.targets) + len(z.targetErrs)
+	switch {
+	case resolved < len(z.splits):
+		prompt += fmt.Sprintf(" (looking up wallets %d/%d...)", resolved, len(z.splits))
+	case len(z.splits) == 1 && len(z.targetErrs) == 1:
+		prompt += fmt.Sprintf(" ⚠️ %v", z.targetErrs[z.splits[0].Pubkey])
+	case len(z.targetErrs) > 0:
+		prompt += fmt.Sprintf(" ⚠️ %d can't be zapped", len(z.targetErrs))
+	case len(z.splits) == 1:
+		info := z.targets[z.splits[0].Pubkey].Info
+		if info.MinSendable > 0 || info.MaxSendable > 0 {
+			prompt += fmt.Sprintf(" [%d–%d]", (info.MinSendable+999)/1000, info.MaxSendable/1000)
+		}
+	}
+	if z.anon {
+		prompt += " 🕶️ anonymous"
+	}
+	if len(app.wallets) > 1 {
+		prompt += " via " + app.wallets[z.wallet].Name
+	}
+
+	prompt += " (↑/↓ presets, a anon"
+	if len(app.wallets) > 1 {
+		prompt += ", Tab wallet"
+	}
+	return prompt + ", Enter next, Esc cancel)"
+}
+
+// View leaves the feed showing under the prompt
+func (z *zapPromptScreen) View(app *appContext) string {
+	return ""
+}
@@ -0,0 +1,227 @@
+package zap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+)
+
+// defaultInvoiceExpiry is used when an invoice has no 'x' field (BOLT-11)
+const defaultInvoiceExpiry = 3600 * time.Second
+
+// BOLT-11 tagged field types (5-bit values of the bech32 characters)
+const (
+	tagPaymentHash     = 1  // 'p'
+	tagDescription     = 13 // 'd'
+	tagPayee           = 19 // 'n'
+	tagDescriptionHash = 23 // 'h'
+	tagExpiry          = 6  // 'x'
+)
+
+// Invoice holds the fields of a decoded BOLT-11 payment request
+type Invoice struct {
+	Network         string        // Currency prefix: "bc", "tb", "tbs", "bcrt", ...
+	AmountMsats     int64         // Zero for amountless invoices
+	CreatedAt       time.Time     // Invoice timestamp
+	Expiry          time.Duration // Time until the invoice expires
+	PaymentHash     string        // Hex payment hash
+	Description     string        // 'd' field, if present
+	DescriptionHash string        // Hex 'h' field, if present
+	Payee           string        // Hex 'n' field, if present
+}
+
+// ExpiresAt returns when the invoice stops being payable
+func (i *Invoice) ExpiresAt() time.Time {
+	return i.CreatedAt.Add(i.Expiry)
+}
+
+// Expired reports whether the invoice has expired
+func (i *Invoice) Expired() bool {
+	return time.Now().After(i.ExpiresAt())
+}
+
+// AmountSats returns the invoice amount in whole sats
+func (i *Invoice) AmountSats() int64 {
+	return i.AmountMsats / 1000
+}
+
+// IsInvoice reports whether s looks like a BOLT-11 payment request
+func IsInvoice(s string) bool {
+	s = strings.ToLower(strings.TrimPrefix(strings.ToLower(s), "lightning:"))
+	return strings.HasPrefix(s, "lnbc") || strings.HasPrefix(s, "lntb") ||
+		strings.HasPrefix(s, "lntbs") || strings.HasPrefix(s, "lnbcrt") ||
+		strings.HasPrefix(s, "lnsb")
+}
+
+// DecodeInvoice decodes a BOLT-11 payment request. The signature is not verified.
+func DecodeInvoice(bolt11 string) (*Invoice, error) {
+	bolt11 = strings.ToLower(strings.TrimSpace(bolt11))
+	bolt11 = strings.TrimPrefix(bolt11, "lightning:")
+
+	hrp, data, err := bech32.DecodeNoLimit(bolt11)
+	if err != nil {
+		return nil, fmt.Errorf("invalid invoice encoding: %w", err)
+	}
+
+	if !strings.HasPrefix(hrp, "ln") {
+		return nil, fmt.Errorf("invalid invoice prefix: %s", hrp)
+	}
+
+	invoice := &Invoice{Expiry: defaultInvoiceExpiry}
+	invoice.Network, invoice.AmountMsats, err = parseInvoiceHRP(hrp[2:])
+	if err != nil {
+		return nil, err
+	}
+
+	// 35-bit timestamp, tagged fields, then a 520-bit (104 group) signature
+	if len(data) < 7+104 {
+		return nil, fmt.Errorf("invoice too short")
+	}
+	invoice.CreatedAt = time.Unix(int64(groupsToInt(data[:7])), 0)
+	fields := data[7 : len(data)-104]
+
+	for len(fields) > 0 {
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("truncated invoice field")
+		}
+		tag := fields[0]
+		length := int(fields[1])<<5 | int(fields[2])
+		if len(fields) < 3+length {
+			return nil, fmt.Errorf("invoice field %d overruns data", tag)
+		}
+		value := fields[3 : 3+length]
+		fields = fields[3+length:]
+
+		switch tag {
+		case tagPaymentHash:
+			// Readers must skip hashes of the wrong length
+			if length == 52 {
+				invoice.PaymentHash, err = groupsToHex(value)
+			}
+		case tagDescriptionHash:
+			if length == 52 {
+				invoice.DescriptionHash, err = groupsToHex(value)
+			}
+		case tagPayee:
+			if length == 53 {
+				invoice.Payee, err = groupsToHex(value)
+			}
+		case tagDescription:
+			var description []byte
+			description, err = bech32.ConvertBits(value, 5, 8, false)
+			invoice.Description = string(description)
+		case tagExpiry:
+			invoice.Expiry = time.Duration(groupsToInt(value)) * time.Second
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid invoice field %d: %w", tag, err)
+		}
+	}
+
+	if invoice.PaymentHash == "" {
+		return nil, fmt.Errorf("invoice has no payment hash")
+	}
+
+	return invoice, nil
+}
+
+// VerifyZapInvoice checks an invoice returned by an LNURL callback against the
+// zap request it was issued for (NIP-57 appendix B): the amount must equal the
+// requested msats and the description hash must be the sha256 of the zap
+// request JSON exactly as it was sent to the callback.
+func VerifyZapInvoice(bolt11 string, amountMsats int64, zapRequestJSON string) (*Invoice, error) {
+	invoice, err := DecodeInvoice(bolt11)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode invoice: %w", err)
+	}
+
+	if invoice.AmountMsats != amountMsats {
+		return nil, fmt.Errorf("invoice amount %d msats does not match requested %d msats", invoice.AmountMsats, amountMsats)
+	}
+
+	hash := sha256.Sum256([]byte(zapRequestJSON))
+	expected := hex.EncodeToString(hash[:])
+	if invoice.DescriptionHash == "" {
+		return nil, fmt.Errorf("invoice has no description hash")
+	}
+	if invoice.DescriptionHash != expected {
+		return nil, fmt.Errorf("invoice description hash does not match zap request")
+	}
+
+	if invoice.Expired() {
+		return nil, fmt.Errorf("invoice already expired")
+	}
+
+	return invoice, nil
+}
+
+// parseInvoiceHRP splits the part after "ln" into the currency prefix and amount
+func parseInvoiceHRP(hrp string) (network string, amountMsats int64, err error) {
+	i := strings.IndexAny(hrp, "0123456789")
+	if i < 0 {
+		// Amountless invoice
+		return hrp, 0, nil
+	}
+	network, amount := hrp[:i], hrp[i:]
+
+	multiplier := byte(0)
+	if last := amount[len(amount)-1]; last < '0' || last > '9' {
+		multiplier = last
+		amount = amount[:len(amount)-1]
+	}
+
+	value, err := strconv.ParseInt(amount, 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid invoice amount: %s", amount)
+	}
+
+	// Convert to msats: 1 BTC = 100,000,000,000 msats
+	var factor int64
+	switch multiplier {
+	case 0:
+		factor = 100_000_000_000
+	case 'm':
+		factor = 100_000_000
+	case 'u':
+		factor = 100_000
+	case 'n':
+		factor = 100
+	case 'p':
+		if value%10 != 0 {
+			return "", 0, fmt.Errorf("invoice amount %dp is not a whole msat", value)
+		}
+		return network, value / 10, nil
+	default:
+		return "", 0, fmt.Errorf("invalid invoice amount multiplier: %c", multiplier)
+	}
+	if value > math.MaxInt64/factor {
+		return "", 0, fmt.Errorf("invoice amount too large: %s", hrp[i:])
+	}
+	amountMsats = value * factor
+
+	return network, amountMsats, nil
+}
+
+// groupsToInt reads big-endian 5-bit groups as an integer
+func groupsToInt(groups []byte) uint64 {
+	var n uint64
+	for _, g := range groups {
+		n = n<<5 | uint64(g)
+	}
+	return n
+}
+
+// groupsToHex converts 5-bit groups to bytes, dropping the padding bits
+func groupsToHex(groups []byte) (string, error) {
+	b, err := bech32.ConvertBits(groups, 5, 8, false)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,140 @@
+package zap
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test vectors from BOLT-11. All share the payment hash and timestamp below.
+const (
+	vectorPaymentHash = "0001020304050607080900010203040506070809000102030405060708090102"
+
+	// "Please make a donation of any amount"
+	vectorDonation = "lnbc1pvjluezsp5zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zygspp5qqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqypqdpl2pkx2ctnv5sxxmmwwd5kgetjypeh2ursdae8g6twvus8g6rfwvs8qun0dfjkxaq9qrsgq357wnc5r2ueh7ck6q93dj32dlqnls087fxdwk8qakdyafkq3yap9us6v52vjjsrvywa6rt52cm9r9zqt8r2t7mlcwspyetp5h2tztugp9lfyql"
+	// "Please send $3 for a cup of coffee to the same peer, within one minute"
+	vectorCoffee = "lnbc2500u1pvjluezsp5zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zygspp5qqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqypqdq5xysxxatsyp3k7enxv4jsxqzpu9qrsgquk0rl77nj30yxdy8j9vdx85fkpmdla2087ne0xh8nhedh8w27kyke0lp53ut353s06fv3qfegext0eh0ymjpf39tuven09sam30g4vgpfna3rh"
+	// "Now send $24 for an entire list of things (hashed)"
+	vectorHashed = "lnbc20m1pvjluezsp5zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zygspp5qqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqypqhp58yjmdan79s6qqdhdzgynm4zwqd5d7xmw5fk98klysy043l2ahrqs9qrsgq7ea976txfraylvgzuxs8kgcw23ezlrszfnh8r6qtfpr6cxga50aj6txm9rxrydzd06dfeawfk6swupvz4erwnyutnjq7x39ymw6j38gp7ynn44"
+	// "The same, on testnet, with a fallback address"
+	vectorTestnet = "lntb20m1pvjluezsp5zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zygshp58yjmdan79s6qqdhdzgynm4zwqd5d7xmw5fk98klysy043l2ahrqspp5qqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqypqfpp3x9et2e20v6pu37c5d9vax37wxq72un989qrsgqdj545axuxtnfemtpwkc45hx9d2ft7x04mt8q7y6t0k2dge9e7h8kpy9p34ytyslj3yu569aalz2xdk8xkd7ltxqld94u8h2esmsmacgpghe9k8"
+
+	// The list of things vectorHashed and vectorTestnet commit to
+	vectorHashedDescription = "One piece of chocolate cake, one icecream cone, one pickle, one slice of swiss cheese, one slice of salami, one lollypop, one piece of cherry pie, one sausage, one cupcake, and one slice of watermelon"
+)
+
+func TestDecodeInvoiceVectors(t *testing.T) {
+	tests := []struct {
+		name            string
+		invoice         string
+		network         string
+		amountMsats     int64
+		description     string
+		descriptionHash string
+		expiry          time.Duration
+	}{
+		{"donation", vectorDonation, "bc", 0, "Please consider supporting this project", "", time.Hour},
+		{"coffee", vectorCoffee, "bc", 250_000_000, "1 cup coffee", "", time.Minute},
+		{"hashed", vectorHashed, "bc", 2_000_000_000, "", "3925b6f67e2c340036ed12093dd44e0368df1b6ea26c53dbe4811f58fd5db8c1", time.Hour},
+		{"testnet", vectorTestnet, "tb", 2_000_000_000, "", "3925b6f67e2c340036ed12093dd44e0368df1b6ea26c53dbe4811f58fd5db8c1", time.Hour},
+		{"uppercase with prefix", "lightning:" + strings.ToUpper(vectorCoffee), "bc", 250_000_000, "1 cup coffee", "", time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoice, err := DecodeInvoice(tt.invoice)
+			if err != nil {
+				t.Fatalf("DecodeInvoice: %v", err)
+			}
+			if invoice.Network != tt.network {
+				t.Errorf("network = %q, want %q", invoice.Network, tt.network)
+			}
+			if invoice.AmountMsats != tt.amountMsats {
+				t.Errorf("amount = %d, want %d", invoice.AmountMsats, tt.amountMsats)
+			}
+			if invoice.PaymentHash != vectorPaymentHash {
+				t.Errorf("payment hash = %s", invoice.PaymentHash)
+			}
+			if invoice.Description != tt.description {
+				t.Errorf("description = %q, want %q", invoice.Description, tt.description)
+			}
+			if invoice.DescriptionHash != tt.descriptionHash {
+				t.Errorf("description hash = %q, want %q", invoice.DescriptionHash, tt.descriptionHash)
+			}
+			if invoice.CreatedAt.Unix() != 1496314658 {
+				t.Errorf("created at = %v", invoice.CreatedAt)
+			}
+			if invoice.Expiry != tt.expiry {
+				t.Errorf("expiry = %v, want %v", invoice.Expiry, tt.expiry)
+			}
+		})
+	}
+}
+
+func TestDecodeInvoiceRejects(t *testing.T) {
+	// Flip the last checksum character
+	badChecksum := vectorCoffee[:len(vectorCoffee)-1] + "q"
+	for name, invoice := range map[string]string{
+		"invalid checksum": badChecksum,
+		"unknown prefix":   "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+		"empty":            "",
+	} {
+		if _, err := DecodeInvoice(invoice); err == nil {
+			t.Errorf("%s: decoded %q", name, invoice)
+		}
+	}
+}
+
+func TestParseInvoiceHRP(t *testing.T) {
+	tests := []struct {
+		hrp         string
+		network     string
+		amountMsats int64
+		wantErr     bool
+	}{
+		{"bc", "bc", 0, false},
+		{"bc1", "bc", 100_000_000_000, false},
+		{"bc20m", "bc", 2_000_000_000, false},
+		{"bc2500u", "bc", 250_000_000, false},
+		{"tb10n", "tb", 1000, false},
+		{"bcrt10p", "bcrt", 1, false},
+		{"bc9678785340p", "bc", 967878534, false},
+		{"bc1p", "", 0, true},  // Not a whole msat
+		{"bc10x", "", 0, true}, // Unknown multiplier
+		{"bc92233721", "", 0, true},
+		{"bc92233720368548m", "", 0, true},
+		{"bc99999999999999999999n", "", 0, true},
+	}
+	for _, tt := range tests {
+		network, amountMsats, err := parseInvoiceHRP(tt.hrp)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, want error %v", tt.hrp, err, tt.wantErr)
+			continue
+		}
+		if network != tt.network || amountMsats != tt.amountMsats {
+			t.Errorf("%s: got %q, %d, want %q, %d", tt.hrp, network, amountMsats, tt.network, tt.amountMsats)
+		}
+	}
+}
+
+func TestVerifyZapInvoice(t *testing.T) {
+	zapRequest := `{"kind":9734,"content":"great post"}`
+	invoice := testInvoice(t, 21000, zapRequest)
+
+	if _, err := VerifyZapInvoice(invoice, 21000, zapRequest); err != nil {
+		t.Errorf("VerifyZapInvoice: %v", err)
+	}
+	if _, err := VerifyZapInvoice(invoice, 42000, zapRequest); err == nil || !strings.Contains(err.Error(), "amount") {
+		t.Errorf("amount mismatch: err = %v", err)
+	}
+	if _, err := VerifyZapInvoice(invoice, 21000, `{"kind":9734,"content":"other post"}`); err == nil || !strings.Contains(err.Error(), "description hash") {
+		t.Errorf("description hash mismatch: err = %v", err)
+	}
+	// Commits to a description rather than its hash
+	if _, err := VerifyZapInvoice(vectorCoffee, 250_000_000, "1 cup coffee"); err == nil {
+		t.Error("accepted an invoice without a description hash")
+	}
+	// Amount and hash match, but it expired in 2017
+	if _, err := VerifyZapInvoice(vectorHashed, 2_000_000_000, vectorHashedDescription); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("expired invoice: err = %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package zap
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+)
+
+// DecodeLNURL decodes a LUD-01 bech32 LNURL ("lnurl1...") into its URL
+func DecodeLNURL(lnurl string) (string, error) {
+	lnurl = strings.ToLower(strings.TrimSpace(lnurl))
+	lnurl = strings.TrimPrefix(lnurl, "lightning:")
+
+	// LNURLs are routinely longer than the 90 characters bech32 allows
+	hrp, data, err := bech32.DecodeNoLimit(lnurl)
+	if err != nil {
+		return "", fmt.Errorf("invalid LNURL encoding: %w", err)
+	}
+	if hrp != "lnurl" {
+		return "", fmt.Errorf("invalid LNURL prefix: %s", hrp)
+	}
+
+	decoded, err := bech32.ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return "", fmt.Errorf("invalid LNURL data: %w", err)
+	}
+
+	rawURL := string(decoded)
+	if err := checkLNURLScheme(rawURL); err != nil {
+		return "", err
+	}
+
+	return rawURL, nil
+}
+
+// EncodeLNURL encodes a URL as a LUD-01 bech32 LNURL
+func EncodeLNURL(rawURL string) (string, error) {
+	data, err := bech32.ConvertBits([]byte(rawURL), 8, 5, true)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert URL: %w", err)
+	}
+
+	lnurl, err := bech32.Encode("lnurl", data)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode LNURL: %w", err)
+	}
+
+	return lnurl, nil
+}
+
+// ResolvePayEndpoint turns a profile's pay target into an LNURL-pay endpoint URL.
+// It accepts a LUD-16 lightning address (user@domain), a LUD-06 bech32 LNURL
+// and a LUD-17 lnurlp:// URL, with or without a lightning: prefix.
+func ResolvePayEndpoint(target string) (string, error) {
+	target = strings.TrimSpace(target)
+	if strings.HasPrefix(strings.ToLower(target), "lightning:") {
+		target = target[len("lightning:"):]
+	}
+	lower := strings.ToLower(target)
+
+	switch {
+	case strings.HasPrefix(lower, "lnurl1"):
+		return DecodeLNURL(target)
+	case strings.HasPrefix(lower, "lnurlp://"):
+		u, err := url.Parse(target)
+		if err != nil {
+			return "", fmt.Errorf("invalid lnurlp URL: %w", err)
+		}
+		// LUD-17: clearnet uses https, onion services use http
+		u.Scheme = "https"
+		if strings.HasSuffix(u.Hostname(), ".onion") {
+			u.Scheme = "http"
+		}
+		return u.String(), nil
+	case strings.Count(target, "@") == 1:
+		return GetLNURL(target)
+	default:
+		return "", fmt.Errorf("not a lightning address or LNURL: %s", target)
+	}
+}
+
+// checkLNURLScheme enforces LUD-01: https everywhere, http only for onion services
+func checkLNURLScheme(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid LNURL URL: %w", err)
+	}
+
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if strings.HasSuffix(u.Hostname(), ".onion") {
+			return nil
+		}
+		return fmt.Errorf("LNURL must use https unless it is an onion service")
+	default:
+		return fmt.Errorf("unsupported LNURL scheme: %s", u.Scheme)
+	}
+}
@@ -0,0 +1,168 @@
+package zap
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// LUD-01 example LNURL
+const (
+	lud01LNURL = "LNURL1DP68GURN8GHJ7UM9WFMXJCM99E3K7MF0V9CXJ0M385EKVCENXC6R2C35XVUKXEFCV5MKVV34X5EKZD3EV56NYD3HXQURZEPEXEJXXEPNXSCRVWFNV9NXZCN9XQ6XYEFHVGCXXCMYXYMNSERXFQ5FNS"
+	lud01URL   = "https://service.com/api?q=3fc3645b439ce8e7f2553a69e5267081d96dcd340693afabe04be7b0ccd178df"
+)
+
+func TestDecodeLNURL(t *testing.T) {
+	for _, input := range []string{lud01LNURL, strings.ToLower(lud01LNURL), "lightning:" + lud01LNURL} {
+		got, err := DecodeLNURL(input)
+		if err != nil {
+			t.Fatalf("DecodeLNURL(%q): %v", input, err)
+		}
+		if got != lud01URL {
+			t.Errorf("DecodeLNURL(%q) = %q, want %q", input, got, lud01URL)
+		}
+	}
+}
+
+func TestDecodeLNURLRejects(t *testing.T) {
+	clearnetHTTP, err := EncodeLNURL("http://service.com/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notLNURL, err := EncodeLNURL("https://service.com/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notLNURL = "lnbc" + notLNURL[len("lnurl"):]
+
+	for name, input := range map[string]string{
+		"clearnet http": clearnetHTTP,
+		"wrong prefix":  notLNURL,
+		"bad checksum":  lud01LNURL[:len(lud01LNURL)-1] + "Q",
+		"garbage":       "lnurl1notbech32!",
+	} {
+		if _, err := DecodeLNURL(input); err == nil {
+			t.Errorf("%s: expected error for %q", name, input)
+		}
+	}
+}
+
+func TestEncodeLNURLRoundTrip(t *testing.T) {
+	onion := "http://abcdefghijklmnop.onion/lnurlp/alice"
+	encoded, err := EncodeLNURL(onion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeLNURL(encoded)
+	if err != nil {
+		t.Fatalf("DecodeLNURL: %v", err)
+	}
+	if decoded != onion {
+		t.Errorf("round trip = %q, want %q", decoded, onion)
+	}
+}
+
+func TestResolvePayEndpoint(t *testing.T) {
+	lnurl, err := EncodeLNURL("https://example.com/lnurlp/bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"alice@example.com", "https://example.com/.well-known/lnurlp/alice"},
+		{"lightning:alice@example.com", "https://example.com/.well-known/lnurlp/alice"},
+		{lnurl, "https://example.com/lnurlp/bob"},
+		{"LIGHTNING:" + strings.ToUpper(lnurl), "https://example.com/lnurlp/bob"},
+		{"lnurlp://example.com/lnurlp/bob", "https://example.com/lnurlp/bob"},
+		{"lnurlp://abcdefghijklmnop.onion/pay", "http://abcdefghijklmnop.onion/pay"},
+	}
+	for _, tt := range tests {
+		got, err := ResolvePayEndpoint(tt.input)
+		if err != nil {
+			t.Errorf("ResolvePayEndpoint(%q): %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ResolvePayEndpoint(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "alice", "@example.com", "alice@", "a@b@c"} {
+		if _, err := ResolvePayEndpoint(input); err == nil {
+			t.Errorf("ResolvePayEndpoint(%q): expected error", input)
+		}
+	}
+}
+
+// TestResolveAndFetch runs both profile forms (lud16 and lud06) through the
+// resolver and fetches the pay info from a local LNURL server
+func TestResolveAndFetch(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var user string
+		switch r.URL.Path {
+		case "/.well-known/lnurlp/alice":
+			user = "alice"
+		case "/lnurlp/bob":
+			user = "bob"
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(LNURLResponse{
+			Callback:    server.URL + "/callback/" + user,
+			MinSendable: 1000,
+			MaxSendable: 100000000,
+			Metadata:    `[["text/plain","` + user + `"]]`,
+			Tag:         "payRequest",
+			AllowsNostr: true,
+			NostrPubkey: strings.Repeat("ab", 32),
+		})
+	}))
+	defer server.Close()
+
+	// Trust the test server's certificate
+	client := NewClient(server.Client())
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	lud06, err := EncodeLNURL(server.URL + "/lnurlp/bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		metadata string
+		user     string
+	}{
+		{"lud16", `{"name":"alice","lud16":"alice@` + host + `"}`, "alice"},
+		{"lud06", `{"name":"bob","lud06":"` + strings.ToUpper(lud06) + `"}`, "bob"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := GetLightningAddress(tt.metadata)
+			if target == "" {
+				t.Fatal("no pay target found in metadata")
+			}
+
+			endpoint, err := ResolvePayEndpoint(target)
+			if err != nil {
+				t.Fatalf("ResolvePayEndpoint(%q): %v", target, err)
+			}
+
+			info, err := client.FetchLNURLPayInfo(context.Background(), endpoint)
+			if err != nil {
+				t.Fatalf("FetchLNURLPayInfo(%q): %v", endpoint, err)
+			}
+			if want := server.URL + "/callback/" + tt.user; info.Callback != want {
+				t.Errorf("callback = %q, want %q", info.Callback, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package zap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// NIP-57 event kinds
+const (
+	KindZapRequest = 9734
+	KindZapReceipt = 9735
+)
+
+// Receipt holds the fields of a parsed NIP-57 zap receipt (kind 9735)
+type Receipt struct {
+	ID          string       // Receipt event ID
+	Signer      string       // Pubkey that signed the receipt (the recipient's LNURL server)
+	Recipient   string       // 'p' tag: who was zapped
+	EventID     string       // 'e' tag: zapped note, empty for profile zaps
+	Address     string       // 'a' tag: zapped addressable event, if any
+	Sender      string       // Pubkey of the embedded zap request
+	Comment     string       // Zap request content
+	AmountMsats int64        // Amount paid, from the bolt11 invoice
+	Bolt11      string       // The paid invoice
+	Description string       // Zap request JSON committed to by the invoice
+	ZapRequest  *nostr.Event // Embedded zap request
+	Invoice     *Invoice     // Decoded bolt11 invoice
+}
+
+// ParseReceipt extracts the zap request, sender and amount from a zap receipt.
+// It does not validate the receipt; see Validate.
+func ParseReceipt(evt *nostr.Event) (*Receipt, error) {
+	if evt.Kind != KindZapReceipt {
+		return nil, fmt.Errorf("not a zap receipt (kind %d)", evt.Kind)
+	}
+
+	receipt := &Receipt{
+		ID:          evt.ID,
+		Signer:      evt.PubKey,
+		Recipient:   tagValue(evt.Tags, "p"),
+		EventID:     tagValue(evt.Tags, "e"),
+		Address:     tagValue(evt.Tags, "a"),
+		Bolt11:      tagValue(evt.Tags, "bolt11"),
+		Description: tagValue(evt.Tags, "description"),
+	}
+
+	if receipt.Bolt11 == "" {
+		return nil, fmt.Errorf("zap receipt has no bolt11 tag")
+	}
+	if receipt.Description == "" {
+		return nil, fmt.Errorf("zap receipt has no description tag")
+	}
+
+	invoice, err := DecodeInvoice(receipt.Bolt11)
+	if err != nil {
+		return nil, fmt.Errorf("zap receipt has an invalid invoice: %w", err)
+	}
+	receipt.Invoice = invoice
+	receipt.AmountMsats = invoice.AmountMsats
+
+	var zapRequest nostr.Event
+	if err := json.Unmarshal([]byte(receipt.Description), &zapRequest); err != nil {
+		return nil, fmt.Errorf("zap receipt has an invalid zap request: %w", err)
+	}
+	receipt.ZapRequest = &zapRequest
+	receipt.Sender = zapRequest.PubKey
+	receipt.Comment = zapRequest.Content
+
+	return receipt, nil
+}
+
+// Validate checks a receipt per NIP-57 appendix F. lnurlNostrPubkey is the
+// nostrPubkey published by the recipient's LNURL-pay endpoint; only that key
+// may sign receipts for them.
+func (r *Receipt) Validate(lnurlNostrPubkey string) error {
+	if lnurlNostrPubkey == "" {
+		return fmt.Errorf("recipient's LNURL server doesn't publish a nostrPubkey")
+	}
+	if r.Signer != lnurlNostrPubkey {
+		return fmt.Errorf("receipt not signed by the recipient's LNURL server")
+	}
+
+	hash := sha256.Sum256([]byte(r.Description))
+	if r.Invoice.DescriptionHash != hex.EncodeToString(hash[:]) {
+		return fmt.Errorf("invoice description hash does not match zap request")
+	}
+
+	if r.ZapRequest.Kind != KindZapRequest {
+		return fmt.Errorf("embedded event is not a zap request (kind %d)", r.ZapRequest.Kind)
+	}
+	if ok, err := r.ZapRequest.CheckSignature(); !ok || err != nil {
+		return fmt.Errorf("zap request has an invalid signature")
+	}
+	if tagValue(r.ZapRequest.Tags, "p") != r.Recipient {
+		return fmt.Errorf("zap request is for a different recipient")
+	}
+
+	if amount := tagValue(r.ZapRequest.Tags, "amount"); amount != "" {
+		requested, err := strconv.ParseInt(amount, 10, 64)
+		if err != nil {
+			return fmt.Errorf("zap request has an invalid amount: %s", amount)
+		}
+		if requested != r.AmountMsats {
+			return fmt.Errorf("invoice amount %d msats does not match requested %d msats", r.AmountMsats, requested)
+		}
+	}
+
+	return nil
+}
+
+// tagValue returns the value of the first tag with the given key
+func tagValue(tags nostr.Tags, key string) string {
+	for _, tag := range tags {
+		if len(tag) >= 2 && tag[0] == key {
+			return tag[1]
+		}
+	}
+	return ""
+}
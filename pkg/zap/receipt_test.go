@@ -0,0 +1,139 @@
+package zap
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// testInvoice builds an unsigned BOLT-11 invoice committing to description.
+// DecodeInvoice doesn't check signatures, so a zeroed one is enough.
+func testInvoice(t *testing.T, amountMsats int64, description string) string {
+	t.Helper()
+
+	var data []byte
+	timestamp := time.Now().Unix()
+	for i := 6; i >= 0; i-- {
+		data = append(data, byte(timestamp>>(5*i))&31)
+	}
+
+	appendField := func(tag byte, value []byte) {
+		groups, err := bech32.ConvertBits(value, 8, 5, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, tag, byte(len(groups)>>5), byte(len(groups)&31))
+		data = append(data, groups...)
+	}
+	paymentHash := sha256.Sum256([]byte("preimage"))
+	descriptionHash := sha256.Sum256([]byte(description))
+	appendField(tagPaymentHash, paymentHash[:])
+	appendField(tagDescriptionHash, descriptionHash[:])
+
+	data = append(data, make([]byte, 104)...)
+
+	invoice, err := bech32.Encode(fmt.Sprintf("lnbc%dn", amountMsats/100), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return invoice
+}
+
+// testReceipt returns a zap request for amountMsats and a receipt for it
+// signed by the LNURL server key, paid with an invoice for paidMsats
+func testReceipt(t *testing.T, senderKey, serverKey, recipient string, amountMsats, paidMsats int64) *nostr.Event {
+	t.Helper()
+
+	zapRequest, err := CreateZapRequest(recipient, strings.Repeat("e", 64), "great post", amountMsats/1000, []string{"wss://relay.example.com"}, senderKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	description, err := json.Marshal(zapRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	receipt := &nostr.Event{
+		CreatedAt: nostr.Now(),
+		Kind:      KindZapReceipt,
+		Tags: nostr.Tags{
+			nostr.Tag{"p", recipient},
+			nostr.Tag{"e", strings.Repeat("e", 64)},
+			nostr.Tag{"bolt11", testInvoice(t, paidMsats, string(description))},
+			nostr.Tag{"description", string(description)},
+		},
+	}
+	if err := receipt.Sign(serverKey); err != nil {
+		t.Fatal(err)
+	}
+	return receipt
+}
+
+func TestReceiptValidate(t *testing.T) {
+	senderKey := nostr.GeneratePrivateKey()
+	serverKey := nostr.GeneratePrivateKey()
+	serverPubkey, _ := nostr.GetPublicKey(serverKey)
+	recipient, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	senderPubkey, _ := nostr.GetPublicKey(senderKey)
+
+	evt := testReceipt(t, senderKey, serverKey, recipient, 21000, 21000)
+	receipt, err := ParseReceipt(evt)
+	if err != nil {
+		t.Fatalf("ParseReceipt: %v", err)
+	}
+	if receipt.Sender != senderPubkey {
+		t.Errorf("sender = %s, want %s", receipt.Sender, senderPubkey)
+	}
+	if receipt.AmountMsats != 21000 {
+		t.Errorf("amount = %d, want 21000", receipt.AmountMsats)
+	}
+	if receipt.Comment != "great post" {
+		t.Errorf("comment = %q, want %q", receipt.Comment, "great post")
+	}
+	if err := receipt.Validate(serverPubkey); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+
+	// Signed by someone other than the recipient's LNURL server
+	otherPubkey, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	if err := receipt.Validate(otherPubkey); err == nil {
+		t.Error("expected error for receipt signed by the wrong key")
+	}
+	if err := receipt.Validate(""); err == nil {
+		t.Error("expected error when the LNURL server has no nostrPubkey")
+	}
+
+	// Invoice paid less than the zap request asked for
+	underpaid, err := ParseReceipt(testReceipt(t, senderKey, serverKey, recipient, 21000, 1000))
+	if err != nil {
+		t.Fatalf("ParseReceipt: %v", err)
+	}
+	if err := underpaid.Validate(serverPubkey); err == nil {
+		t.Error("expected error for amount mismatch")
+	}
+
+	// Invoice that commits to a different description
+	tampered, err := ParseReceipt(evt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered.Description = strings.Replace(tampered.Description, "great post", "other post", 1)
+	if err := tampered.Validate(serverPubkey); err == nil {
+		t.Error("expected error for description hash mismatch")
+	}
+}
+
+func TestParseReceiptRejects(t *testing.T) {
+	if _, err := ParseReceipt(&nostr.Event{Kind: 1}); err == nil {
+		t.Error("expected error for non-receipt kind")
+	}
+	if _, err := ParseReceipt(&nostr.Event{Kind: KindZapReceipt}); err == nil {
+		t.Error("expected error for receipt without bolt11")
+	}
+}
@@ -0,0 +1,131 @@
+package zap
+
+import (
+	"encoding/hex"
+	"sort"
+	"strconv"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Split is one recipient listed in a note's "zap" tags (NIP-57 appendix G)
+type Split struct {
+	Pubkey string
+	Relay  string // Relay hint for the recipient's profile
+	Weight float64
+}
+
+// Share is a recipient's part of a split zap
+type Share struct {
+	Pubkey     string
+	Relay      string
+	AmountSats int64
+}
+
+// ParseSplits reads the zap split recipients from an event's tags. When only
+// some tags carry a weight, the ones without are ignored; when none do, the
+// amount is split equally. Returns nil if the event has no usable zap tags.
+func ParseSplits(tags nostr.Tags) []Split {
+	var splits []Split
+	weighted := false
+	index := make(map[string]int)
+
+	for _, tag := range tags {
+		if len(tag) < 2 || tag[0] != "zap" || !isPubkey(tag[1]) {
+			continue
+		}
+
+		split := Split{Pubkey: tag[1], Weight: -1}
+		if len(tag) >= 3 {
+			split.Relay = tag[2]
+		}
+		if len(tag) >= 4 {
+			if weight, err := strconv.ParseFloat(tag[3], 64); err == nil && weight >= 0 {
+				split.Weight = weight
+				weighted = true
+			}
+		}
+
+		// A pubkey listed twice gets the sum of its weights
+		if i, ok := index[split.Pubkey]; ok {
+			if split.Weight >= 0 {
+				splits[i].Weight = max(splits[i].Weight, 0) + split.Weight
+			}
+			continue
+		}
+		index[split.Pubkey] = len(splits)
+		splits = append(splits, split)
+	}
+
+	var usable []Split
+	for _, split := range splits {
+		switch {
+		case !weighted:
+			split.Weight = 1
+		case split.Weight <= 0:
+			continue
+		}
+		usable = append(usable, split)
+	}
+	return usable
+}
+
+// SplitAmount divides amountSats between the recipients by weight. Rounding
+// leftovers go to the heaviest recipients; recipients whose share rounds down
+// to nothing are dropped.
+func SplitAmount(splits []Split, amountSats int64) []Share {
+	var totalWeight float64
+	for _, split := range splits {
+		totalWeight += split.Weight
+	}
+	if totalWeight <= 0 || amountSats <= 0 {
+		return nil
+	}
+
+	shares := make([]Share, len(splits))
+	var assigned int64
+	for i, split := range splits {
+		amount := int64(float64(amountSats) * split.Weight / totalWeight)
+		shares[i] = Share{Pubkey: split.Pubkey, Relay: split.Relay, AmountSats: amount}
+		assigned += amount
+	}
+
+	// Hand out the sats lost to rounding, heaviest recipients first
+	order := make([]int, len(splits))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return splits[order[a]].Weight > splits[order[b]].Weight
+	})
+	for i := 0; assigned < amountSats; i++ {
+		shares[order[i%len(order)]].AmountSats++
+		assigned++
+	}
+
+	var nonZero []Share
+	for _, share := range shares {
+		if share.AmountSats > 0 {
+			nonZero = append(nonZero, share)
+		}
+	}
+	return nonZero
+}
+
+// SplitTags builds the "zap" tags for publishing a note with zap splits
+func SplitTags(splits []Split) nostr.Tags {
+	tags := make(nostr.Tags, 0, len(splits))
+	for _, split := range splits {
+		tags = append(tags, nostr.Tag{"zap", split.Pubkey, split.Relay, strconv.FormatFloat(split.Weight, 'f', -1, 64)})
+	}
+	return tags
+}
+
+// isPubkey reports whether s is a 32-byte hex public key
+func isPubkey(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
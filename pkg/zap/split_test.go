@@ -0,0 +1,102 @@
+package zap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+var (
+	alice = strings.Repeat("a", 64)
+	bob   = strings.Repeat("b", 64)
+	carol = strings.Repeat("c", 64)
+)
+
+func TestParseSplits(t *testing.T) {
+	tests := []struct {
+		name string
+		tags nostr.Tags
+		want []Split
+	}{
+		{
+			name: "weighted",
+			tags: nostr.Tags{{"zap", alice, "wss://a", "1"}, {"zap", bob, "wss://b", "3"}},
+			want: []Split{{alice, "wss://a", 1}, {bob, "wss://b", 3}},
+		},
+		{
+			name: "no weights splits equally",
+			tags: nostr.Tags{{"zap", alice}, {"zap", bob, "wss://b"}},
+			want: []Split{{alice, "", 1}, {bob, "wss://b", 1}},
+		},
+		{
+			name: "partial weights ignore unweighted",
+			tags: nostr.Tags{{"zap", alice, "", "2"}, {"zap", bob}, {"zap", carol, "", "0"}},
+			want: []Split{{alice, "", 2}},
+		},
+		{
+			name: "invalid pubkeys and other tags skipped",
+			tags: nostr.Tags{{"p", alice}, {"zap", "npub1xyz", "", "1"}, {"zap", bob, "", "1"}},
+			want: []Split{{bob, "", 1}},
+		},
+		{
+			name: "duplicate pubkeys merged",
+			tags: nostr.Tags{{"zap", alice, "", "1"}, {"zap", alice, "", "2"}},
+			want: []Split{{alice, "", 3}},
+		},
+		{
+			name: "no zap tags",
+			tags: nostr.Tags{{"e", alice}},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		got := ParseSplits(tt.tags)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: split %d = %v, want %v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestSplitAmount(t *testing.T) {
+	splits := []Split{{alice, "", 1}, {bob, "", 2}, {carol, "", 1}}
+
+	shares := SplitAmount(splits, 101)
+	var total int64
+	got := make(map[string]int64)
+	for _, share := range shares {
+		total += share.AmountSats
+		got[share.Pubkey] = share.AmountSats
+	}
+	if total != 101 {
+		t.Errorf("shares add up to %d, want 101", total)
+	}
+	// 25.25 / 50.5 / 25.25: bob is heaviest and gets the leftover sat
+	if got[alice] != 25 || got[bob] != 51 || got[carol] != 25 {
+		t.Errorf("unexpected shares: %v", got)
+	}
+
+	// Shares that round to zero are dropped
+	shares = SplitAmount([]Split{{alice, "", 99}, {bob, "", 1}}, 10)
+	if len(shares) != 1 || shares[0].Pubkey != alice || shares[0].AmountSats != 10 {
+		t.Errorf("expected all 10 sats to alice, got %v", shares)
+	}
+
+	if shares := SplitAmount(nil, 21); shares != nil {
+		t.Errorf("expected no shares without splits, got %v", shares)
+	}
+}
+
+func TestSplitTagsRoundTrip(t *testing.T) {
+	splits := []Split{{alice, "wss://relay.example.com", 80}, {bob, "", 20.5}}
+	got := ParseSplits(SplitTags(splits))
+	if len(got) != 2 || got[0] != splits[0] || got[1] != splits[1] {
+		t.Errorf("round trip = %v, want %v", got, splits)
+	}
+}
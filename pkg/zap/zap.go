@@ -9,9 +9,9 @@ import (
 "net/url"
 "strings"
 "time"
+"unicode/utf8"
 
 "github.com/nbd-wtf/go-nostr"
-"github.com/nbd-wtf/go-nostr/nip19"
 )
 
 // LNURLResponse is the response from a LNURL-pay endpoint
@@ -25,16 +25,21 @@ Tag          string `json:"tag"`
 AllowsNostr  bool   `json:"allowsNostr"`
 NostrPubkey  string `json:"nostrPubkey"`
 CommentAllowed int  `json:"commentAllowed,omitempty"`
+Reason       string `json:"reason,omitempty"` // Set when Status is "ERROR"
 }
 
 // LNURLCallbackResponse is the response from the callback
 type LNURLCallbackResponse struct {
+Status string `json:"status,omitempty"`
+Reason string `json:"reason,omitempty"`
 PR     string `json:"pr"`     // Payment request (invoice)
 Routes []struct{} `json:"routes"`
 }
 
-// CreateZapRequest creates a NIP-57 zap request event
-func CreateZapRequest(recipientPubkey, eventID, content string, amountSats int64, relays []string, privkey string) (*nostr.Event, error) {
+// NewZapRequest builds an unsigned NIP-57 zap request event from senderPubkey.
+// eventID is the zapped note's ID, an addressable event's coordinate
+// (kind:pubkey:d) which is sent as an "a" tag, or empty for a profile zap.
+func NewZapRequest(senderPubkey, recipientPubkey, eventID, content string, amountSats int64, relays []string) *nostr.Event {
 amountMsats := amountSats * 1000
 
 tags := nostr.Tags{
@@ -42,8 +47,10 @@ nostr.Tag{"p", recipientPubkey},
 nostr.Tag{"amount", fmt.Sprintf("%d", amountMsats)},
 }
 
-// Add event ID if this is a zap on a note
-if eventID != "" {
+// Add event ID if this is a zap on a note, or its address if it's addressable
+if IsAddress(eventID) {
+tags = append(tags, nostr.Tag{"a", eventID})
+} else if eventID != "" {
 tags = append(tags, nostr.Tag{"e", eventID})
 }
 
@@ -52,19 +59,43 @@ for _, relay := range relays {
 tags = append(tags, nostr.Tag{"relays", relay})
 }
 
+return &nostr.Event{
+PubKey:    senderPubkey,
+CreatedAt: nostr.Now(),
+Kind:      KindZapRequest,
+Tags:      tags,
+Content:   content,
+}
+}
+
+// CreateZapRequest creates a NIP-57 zap request event
+func CreateZapRequest(recipientPubkey, eventID, content string, amountSats int64, relays []string, privkey string) (*nostr.Event, error) {
 pubkey, err := nostr.GetPublicKey(privkey)
 if err != nil {
 return nil, fmt.Errorf("failed to get public key: %w", err)
 }
 
-evt := &nostr.Event{
-PubKey:    pubkey,
-CreatedAt: nostr.Now(),
-Kind:      9734, // Zap request kind
-Tags:      tags,
-Content:   content,
+evt := NewZapRequest(pubkey, recipientPubkey, eventID, content, amountSats, relays)
+
+if err := evt.Sign(privkey); err != nil {
+return nil, fmt.Errorf("failed to sign zap request: %w", err)
 }
 
+return evt, nil
+}
+
+// CreateAnonZapRequest creates an anonymous zap request signed with a
+// throwaway key and marked with an empty "anon" tag (NIP-57)
+func CreateAnonZapRequest(recipientPubkey, eventID, content string, amountSats int64, relays []string) (*nostr.Event, error) {
+privkey := nostr.GeneratePrivateKey()
+pubkey, err := nostr.GetPublicKey(privkey)
+if err != nil {
+return nil, fmt.Errorf("failed to get public key: %w", err)
+}
+
+evt := NewZapRequest(pubkey, recipientPubkey, eventID, content, amountSats, relays)
+evt.Tags = append(evt.Tags, nostr.Tag{"anon"})
+
 if err := evt.Sign(privkey); err != nil {
 return nil, fmt.Errorf("failed to sign zap request: %w", err)
 }
@@ -72,6 +103,31 @@ return nil, fmt.Errorf("failed to sign zap request: %w", err)
 return evt, nil
 }
 
+// IsAddress reports whether s is an addressable event coordinate (kind:pubkey:d)
+func IsAddress(s string) bool {
+parts := strings.SplitN(s, ":", 3)
+return len(parts) == 3 && len(parts[1]) == 64
+}
+
+// CheckZapParams validates an amount and comment against the limits the
+// recipient's LNURL-pay endpoint advertises, before an invoice is requested
+func CheckZapParams(info *LNURLResponse, amountSats int64, comment string) error {
+amountMsats := amountSats * 1000
+if info.MinSendable > 0 && amountMsats < info.MinSendable {
+return fmt.Errorf("minimum zap is %d sats", (info.MinSendable+999)/1000)
+}
+if info.MaxSendable > 0 && amountMsats > info.MaxSendable {
+return fmt.Errorf("maximum zap is %d sats", info.MaxSendable/1000)
+}
+
+// LUD-12: comments longer than commentAllowed are rejected by the server
+if info.CommentAllowed > 0 && utf8.RuneCountInString(comment) > info.CommentAllowed {
+return fmt.Errorf("comment is too long (max %d characters)", info.CommentAllowed)
+}
+
+return nil
+}
+
 // GetLNURL fetches the lightning address metadata
 func GetLNURL(lightningAddress string) (string, error) {
 // Parse lightning address (user@domain.com)
@@ -82,36 +138,66 @@ return "", fmt.Errorf("invalid lightning address format")
 
 user := parts[0]
 domain := parts[1]
+if user == "" || domain == "" {
+return "", fmt.Errorf("invalid lightning address format")
+}
+
+// LUD-16: clearnet domains use https, onion services use http
+scheme := "https"
+if strings.HasSuffix(strings.ToLower(domain), ".onion") {
+scheme = "http"
+}
 
 // Construct LNURL endpoint
-lnurlEndpoint := fmt.Sprintf("https://%s/.well-known/lnurlp/%s", domain, user)
+lnurlEndpoint := fmt.Sprintf("%s://%s/.well-known/lnurlp/%s", scheme, domain, user)
 
 return lnurlEndpoint, nil
 }
 
-// FetchLNURLPayInfo fetches LNURL-pay information
-func FetchLNURLPayInfo(lnurlEndpoint string) (*LNURLResponse, error) {
-ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-defer cancel()
+// DefaultMaxResponseSize caps LNURL responses; metadata can embed an image
+const DefaultMaxResponseSize = 1 << 20
 
-req, err := http.NewRequestWithContext(ctx, "GET", lnurlEndpoint, nil)
-if err != nil {
-return nil, fmt.Errorf("failed to create request: %w", err)
+// Client talks to LNURL-pay endpoints and callbacks
+type Client struct {
+HTTP            *http.Client // HTTP client used for all requests
+MaxResponseSize int64        // Largest response body accepted, in bytes
 }
 
-resp, err := http.DefaultClient.Do(req)
+// NewClient creates an LNURL client. A nil httpClient gets a client with a
+// 10 second timeout; callers can also bound requests through their context.
+func NewClient(httpClient *http.Client) *Client {
+if httpClient == nil {
+httpClient = &http.Client{Timeout: 10 * time.Second}
+}
+return &Client{
+HTTP:            httpClient,
+MaxResponseSize: DefaultMaxResponseSize,
+}
+}
+
+// FetchLNURLPayInfo fetches LNURL-pay information for a zap
+func (c *Client) FetchLNURLPayInfo(ctx context.Context, lnurlEndpoint string) (*LNURLResponse, error) {
+lnurlResp, err := c.FetchLNURLPay(ctx, lnurlEndpoint)
 if err != nil {
-return nil, fmt.Errorf("failed to fetch LNURL info: %w", err)
+return nil, err
 }
-defer resp.Body.Close()
 
-if resp.StatusCode != 200 {
-return nil, fmt.Errorf("LNURL endpoint returned status %d", resp.StatusCode)
+if !lnurlResp.AllowsNostr {
+return nil, fmt.Errorf("recipient doesn't support Nostr zaps")
+}
+
+return lnurlResp, nil
+}
+
+// FetchLNURLPay fetches LNURL-pay information for a plain (non-zap) payment
+func (c *Client) FetchLNURLPay(ctx context.Context, lnurlEndpoint string) (*LNURLResponse, error) {
+if err := checkLNURLScheme(lnurlEndpoint); err != nil {
+return nil, err
 }
 
-body, err := io.ReadAll(resp.Body)
+body, err := c.get(ctx, lnurlEndpoint)
 if err != nil {
-return nil, fmt.Errorf("failed to read response: %w", err)
+return nil, fmt.Errorf("failed to fetch LNURL info: %w", err)
 }
 
 var lnurlResp LNURLResponse
@@ -119,15 +205,20 @@ if err := json.Unmarshal(body, &lnurlResp); err != nil {
 return nil, fmt.Errorf("failed to parse LNURL response: %w", err)
 }
 
-if !lnurlResp.AllowsNostr {
-return nil, fmt.Errorf("recipient doesn't support Nostr zaps")
+if lnurlResp.Status == "ERROR" {
+return nil, fmt.Errorf("LNURL endpoint returned an error: %s", lnurlResp.Reason)
+}
+
+if lnurlResp.Callback == "" {
+return nil, fmt.Errorf("LNURL response has no callback")
 }
 
 return &lnurlResp, nil
 }
 
-// GetInvoice gets a lightning invoice for the zap
-func GetInvoice(callbackURL string, amountSats int64, zapRequest *nostr.Event) (string, error) {
+// GetInvoice gets a lightning invoice for the zap, verifying that it is for
+// the requested amount and commits to the zap request
+func (c *Client) GetInvoice(ctx context.Context, callbackURL string, amountSats int64, zapRequest *nostr.Event) (string, error) {
 amountMsats := amountSats * 1000
 
 // Encode zap request as JSON
@@ -136,39 +227,55 @@ if err != nil {
 return "", fmt.Errorf("failed to marshal zap request: %w", err)
 }
 
-// Build callback URL with parameters
-u, err := url.Parse(callbackURL)
+params := url.Values{}
+params.Set("amount", fmt.Sprintf("%d", amountMsats))
+params.Set("nostr", string(zapRequestJSON))
+
+invoice, err := c.requestInvoice(ctx, callbackURL, params)
 if err != nil {
-return "", fmt.Errorf("invalid callback URL: %w", err)
+return "", err
 }
 
-q := u.Query()
-q.Set("amount", fmt.Sprintf("%d", amountMsats))
-q.Set("nostr", string(zapRequestJSON))
-u.RawQuery = q.Encode()
+// Never hand the wallet an invoice that doesn't commit to our zap request
+if _, err := VerifyZapInvoice(invoice, amountMsats, string(zapRequestJSON)); err != nil {
+return "", fmt.Errorf("rejected invoice from LNURL callback: %w", err)
+}
 
-// Make request
-ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-defer cancel()
+return invoice, nil
+}
 
-req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
-if err != nil {
-return "", fmt.Errorf("failed to create callback request: %w", err)
+// GetPayInvoice gets a lightning invoice for a plain LNURL-pay payment, without a zap request
+func (c *Client) GetPayInvoice(ctx context.Context, callbackURL string, amountSats int64, comment string) (string, error) {
+params := url.Values{}
+params.Set("amount", fmt.Sprintf("%d", amountSats*1000))
+if comment != "" {
+params.Set("comment", comment)
+}
+
+return c.requestInvoice(ctx, callbackURL, params)
+}
+
+// requestInvoice calls an LNURL-pay callback and returns the payment request
+func (c *Client) requestInvoice(ctx context.Context, callbackURL string, params url.Values) (string, error) {
+if err := checkLNURLScheme(callbackURL); err != nil {
+return "", fmt.Errorf("invalid callback URL: %w", err)
 }
 
-resp, err := http.DefaultClient.Do(req)
+// Build callback URL with parameters
+u, err := url.Parse(callbackURL)
 if err != nil {
-return "", fmt.Errorf("failed to fetch invoice: %w", err)
+return "", fmt.Errorf("invalid callback URL: %w", err)
 }
-defer resp.Body.Close()
 
-if resp.StatusCode != 200 {
-return "", fmt.Errorf("callback returned status %d", resp.StatusCode)
+q := u.Query()
+for key, values := range params {
+q[key] = values
 }
+u.RawQuery = q.Encode()
 
-body, err := io.ReadAll(resp.Body)
+body, err := c.get(ctx, u.String())
 if err != nil {
-return "", fmt.Errorf("failed to read callback response: %w", err)
+return "", fmt.Errorf("failed to fetch invoice: %w", err)
 }
 
 var callbackResp LNURLCallbackResponse
@@ -176,6 +283,10 @@ if err := json.Unmarshal(body, &callbackResp); err != nil {
 return "", fmt.Errorf("failed to parse callback response: %w", err)
 }
 
+if callbackResp.Status == "ERROR" {
+return "", fmt.Errorf("callback returned an error: %s", callbackResp.Reason)
+}
+
 if callbackResp.PR == "" {
 return "", fmt.Errorf("no payment request in callback response")
 }
@@ -183,7 +294,36 @@ return "", fmt.Errorf("no payment request in callback response")
 return callbackResp.PR, nil
 }
 
-// GetLightningAddress extracts lightning address from profile metadata
+// get fetches a URL and returns its body, refusing oversized responses
+func (c *Client) get(ctx context.Context, rawURL string) ([]byte, error) {
+req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+if err != nil {
+return nil, fmt.Errorf("failed to create request: %w", err)
+}
+
+resp, err := c.HTTP.Do(req)
+if err != nil {
+return nil, err
+}
+defer resp.Body.Close()
+
+if resp.StatusCode != 200 {
+return nil, fmt.Errorf("status %d", resp.StatusCode)
+}
+
+body, err := io.ReadAll(io.LimitReader(resp.Body, c.MaxResponseSize+1))
+if err != nil {
+return nil, fmt.Errorf("failed to read response: %w", err)
+}
+if int64(len(body)) > c.MaxResponseSize {
+return nil, fmt.Errorf("response larger than %d bytes", c.MaxResponseSize)
+}
+
+return body, nil
+}
+
+// GetLightningAddress extracts the pay target from profile metadata: the lud16
+// lightning address, or the lud06 bech32 LNURL. Pass it to ResolvePayEndpoint.
 func GetLightningAddress(metadata string) string {
 var meta map[string]interface{}
 if err := json.Unmarshal([]byte(metadata), &meta); err != nil {
@@ -191,19 +331,11 @@ return ""
 }
 
 if lud16, ok := meta["lud16"].(string); ok && lud16 != "" {
-return lud16
+return strings.TrimSpace(lud16)
 }
 
 if lud06, ok := meta["lud06"].(string); ok && lud06 != "" {
-// lud06 is LNURL, need to decode it
-if strings.HasPrefix(lud06, "lnurl") {
-_, decoded, err := nip19.Decode(lud06)
-if err == nil {
-if lnurl, ok := decoded.(string); ok {
-return lnurl
-}
-}
-}
+return strings.TrimSpace(lud06)
 }
 
 return ""
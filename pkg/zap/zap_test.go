@@ -0,0 +1,266 @@
+package zap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestCheckZapParams(t *testing.T) {
+	info := &LNURLResponse{MinSendable: 10000, MaxSendable: 1000000, CommentAllowed: 5}
+
+	tests := []struct {
+		name    string
+		amount  int64
+		comment string
+		wantErr bool
+	}{
+		{"within limits", 21, "hi", false},
+		{"at minimum", 10, "", false},
+		{"at maximum", 1000, "", false},
+		{"below minimum", 9, "", true},
+		{"above maximum", 1001, "", true},
+		{"comment at limit", 21, "héllo", false},
+		{"comment too long", 21, "hello!", true},
+	}
+	for _, tt := range tests {
+		err := CheckZapParams(info, tt.amount, tt.comment)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: CheckZapParams(%d, %q) error = %v, wantErr %v", tt.name, tt.amount, tt.comment, err, tt.wantErr)
+		}
+	}
+
+	// No advertised limits means anything goes
+	if err := CheckZapParams(&LNURLResponse{}, 1, strings.Repeat("x", 500)); err != nil {
+		t.Errorf("unexpected error without limits: %v", err)
+	}
+}
+
+func TestCreateAnonZapRequest(t *testing.T) {
+	recipient, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+
+	evt, err := CreateAnonZapRequest(recipient, "", "anon hello", 21, []string{"wss://relay.example.com"})
+	if err != nil {
+		t.Fatalf("CreateAnonZapRequest: %v", err)
+	}
+	if ok, err := evt.CheckSignature(); !ok || err != nil {
+		t.Errorf("invalid signature: %v", err)
+	}
+	if evt.Kind != KindZapRequest || evt.Content != "anon hello" {
+		t.Errorf("unexpected zap request: kind %d, content %q", evt.Kind, evt.Content)
+	}
+
+	hasAnon := false
+	for _, tag := range evt.Tags {
+		if len(tag) > 0 && tag[0] == "anon" {
+			hasAnon = true
+		}
+		if len(tag) >= 2 && tag[0] == "e" {
+			t.Errorf("profile zap has an e tag: %v", tag)
+		}
+	}
+	if !hasAnon {
+		t.Error("anonymous zap request has no anon tag")
+	}
+
+	// Each anonymous zap uses a fresh key
+	other, err := CreateAnonZapRequest(recipient, "", "", 21, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.PubKey == evt.PubKey {
+		t.Error("anonymous zaps reused a key")
+	}
+}
+
+func TestNewZapRequestTargets(t *testing.T) {
+	recipient := strings.Repeat("a", 64)
+	address := "30023:" + recipient + ":my-article"
+
+	tests := []struct {
+		name    string
+		eventID string
+		wantTag string
+	}{
+		{"note", strings.Repeat("e", 64), "e"},
+		{"addressable event", address, "a"},
+		{"profile", "", ""},
+	}
+	for _, tt := range tests {
+		evt := NewZapRequest(strings.Repeat("b", 64), recipient, tt.eventID, "", 21, nil)
+		var found []string
+		for _, tag := range evt.Tags {
+			if tag[0] == "e" || tag[0] == "a" {
+				found = append(found, tag[0])
+				if tag[1] != tt.eventID {
+					t.Errorf("%s: %s tag = %q, want %q", tt.name, tag[0], tag[1], tt.eventID)
+				}
+			}
+		}
+		switch {
+		case tt.wantTag == "" && len(found) != 0:
+			t.Errorf("%s: unexpected tags %v", tt.name, found)
+		case tt.wantTag != "" && (len(found) != 1 || found[0] != tt.wantTag):
+			t.Errorf("%s: got tags %v, want one %q tag", tt.name, found, tt.wantTag)
+		}
+	}
+}
+
+// lnurlServer is a lightning address host for testing the zap flow. The
+// callback answers with an invoice for the requested amount plus extraMsats.
+type lnurlServer struct {
+	*httptest.Server
+	extraMsats int64
+	callback   func(w http.ResponseWriter, r *http.Request) bool // Overrides the reply when it returns true
+}
+
+func newLNURLServer(t *testing.T) *lnurlServer {
+	t.Helper()
+
+	s := &lnurlServer{}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/lnurlp/alice":
+			json.NewEncoder(w).Encode(LNURLResponse{
+				Callback:    s.URL + "/callback",
+				MinSendable: 1000,
+				MaxSendable: 100000000,
+				Metadata:    `[["text/plain","alice"]]`,
+				Tag:         "payRequest",
+				AllowsNostr: true,
+				NostrPubkey: strings.Repeat("ab", 32),
+			})
+		case "/callback":
+			if s.callback != nil && s.callback(w, r) {
+				return
+			}
+			amount, err := strconv.ParseInt(r.URL.Query().Get("amount"), 10, 64)
+			if err != nil {
+				http.Error(w, "bad amount", http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(LNURLCallbackResponse{
+				PR: testInvoice(t, amount+s.extraMsats, r.URL.Query().Get("nostr")),
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// TestZapFlow follows a zap from lightning address to verified invoice
+func TestZapFlow(t *testing.T) {
+	server := newLNURLServer(t)
+	client := NewClient(server.Client())
+	ctx := context.Background()
+
+	endpoint, err := GetLNURL("alice@" + strings.TrimPrefix(server.URL, "https://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := client.FetchLNURLPayInfo(ctx, endpoint)
+	if err != nil {
+		t.Fatalf("FetchLNURLPayInfo: %v", err)
+	}
+
+	recipient, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	zapRequest, err := CreateZapRequest(recipient, "", "hi", 21, []string{"wss://relay.example.com"}, nostr.GeneratePrivateKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invoice, err := client.GetInvoice(ctx, info.Callback, 21, zapRequest)
+	if err != nil {
+		t.Fatalf("GetInvoice: %v", err)
+	}
+	decoded, err := DecodeInvoice(invoice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.AmountMsats != 21000 {
+		t.Errorf("invoice amount = %d msats, want 21000", decoded.AmountMsats)
+	}
+
+	// A callback that inflates the amount must be rejected
+	server.extraMsats = 1000
+	if _, err := client.GetInvoice(ctx, info.Callback, 21, zapRequest); err == nil {
+		t.Error("expected error for invoice with the wrong amount")
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	server := newLNURLServer(t)
+	client := NewClient(server.Client())
+	ctx := context.Background()
+	zapRequest, err := CreateZapRequest(strings.Repeat("cd", 32), "", "", 21, nil, nostr.GeneratePrivateKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// LNURL error status
+	server.callback = func(w http.ResponseWriter, r *http.Request) bool {
+		w.Write([]byte(`{"status":"ERROR","reason":"amount too low"}`))
+		return true
+	}
+	_, err = client.GetInvoice(ctx, server.URL+"/callback", 21, zapRequest)
+	if err == nil || !strings.Contains(err.Error(), "amount too low") {
+		t.Errorf("expected LNURL error reason, got %v", err)
+	}
+
+	// Oversized response
+	server.callback = func(w http.ResponseWriter, r *http.Request) bool {
+		w.Write([]byte(`{"pr":"` + strings.Repeat("a", 2048) + `"}`))
+		return true
+	}
+	small := NewClient(server.Client())
+	small.MaxResponseSize = 1024
+	if _, err := small.GetInvoice(ctx, server.URL+"/callback", 21, zapRequest); err == nil {
+		t.Error("expected error for oversized response")
+	}
+
+	// Canceled context
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = client.FetchLNURLPay(canceled, server.URL+"/.well-known/lnurlp/alice")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	// Plain http is only allowed for onion services
+	if _, err := client.FetchLNURLPay(ctx, "http://example.com/.well-known/lnurlp/alice"); err == nil {
+		t.Error("expected error for clearnet http endpoint")
+	}
+}
+
+func TestGetLNURL(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{"alice@example.com", "https://example.com/.well-known/lnurlp/alice"},
+		{"bob@abcdefghijklmnop.onion", "http://abcdefghijklmnop.onion/.well-known/lnurlp/bob"},
+		{"alice", ""},
+		{"@example.com", ""},
+	}
+	for _, tt := range tests {
+		got, err := GetLNURL(tt.address)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("GetLNURL(%q) = %q, expected error", tt.address, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("GetLNURL(%q) = %q, %v; want %q", tt.address, got, err, tt.want)
+		}
+	}
+}
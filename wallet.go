@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"noscli/pkg/config"
+	"noscli/pkg/nwc"
+)
+
+const walletUsage = `Usage: noscli wallet doctor [--wallet <name> | <nwc-connection-string>]
+
+Checks a Nostr Wallet Connect connection and prints a pass/fail report.
+Without arguments the default wallet from the config file is checked.`
+
+// runWalletCommand runs "noscli wallet ..." and returns the exit code
+func runWalletCommand(args []string) int {
+	if len(args) == 0 || args[0] != "doctor" {
+		fmt.Fprintln(os.Stderr, walletUsage)
+		return 2
+	}
+	args = args[1:]
+
+	var uri, name string
+	switch {
+	case len(args) == 0:
+	case len(args) == 2 && args[0] == "--wallet":
+		name = args[1]
+	case len(args) == 1 && strings.HasPrefix(args[0], "nostr+walletconnect://"):
+		uri = args[0]
+	default:
+		fmt.Fprintln(os.Stderr, walletUsage)
+		return 2
+	}
+
+	if uri == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 1
+		}
+		wallet := cfg.GetDefaultWallet()
+		if name != "" {
+			wallet = cfg.GetWallet(name)
+		}
+		if wallet == nil {
+			fmt.Fprintln(os.Stderr, "❌ No such wallet configured; add one in Settings → Wallet or pass a connection string")
+			return 1
+		}
+		fmt.Printf("Checking wallet '%s'...\n\n", wallet.Name)
+		uri = wallet.NWC
+	}
+
+	report := nwc.Diagnose(context.Background(), uri)
+	fmt.Print(report)
+	if !report.Passed() {
+		return 1
+	}
+	return 0
+}